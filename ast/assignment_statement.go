@@ -10,6 +10,19 @@ type AssignmentStatement struct {
 	Token token.Token // token.ASSIGNMENT
 	Name  *Identifier
 	Value Expression
+	// Annotation is the declared type of the variable, x: Float = 1
+	Annotation *Identifier
+	// Const variables cannot be assigned again, const x = 1
+	Const bool
+	// Chain holds the other variables assigned the same value, b in a = b = 0
+	Chain []*Identifier
+	// Doc is the text of the comments right above the statement
+	Doc string
+}
+
+// Names returns the assigned variables from left to right
+func (ae *AssignmentStatement) Names() []*Identifier {
+	return append([]*Identifier{ae.Name}, ae.Chain...)
 }
 
 func (ae *AssignmentStatement) statementNode()       {}
@@ -21,8 +34,19 @@ func (ae *AssignmentStatement) Accept(visit NodeVisitor) object.Object {
 
 func (ae *AssignmentStatement) String() string {
 	var out bytes.Buffer
+	if ae.Const {
+		out.WriteString("const ")
+	}
 	out.WriteString(ae.Name.String())
+	if ae.Annotation != nil {
+		out.WriteString(": ")
+		out.WriteString(ae.Annotation.String())
+	}
 	out.WriteString(" = ")
+	for _, name := range ae.Chain {
+		out.WriteString(name.String())
+		out.WriteString(" = ")
+	}
 	if ae.Value != nil {
 		out.WriteString(ae.Value.String())
 	}
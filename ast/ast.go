@@ -16,6 +16,14 @@ type NodeVisitor interface {
 	PrefixExpression(*PrefixExpression) object.Object
 	InfixExpression(*InfixExpression) object.Object
 	CallExpression(*CallExpression) object.Object
+	QuoteExpression(*QuoteExpression) object.Object
+	StringLiteral(*StringLiteral) object.Object
+	DurationLiteral(*DurationLiteral) object.Object
+	ImportStatement(*ImportStatement) object.Object
+	MemberExpression(*MemberExpression) object.Object
+	TypeStatement(*TypeStatement) object.Object
+	CompoundAssignmentStatement(*CompoundAssignmentStatement) object.Object
+	DestructuringStatement(*DestructuringStatement) object.Object
 }
 
 type Node interface {
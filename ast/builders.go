@@ -0,0 +1,44 @@
+package ast
+
+import (
+	"gocalc/token"
+	"strconv"
+)
+
+// The following constructors build nodes with the same tokens the parser would use,
+// for passes that synthesise new trees
+
+func NewIdentifier(name string) *Identifier {
+	return &Identifier{Token: token.NewExt(token.IDENT, name), Value: name}
+}
+
+func NewFloatLiteral(value float64) *FloatLiteral {
+	lit := strconv.FormatFloat(value, 'g', -1, 64)
+	return &FloatLiteral{Token: token.NewExt(token.FLOAT, lit), Value: value}
+}
+
+func NewBooleanLiteral(value bool) *BooleanLiteral {
+	tt := token.FALSE
+	if value {
+		tt = token.TRUE
+	}
+	return &BooleanLiteral{Token: token.NewExt(tt, tt.String()), Value: value}
+}
+
+func NewPrefixExpression(operator string, right Expression) *PrefixExpression {
+	tt, _ := token.LookupOperator(operator)
+	return &PrefixExpression{Token: token.NewExt(tt, operator), Operator: operator, Right: right}
+}
+
+func NewInfixExpression(operator string, left, right Expression) *InfixExpression {
+	tt, _ := token.LookupOperator(operator)
+	return &InfixExpression{Token: token.NewExt(tt, operator), Operator: operator, Left: left, Right: right}
+}
+
+func NewCallExpression(function string, args ...Expression) *CallExpression {
+	return &CallExpression{
+		Token:     token.NewExt(token.LPAREN, "("),
+		Function:  NewIdentifier(function),
+		Arguments: args,
+	}
+}
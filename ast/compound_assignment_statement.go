@@ -0,0 +1,34 @@
+package ast
+
+import (
+	"gocalc/object"
+	"gocalc/token"
+)
+
+// CompoundAssignmentStatement applies an operator to a variable, x += 1
+type CompoundAssignmentStatement struct {
+	Token    token.Token // the assignment operator, token.PLUS_ASSIGN
+	Name     *Identifier
+	Operator string // the infix operator, + for +=
+	Value    Expression
+}
+
+func (cs *CompoundAssignmentStatement) statementNode()       {}
+func (cs *CompoundAssignmentStatement) TokenLiteral() string { return cs.Token.Literal }
+
+func (cs *CompoundAssignmentStatement) Accept(visit NodeVisitor) object.Object {
+	return visit.CompoundAssignmentStatement(cs)
+}
+
+// Infix is the expression computing the new value of the variable, x + 1 for x += 1
+func (cs *CompoundAssignmentStatement) Infix() *InfixExpression {
+	return &InfixExpression{Token: cs.Name.Token, Operator: cs.Operator, Left: cs.Name, Right: cs.Value}
+}
+
+func (cs *CompoundAssignmentStatement) String() string {
+	value := ""
+	if cs.Value != nil {
+		value = cs.Value.String()
+	}
+	return cs.Name.String() + " " + cs.Operator + "= " + value + ";"
+}
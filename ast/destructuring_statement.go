@@ -0,0 +1,47 @@
+package ast
+
+import (
+	"gocalc/object"
+	"gocalc/token"
+	"strings"
+)
+
+// DestructuringStatement assigns several variables at once, a, b = b, a or
+// [x, y] = l. A single value is unpacked into the variables
+type DestructuringStatement struct {
+	Token  token.Token // the first token of the statement
+	Names  []*Identifier
+	Values []Expression
+	List   bool // the names are written as a list, [x, y] = l
+}
+
+func (ds *DestructuringStatement) statementNode()       {}
+func (ds *DestructuringStatement) TokenLiteral() string { return ds.Token.Literal }
+
+func (ds *DestructuringStatement) Accept(visit NodeVisitor) object.Object {
+	return visit.DestructuringStatement(ds)
+}
+
+// Unpacks reports whether the variables take the elements of a single value
+func (ds *DestructuringStatement) Unpacks() bool {
+	return len(ds.Values) == 1 && (len(ds.Names) > 1 || ds.List)
+}
+
+func (ds *DestructuringStatement) String() string {
+	names := make([]string, len(ds.Names))
+	for i, n := range ds.Names {
+		names[i] = n.String()
+	}
+	values := make([]string, len(ds.Values))
+	for i, v := range ds.Values {
+		if v != nil {
+			values[i] = v.String()
+		}
+	}
+
+	lhs := strings.Join(names, ", ")
+	if ds.List {
+		lhs = "[" + lhs + "]"
+	}
+	return lhs + " = " + strings.Join(values, ", ") + ";"
+}
@@ -0,0 +1,18 @@
+package ast
+
+import (
+	"gocalc/calendar"
+	"gocalc/object"
+	"gocalc/token"
+	"time"
+)
+
+type DurationLiteral struct {
+	Token token.Token
+	Value time.Duration
+}
+
+func (dl *DurationLiteral) expressionNode()                        {}
+func (dl *DurationLiteral) TokenLiteral() string                   { return dl.Token.Literal }
+func (dl *DurationLiteral) String() string                         { return calendar.FormatDuration(dl.Value) }
+func (dl *DurationLiteral) Accept(visit NodeVisitor) object.Object { return visit.DurationLiteral(dl) }
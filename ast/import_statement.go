@@ -0,0 +1,32 @@
+package ast
+
+import (
+	"gocalc/object"
+	"gocalc/token"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+type ImportStatement struct {
+	Token token.Token // token.IMPORT
+	Path  string
+}
+
+func (is *ImportStatement) statementNode()       {}
+func (is *ImportStatement) TokenLiteral() string { return is.Token.Literal }
+
+func (is *ImportStatement) Accept(visit NodeVisitor) object.Object {
+	return visit.ImportStatement(is)
+}
+
+func (is *ImportStatement) String() string {
+	return "import " + strconv.Quote(is.Path) + ";"
+}
+
+// Name is the name the module is bound to: the base name of the file without
+// its extension, or the path itself for builtin modules
+func (is *ImportStatement) Name() string {
+	base := filepath.Base(is.Path)
+	return strings.TrimSuffix(base, filepath.Ext(base))
+}
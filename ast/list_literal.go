@@ -24,7 +24,7 @@ func (ll *ListLiteral) String() string {
 
 	for i, exp := range ll.Values {
 		out.WriteString(exp.String())
-		if i != len(ll.Values) {
+		if i != len(ll.Values)-1 {
 			out.WriteString(", ")
 		}
 	}
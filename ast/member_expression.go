@@ -0,0 +1,17 @@
+package ast
+
+import (
+	"gocalc/object"
+	"gocalc/token"
+)
+
+type MemberExpression struct {
+	Token  token.Token // the '.' token
+	Object Expression
+	Member *Identifier
+}
+
+func (me *MemberExpression) expressionNode()                    {}
+func (me *MemberExpression) TokenLiteral() string               { return me.Token.Literal }
+func (me *MemberExpression) Accept(v NodeVisitor) object.Object { return v.MemberExpression(me) }
+func (me *MemberExpression) String() string                     { return me.Object.String() + "." + me.Member.String() }
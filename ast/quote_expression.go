@@ -0,0 +1,19 @@
+package ast
+
+import (
+	"gocalc/object"
+	"gocalc/token"
+)
+
+type QuoteExpression struct {
+	Token      token.Token // token.QUOTE
+	Expression Expression
+}
+
+func (qe *QuoteExpression) expressionNode()      {}
+func (qe *QuoteExpression) TokenLiteral() string { return qe.Token.Literal }
+func (qe *QuoteExpression) String() string       { return "'" + qe.Expression.String() }
+
+func (qe *QuoteExpression) Accept(visit NodeVisitor) object.Object {
+	return visit.QuoteExpression(qe)
+}
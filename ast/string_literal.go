@@ -0,0 +1,17 @@
+package ast
+
+import (
+	"gocalc/object"
+	"gocalc/token"
+	"strconv"
+)
+
+type StringLiteral struct {
+	Token token.Token
+	Value string
+}
+
+func (sl *StringLiteral) expressionNode()                        {}
+func (sl *StringLiteral) TokenLiteral() string                   { return sl.Token.Literal }
+func (sl *StringLiteral) String() string                         { return strconv.Quote(sl.Value) }
+func (sl *StringLiteral) Accept(visit NodeVisitor) object.Object { return visit.StringLiteral(sl) }
@@ -0,0 +1,33 @@
+package ast
+
+import (
+	"gocalc/object"
+	"gocalc/token"
+	"strings"
+)
+
+// TypeStatement declares a record type, type Point = {x, y}
+type TypeStatement struct {
+	Token  token.Token // token.TYPE
+	Name   *Identifier
+	Fields []*Identifier
+}
+
+func (ts *TypeStatement) statementNode()       {}
+func (ts *TypeStatement) TokenLiteral() string { return ts.Token.Literal }
+
+func (ts *TypeStatement) Accept(visit NodeVisitor) object.Object {
+	return visit.TypeStatement(ts)
+}
+
+func (ts *TypeStatement) FieldNames() []string {
+	fields := make([]string, len(ts.Fields))
+	for i, f := range ts.Fields {
+		fields[i] = f.Value
+	}
+	return fields
+}
+
+func (ts *TypeStatement) String() string {
+	return "type " + ts.Name.String() + " = {" + strings.Join(ts.FieldNames(), ", ") + "};"
+}
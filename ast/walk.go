@@ -0,0 +1,68 @@
+package ast
+
+// Inspect traverses the tree rooted at node in depth-first order, calling f
+// for every node. Children are skipped when f returns false
+func Inspect(node Node, f func(Node) bool) {
+	if node == nil || !f(node) {
+		return
+	}
+
+	switch n := node.(type) {
+	case *Program:
+		for _, s := range n.Statements {
+			Inspect(s, f)
+		}
+	case *AssignmentStatement:
+		Inspect(n.Name, f)
+		if n.Annotation != nil {
+			Inspect(n.Annotation, f)
+		}
+		for _, name := range n.Chain {
+			Inspect(name, f)
+		}
+		inspectExpression(n.Value, f)
+	case *CompoundAssignmentStatement:
+		Inspect(n.Name, f)
+		inspectExpression(n.Value, f)
+	case *DestructuringStatement:
+		for _, name := range n.Names {
+			Inspect(name, f)
+		}
+		for _, v := range n.Values {
+			inspectExpression(v, f)
+		}
+	case *TypeStatement:
+		Inspect(n.Name, f)
+		for _, field := range n.Fields {
+			Inspect(field, f)
+		}
+	case *ExpressionStatement:
+		inspectExpression(n.Expression, f)
+	case *PrefixExpression:
+		inspectExpression(n.Right, f)
+	case *InfixExpression:
+		inspectExpression(n.Left, f)
+		inspectExpression(n.Right, f)
+	case *CallExpression:
+		inspectExpression(n.Function, f)
+		for _, a := range n.Arguments {
+			inspectExpression(a, f)
+		}
+	case *QuoteExpression:
+		inspectExpression(n.Expression, f)
+	case *MemberExpression:
+		inspectExpression(n.Object, f)
+		Inspect(n.Member, f)
+	case *ListLiteral:
+		for _, v := range n.Values {
+			inspectExpression(v, f)
+		}
+	}
+}
+
+// inspectExpression avoids passing typed nil expressions left by parse errors
+func inspectExpression(e Expression, f func(Node) bool) {
+	if e != nil {
+		Inspect(e, f)
+	}
+}
@@ -0,0 +1,226 @@
+// Package calendar implements the date and duration helpers of the calculator:
+// duration literals such as 3d 4h, strftime style formatting and business days.
+// The time zone database is bundled so conversions work on any system
+package calendar
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	_ "time/tzdata"
+)
+
+const Day = 24 * time.Hour
+
+type Unit struct {
+	Suffix string
+	Value  time.Duration
+}
+
+// Units lists the units of duration literals, longest suffix first
+var Units = []Unit{
+	{"ms", time.Millisecond},
+	{"w", 7 * Day},
+	{"d", Day},
+	{"h", time.Hour},
+	{"m", time.Minute},
+	{"s", time.Second},
+}
+
+// ParseDuration parses a sequence of numbers followed by a unit, such as
+// "3d 4h" or "1.5h". Spaces between the parts are optional
+func ParseDuration(s string) (time.Duration, error) {
+	rest := strings.TrimSpace(s)
+	if rest == "" {
+		return 0, fmt.Errorf("invalid duration %q", s)
+	}
+
+	total := 0.0
+	for rest != "" {
+		i := 0
+		for i < len(rest) && (rest[i] >= '0' && rest[i] <= '9' || rest[i] == '.') {
+			i++
+		}
+		n, err := strconv.ParseFloat(rest[:i], 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q", s)
+		}
+		rest = rest[i:]
+
+		unit, ok := unitPrefix(rest)
+		if !ok {
+			return 0, fmt.Errorf("invalid duration %q: missing unit", s)
+		}
+		total += n * float64(unit.Value)
+		rest = strings.TrimSpace(rest[len(unit.Suffix):])
+	}
+
+	if total > math.MaxInt64 {
+		return 0, fmt.Errorf("invalid duration %q: out of range", s)
+	}
+	return time.Duration(total), nil
+}
+
+// UnitAt returns the length of the unit at the start of s, or 0
+func UnitAt(s string) int {
+	if unit, ok := unitPrefix(s); ok {
+		return len(unit.Suffix)
+	}
+	return 0
+}
+
+func unitPrefix(s string) (Unit, bool) {
+	for _, u := range Units {
+		if strings.HasPrefix(s, u.Suffix) {
+			return u, true
+		}
+	}
+	return Unit{}, false
+}
+
+// FormatDuration writes d with days, hours, minutes, seconds and milliseconds,
+// in the syntax of duration literals
+func FormatDuration(d time.Duration) string {
+	if d == 0 {
+		return "0s"
+	}
+
+	var parts []string
+	sign := ""
+	if d < 0 {
+		sign, d = "-", -d
+	}
+	for _, u := range []Unit{{"d", Day}, {"h", time.Hour}, {"m", time.Minute}, {"s", time.Second}} {
+		if n := d / u.Value; n > 0 {
+			parts = append(parts, strconv.FormatInt(int64(n), 10)+u.Suffix)
+			d -= n * u.Value
+		}
+	}
+	if d > 0 {
+		ms := strconv.FormatFloat(float64(d)/float64(time.Millisecond), 'f', -1, 64)
+		parts = append(parts, ms+"ms")
+	}
+	return sign + strings.Join(parts, " ")
+}
+
+// FormatTime writes the date of t, followed by its time of day unless it is
+// midnight and by its zone unless it is UTC
+func FormatTime(t time.Time) string {
+	layout := "2006-01-02"
+	if t.Hour() != 0 || t.Minute() != 0 || t.Second() != 0 || t.Nanosecond() != 0 {
+		layout += " 15:04:05"
+	}
+	if t.Location() != time.UTC {
+		layout += " MST"
+	}
+	return t.Format(layout)
+}
+
+// ParseTime parses dates such as 2026-10-18, 2026-10-18 14:30, 2026-10-18 14:30:05
+// or RFC 3339 timestamps. Times without a zone are in loc
+func ParseTime(s string, loc *time.Location) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339Nano, s); err == nil {
+		return t, nil
+	}
+	for _, layout := range []string{"2006-01-02", "2006-01-02 15:04", "2006-01-02 15:04:05", "2006-01-02T15:04:05"} {
+		if t, err := time.ParseInLocation(layout, s, loc); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date %q, expected a date like 2026-10-18", s)
+}
+
+var strftime = map[byte]string{
+	'Y': "2006", 'y': "06", 'm': "01", 'd': "02", 'e': "_2",
+	'H': "15", 'I': "03", 'M': "04", 'S': "05", 'p': "PM",
+	'b': "Jan", 'B': "January", 'a': "Mon", 'A': "Monday",
+	'Z': "MST", 'z': "-0700", 'j': "002",
+}
+
+// Format writes t with a strftime style layout, such as %Y-%m-%d
+func Format(t time.Time, layout string) (string, error) {
+	var buf strings.Builder
+	for i := 0; i < len(layout); i++ {
+		if layout[i] != '%' {
+			buf.WriteByte(layout[i])
+			continue
+		}
+		if i++; i == len(layout) {
+			return "", fmt.Errorf("format %q ends with %%", layout)
+		}
+		if layout[i] == '%' {
+			buf.WriteByte('%')
+			continue
+		}
+		goLayout, ok := strftime[layout[i]]
+		if !ok {
+			return "", fmt.Errorf("unknown directive %%%c in format %q", layout[i], layout)
+		}
+		buf.WriteString(t.Format(goLayout))
+	}
+	return buf.String(), nil
+}
+
+// Add moves t by d, counting whole days as calendar days so that adding 1d
+// keeps the time of day across daylight saving changes
+func Add(t time.Time, d time.Duration) time.Time {
+	days := d / Day
+	return t.AddDate(0, 0, int(days)).Add(d - days*Day)
+}
+
+func IsWeekend(t time.Time) bool {
+	return t.Weekday() == time.Saturday || t.Weekday() == time.Sunday
+}
+
+// BusinessDays counts the weekdays from the day of start included to the day of end excluded,
+// negatively when end is before start
+func BusinessDays(start, end time.Time) int {
+	if end.Before(start) {
+		return -BusinessDays(end, start)
+	}
+	from := date(start)
+	to := date(end.In(start.Location()))
+
+	days := int(to.Sub(from).Round(Day) / Day)
+	weeks := days / 7
+	count := weeks * 5
+	for d := from.AddDate(0, 0, weeks*7); d.Before(to); d = d.AddDate(0, 0, 1) {
+		if !IsWeekend(d) {
+			count++
+		}
+	}
+	return count
+}
+
+// MaxBusinessDays bounds the weekdays AddBusinessDays can move by, about ten thousand years
+const MaxBusinessDays = 260 * 10000
+
+// AddBusinessDays moves t by n weekdays, skipping weekends
+func AddBusinessDays(t time.Time, n int) time.Time {
+	step := 1
+	if n < 0 {
+		step, n = -1, -n
+	}
+	for IsWeekend(t) && n > 0 {
+		t = t.AddDate(0, 0, step)
+		if !IsWeekend(t) {
+			n--
+		}
+	}
+	// from a weekday, five business days are a week
+	t = t.AddDate(0, 0, step*7*(n/5))
+	n %= 5
+	for n > 0 {
+		t = t.AddDate(0, 0, step)
+		if !IsWeekend(t) {
+			n--
+		}
+	}
+	return t
+}
+
+func date(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
@@ -0,0 +1,86 @@
+package calendar
+
+import (
+	"gocalc/testing_utils"
+	"testing"
+	"time"
+)
+
+func TestDurations(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected time.Duration
+		format   string
+	}{
+		{"3d 4h", 3*Day + 4*time.Hour, "3d 4h"},
+		{"3d4h", 3*Day + 4*time.Hour, "3d 4h"},
+		{"2w", 14 * Day, "14d"},
+		{"1.5h", 90 * time.Minute, "1h 30m"},
+		{"90m", 90 * time.Minute, "1h 30m"},
+		{"1s 250ms", 1250 * time.Millisecond, "1s 250ms"},
+		{"0s", 0, "0s"},
+	}
+
+	for _, tt := range tests {
+		d, err := ParseDuration(tt.input)
+		testingutils.Assert(t, err == nil, "%s: %v", tt.input, err)
+		testingutils.Equals(t, tt.expected, d, tt.input)
+		testingutils.Equals(t, tt.format, FormatDuration(d), "FormatDuration of "+tt.input)
+	}
+
+	testingutils.Equals(t, "-1d 2h", FormatDuration(-26*time.Hour), "negative durations")
+
+	for _, input := range []string{"", "3", "3x", "d"} {
+		_, err := ParseDuration(input)
+		testingutils.Assert(t, err != nil, "%q should not parse", input)
+	}
+}
+
+func TestTimes(t *testing.T) {
+	d, err := ParseTime("2026-10-18", time.UTC)
+	testingutils.Assert(t, err == nil, "ParseTime: %v", err)
+	testingutils.Equals(t, "2026-10-18", FormatTime(d), "date")
+
+	d, _ = ParseTime("2026-10-18 14:30", time.UTC)
+	testingutils.Equals(t, "2026-10-18 14:30:00", FormatTime(d), "date and time")
+
+	paris, _ := time.LoadLocation("Europe/Paris")
+	testingutils.Equals(t, "2026-10-18 16:30:00 CEST", FormatTime(d.In(paris)), "time zones")
+
+	d, _ = ParseTime("2026-10-18T14:30:00+02:00", time.UTC)
+	testingutils.Equals(t, "2026-10-18 12:30:00", FormatTime(d.UTC()), "RFC 3339")
+
+	_, err = ParseTime("18/10/2026", time.UTC)
+	testingutils.Equals(t, `invalid date "18/10/2026", expected a date like 2026-10-18`, err.Error(), "invalid date")
+
+	s, err := Format(d.UTC(), "%A %d %B %Y, %H:%M (100%%)")
+	testingutils.Assert(t, err == nil, "Format: %v", err)
+	testingutils.Equals(t, "Sunday 18 October 2026, 12:30 (100%)", s, "Format")
+
+	_, err = Format(d, "%Q")
+	testingutils.Assert(t, err != nil, "unknown directive")
+
+	// the last Sunday of October is 25 hours long in Paris
+	d = time.Date(2026, 10, 24, 12, 0, 0, 0, paris)
+	testingutils.Equals(t, "2026-10-25 12:00:00 CET", FormatTime(Add(d, Day)), "Add across DST")
+	testingutils.Equals(t, "2026-10-25 13:30:00 CET", FormatTime(Add(d, Day+90*time.Minute)), "Add days and hours")
+}
+
+func TestBusinessDays(t *testing.T) {
+	day := func(s string) time.Time {
+		d, _ := ParseTime(s, time.UTC)
+		return d
+	}
+
+	// 2026-10-16 is a Friday
+	testingutils.Equals(t, 1, BusinessDays(day("2026-10-16"), day("2026-10-19")), "friday to monday")
+	testingutils.Equals(t, 10, BusinessDays(day("2026-10-19"), day("2026-11-02")), "two weeks")
+	testingutils.Equals(t, -10, BusinessDays(day("2026-11-02"), day("2026-10-19")), "backwards")
+	testingutils.Equals(t, 0, BusinessDays(day("2026-10-17"), day("2026-10-19")), "weekend")
+
+	testingutils.Equals(t, day("2026-10-19"), AddBusinessDays(day("2026-10-16"), 1), "friday + 1")
+	testingutils.Equals(t, day("2026-10-23"), AddBusinessDays(day("2026-10-17"), 5), "saturday + 5")
+	testingutils.Equals(t, day("2026-10-30"), AddBusinessDays(day("2026-10-16"), 10), "friday + 10")
+	testingutils.Equals(t, day("2026-10-16"), AddBusinessDays(day("2026-10-19"), -1), "monday - 1")
+	testingutils.Equals(t, true, IsWeekend(day("2026-10-18")), "sunday")
+}
@@ -0,0 +1,68 @@
+package code
+
+import (
+	"gocalc/testing_utils"
+	"testing"
+)
+
+func TestMake(t *testing.T) {
+	tests := []struct {
+		op       Opcode
+		operands []int
+		expected []byte
+	}{
+		{OpConstant, []int{65534}, []byte{byte(OpConstant), 255, 254}},
+		{OpCall, []int{3}, []byte{byte(OpCall), 3}},
+		{OpAdd, []int{}, []byte{byte(OpAdd)}},
+	}
+
+	for _, tt := range tests {
+		testingutils.Equals(t, tt.expected, Make(tt.op, tt.operands...), "Make")
+	}
+}
+
+func TestInstructionsString(t *testing.T) {
+	instructions := []Instructions{
+		Make(OpConstant, 1),
+		Make(OpGetGlobal, 2),
+		Make(OpAdd),
+		Make(OpCall, 1),
+		Make(OpResult),
+	}
+
+	expected := `0000 OpConstant 1
+0003 OpGetGlobal 2
+0006 OpAdd
+0007 OpCall 1
+0009 OpResult
+`
+
+	concatted := Instructions{}
+	for _, ins := range instructions {
+		concatted = append(concatted, ins...)
+	}
+
+	testingutils.Equals(t, expected, concatted.String(), "Instructions.String()")
+}
+
+func TestReadOperands(t *testing.T) {
+	tests := []struct {
+		op        Opcode
+		operands  []int
+		bytesRead int
+	}{
+		{OpConstant, []int{65535}, 2},
+		{OpCall, []int{255}, 1},
+	}
+
+	for _, tt := range tests {
+		instruction := Make(tt.op, tt.operands...)
+
+		def, err := Lookup(tt.op)
+		testingutils.Assert(t, err == nil, "definition not found: %q", err)
+
+		operandsRead, n := ReadOperands(def, instruction[1:])
+		testingutils.Equals(t, tt.bytesRead, n, "bytes read")
+		testingutils.Equals(t, tt.operands, operandsRead, "operands")
+	}
+}
@@ -0,0 +1,343 @@
+package compiler
+
+import (
+	"fmt"
+	"gocalc/ast"
+	"gocalc/code"
+	"gocalc/object"
+	"math"
+)
+
+// Bytecode is the result of compiling a program
+type Bytecode struct {
+	Instructions   code.Instructions
+	Constants      []object.Object
+	Names          []string                      // identifiers referenced by OpGetGlobal, OpSetGlobal, OpDeclare and OpMember
+	Calls          []*ast.CallExpression         // call sites referenced by OpCallQuoted
+	Quotes         []*ast.QuoteExpression        // quoted expressions referenced by OpQuote
+	Imports        []*ast.ImportStatement        // imports referenced by OpImport
+	Declarations   []*ast.AssignmentStatement    // annotated, const or documented assignments referenced by OpDeclare
+	Destructurings []*ast.DestructuringStatement // destructurings referenced by OpDestructure
+	MaxDepth       int                           // deepest nesting of nodes, as counted by the tree walking evaluator
+}
+
+type Compiler struct {
+	instructions code.Instructions
+	constants    []object.Object
+	names        []string
+	calls        []*ast.CallExpression
+	quotes       []*ast.QuoteExpression
+	imports      []*ast.ImportStatement
+	declarations []*ast.AssignmentStatement
+	destructs    []*ast.DestructuringStatement
+	floats       map[uint64]int
+	nameIndex    map[string]int
+	depth        int
+	maxDepth     int
+}
+
+var infixOps = map[string]code.Opcode{
+	"+":  code.OpAdd,
+	"-":  code.OpSub,
+	"*":  code.OpMul,
+	"/":  code.OpDiv,
+	"^":  code.OpPow,
+	"==": code.OpEqual,
+	"!=": code.OpNotEqual,
+	">":  code.OpGreater,
+	">=": code.OpGreaterEqual,
+	"<":  code.OpLess,
+	"<=": code.OpLessEqual,
+	"&&": code.OpAnd,
+	"||": code.OpOr,
+}
+
+var prefixOps = map[string]code.Opcode{
+	"-": code.OpMinus,
+	"!": code.OpBang,
+}
+
+func New() *Compiler {
+	return &Compiler{
+		instructions: code.Instructions{},
+		constants:    []object.Object{},
+		names:        []string{},
+		floats:       map[uint64]int{},
+		nameIndex:    map[string]int{},
+	}
+}
+
+// Compile compiles a whole program
+func Compile(program *ast.Program) (*Bytecode, error) {
+	c := New()
+	if err := c.Compile(program); err != nil {
+		return nil, err
+	}
+	return c.Bytecode(), nil
+}
+
+func (c *Compiler) Compile(node ast.Node) error {
+	if _, ok := node.(*ast.Program); !ok {
+		c.depth++
+		if c.depth > c.maxDepth {
+			c.maxDepth = c.depth
+		}
+		defer func() { c.depth-- }()
+	}
+
+	switch node := node.(type) {
+	case *ast.Program:
+		for _, s := range node.Statements {
+			if err := c.Compile(s); err != nil {
+				return err
+			}
+		}
+
+	case *ast.ExpressionStatement:
+		if err := c.Compile(node.Expression); err != nil {
+			return err
+		}
+		c.emit(code.OpResult)
+
+	case *ast.AssignmentStatement:
+		if err := c.Compile(node.Value); err != nil {
+			return err
+		}
+		// chained variables are assigned from right to left, each one reading
+		// the value back for the next
+		names := node.Names()
+		for i := len(names) - 1; i >= 0; i-- {
+			if i < len(names)-1 {
+				if err := c.emitName(code.OpGetGlobal, names[i+1].Value); err != nil {
+					return err
+				}
+			}
+			if err := c.emitAssignment(names[i].Value, node); err != nil {
+				return err
+			}
+		}
+
+	case *ast.CompoundAssignmentStatement:
+		if err := c.Compile(node.Infix()); err != nil {
+			return err
+		}
+		return c.emitName(code.OpSetGlobal, node.Name.Value)
+
+	case *ast.DestructuringStatement:
+		for _, v := range node.Values {
+			if err := c.Compile(v); err != nil {
+				return err
+			}
+		}
+		if len(c.destructs) > math.MaxUint16 {
+			return fmt.Errorf("too many destructurings")
+		}
+		c.emit(code.OpDestructure, len(c.destructs))
+		c.destructs = append(c.destructs, node)
+
+	case *ast.TypeStatement:
+		if err := c.emitConstant(object.NewRecordType(node.Name.Value, node.FieldNames()...)); err != nil {
+			return err
+		}
+		return c.emitName(code.OpSetGlobal, node.Name.Value)
+
+	case *ast.ImportStatement:
+		if len(c.imports) > math.MaxUint16 {
+			return fmt.Errorf("too many imports")
+		}
+		c.emit(code.OpImport, len(c.imports))
+		c.imports = append(c.imports, node)
+
+	case *ast.InfixExpression:
+		op, ok := infixOps[node.Operator]
+		if !ok {
+			return fmt.Errorf("unknown operator %s", node.Operator)
+		}
+		// the tree walking evaluator evaluates the right operand first
+		if err := c.Compile(node.Right); err != nil {
+			return err
+		}
+		if err := c.Compile(node.Left); err != nil {
+			return err
+		}
+		c.emit(op)
+
+	case *ast.PrefixExpression:
+		op, ok := prefixOps[node.Operator]
+		if !ok {
+			return fmt.Errorf("unknown operator %s", node.Operator)
+		}
+		if err := c.Compile(node.Right); err != nil {
+			return err
+		}
+		c.emit(op)
+
+	case *ast.FloatLiteral:
+		return c.emitFloat(node.Value)
+
+	case *ast.StringLiteral:
+		return c.emitConstant(&object.String{Value: node.Value})
+
+	case *ast.DurationLiteral:
+		return c.emitConstant(&object.Duration{Value: node.Value})
+
+	case *ast.BooleanLiteral:
+		if node.Value {
+			c.emit(code.OpTrue)
+		} else {
+			c.emit(code.OpFalse)
+		}
+
+	case *ast.Identifier:
+		return c.emitName(code.OpGetGlobal, node.Value)
+
+	case *ast.ListLiteral:
+		if len(node.Values) > math.MaxUint16 {
+			return fmt.Errorf("list literal too long (%d elements)", len(node.Values))
+		}
+		for _, v := range node.Values {
+			if err := c.Compile(v); err != nil {
+				return err
+			}
+		}
+		c.emit(code.OpList, len(node.Values))
+
+	case *ast.CallExpression:
+		if len(node.Arguments) > math.MaxUint8 {
+			return fmt.Errorf("too many arguments (%d)", len(node.Arguments))
+		}
+		if id, ok := node.Function.(*ast.Identifier); ok {
+			if err := c.emitName(code.OpGetGlobal, id.Value); err != nil {
+				return err
+			}
+		} else if err := c.Compile(node.Function); err != nil {
+			return err
+		}
+		// natives taking their arguments unevaluated are called with the call site
+		// and skip the evaluation of the arguments
+		if len(c.calls) > math.MaxUint16 {
+			return fmt.Errorf("too many calls")
+		}
+		site := len(c.calls)
+		quoted := c.emit(code.OpCallQuoted, site, 0)
+		c.calls = append(c.calls, node)
+		for _, a := range node.Arguments {
+			if err := c.Compile(a); err != nil {
+				return err
+			}
+		}
+		c.emit(code.OpCall, len(node.Arguments))
+		c.changeOperands(quoted, site, len(c.instructions))
+
+	case *ast.MemberExpression:
+		if err := c.Compile(node.Object); err != nil {
+			return err
+		}
+		return c.emitName(code.OpMember, node.Member.Value)
+
+	case *ast.QuoteExpression:
+		if len(c.quotes) > math.MaxUint16 {
+			return fmt.Errorf("too many quoted expressions")
+		}
+		c.emit(code.OpQuote, len(c.quotes))
+		c.quotes = append(c.quotes, node)
+
+	default:
+		return fmt.Errorf("cannot compile node %T", node)
+	}
+
+	return nil
+}
+
+func (c *Compiler) Bytecode() *Bytecode {
+	return &Bytecode{
+		Instructions:   c.instructions,
+		Constants:      c.constants,
+		Names:          c.names,
+		Calls:          c.calls,
+		Quotes:         c.quotes,
+		Imports:        c.imports,
+		Declarations:   c.declarations,
+		Destructurings: c.destructs,
+		MaxDepth:       c.maxDepth,
+	}
+}
+
+// emitFloat pools float constants so that every value is stored once
+func (c *Compiler) emitFloat(val float64) error {
+	bits := math.Float64bits(val)
+	if idx, ok := c.floats[bits]; ok {
+		c.emit(code.OpConstant, idx)
+		return nil
+	}
+
+	c.floats[bits] = len(c.constants)
+	return c.emitConstant(&object.Float{Value: val})
+}
+
+func (c *Compiler) emitConstant(obj object.Object) error {
+	idx := len(c.constants)
+	if idx > math.MaxUint16 {
+		return fmt.Errorf("too many constants")
+	}
+	c.constants = append(c.constants, obj)
+
+	c.emit(code.OpConstant, idx)
+	return nil
+}
+
+func (c *Compiler) emitName(op code.Opcode, name string) error {
+	idx, err := c.nameIndexOf(name)
+	if err != nil {
+		return err
+	}
+
+	c.emit(op, idx)
+	return nil
+}
+
+// emitAssignment assigns the value on top of the stack to name. Annotated,
+// constant and documented variables are declared with as, so that the evaluator
+// checks and records them
+func (c *Compiler) emitAssignment(name string, as *ast.AssignmentStatement) error {
+	idx, err := c.nameIndexOf(name)
+	if err != nil {
+		return err
+	}
+	if as.Annotation == nil && !as.Const && as.Doc == "" {
+		c.emit(code.OpSetGlobal, idx)
+		return nil
+	}
+
+	if len(c.declarations) > math.MaxUint16 {
+		return fmt.Errorf("too many declarations")
+	}
+	c.emit(code.OpDeclare, len(c.declarations), idx)
+	c.declarations = append(c.declarations, as)
+	return nil
+}
+
+// nameIndexOf returns the index of name in the names table, adding it if needed
+func (c *Compiler) nameIndexOf(name string) (int, error) {
+	idx, ok := c.nameIndex[name]
+	if !ok {
+		idx = len(c.names)
+		if idx > math.MaxUint16 {
+			return 0, fmt.Errorf("too many identifiers")
+		}
+		c.names = append(c.names, name)
+		c.nameIndex[name] = idx
+	}
+	return idx, nil
+}
+
+func (c *Compiler) changeOperands(pos int, operands ...int) {
+	op := code.Opcode(c.instructions[pos])
+	copy(c.instructions[pos:], code.Make(op, operands...))
+}
+
+func (c *Compiler) emit(op code.Opcode, operands ...int) int {
+	pos := len(c.instructions)
+	c.instructions = append(c.instructions, code.Make(op, operands...)...)
+	return pos
+}
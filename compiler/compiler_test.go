@@ -0,0 +1,183 @@
+package compiler
+
+import (
+	"gocalc/code"
+	"gocalc/lexer"
+	"gocalc/parser"
+	"gocalc/testing_utils"
+	"testing"
+)
+
+func TestCompile(t *testing.T) {
+	tests := []struct {
+		input     string
+		constants []string
+		names     []string
+		expected  []code.Instructions
+	}{
+		{
+			"1 + 2",
+			[]string{"2", "1"},
+			[]string{},
+			[]code.Instructions{
+				code.Make(code.OpConstant, 0),
+				code.Make(code.OpConstant, 1),
+				code.Make(code.OpAdd),
+				code.Make(code.OpResult),
+			},
+		},
+		{
+			"x = 2; x * 2 == -x",
+			[]string{"2"},
+			[]string{"x"},
+			[]code.Instructions{
+				code.Make(code.OpConstant, 0),
+				code.Make(code.OpSetGlobal, 0),
+				code.Make(code.OpGetGlobal, 0),
+				code.Make(code.OpMinus),
+				code.Make(code.OpConstant, 0),
+				code.Make(code.OpGetGlobal, 0),
+				code.Make(code.OpMul),
+				code.Make(code.OpEqual),
+				code.Make(code.OpResult),
+			},
+		},
+		{
+			"len([true, false]); !true",
+			[]string{},
+			[]string{"len"},
+			[]code.Instructions{
+				code.Make(code.OpGetGlobal, 0),
+				code.Make(code.OpCallQuoted, 0, 15),
+				code.Make(code.OpTrue),
+				code.Make(code.OpFalse),
+				code.Make(code.OpList, 2),
+				code.Make(code.OpCall, 1),
+				code.Make(code.OpResult),
+				code.Make(code.OpTrue),
+				code.Make(code.OpBang),
+				code.Make(code.OpResult),
+			},
+		},
+		{
+			`import "math"; math.sin(0)`,
+			[]string{"0"},
+			[]string{"math", "sin"},
+			[]code.Instructions{
+				code.Make(code.OpImport, 0),
+				code.Make(code.OpGetGlobal, 0),
+				code.Make(code.OpMember, 1),
+				code.Make(code.OpCallQuoted, 0, 19),
+				code.Make(code.OpConstant, 0),
+				code.Make(code.OpCall, 1),
+				code.Make(code.OpResult),
+			},
+		},
+		{
+			"x: Float = 1",
+			[]string{"1"},
+			[]string{"x"},
+			[]code.Instructions{
+				code.Make(code.OpConstant, 0),
+				code.Make(code.OpDeclare, 0, 0),
+			},
+		},
+		{
+			"const x = 1",
+			[]string{"1"},
+			[]string{"x"},
+			[]code.Instructions{
+				code.Make(code.OpConstant, 0),
+				code.Make(code.OpDeclare, 0, 0),
+			},
+		},
+		{
+			"# one\nx = 1",
+			[]string{"1"},
+			[]string{"x"},
+			[]code.Instructions{
+				code.Make(code.OpConstant, 0),
+				code.Make(code.OpDeclare, 0, 0),
+			},
+		},
+		{
+			"x += 2",
+			[]string{"2"},
+			[]string{"x"},
+			[]code.Instructions{
+				code.Make(code.OpConstant, 0),
+				code.Make(code.OpGetGlobal, 0),
+				code.Make(code.OpAdd),
+				code.Make(code.OpSetGlobal, 0),
+			},
+		},
+		{
+			"a, b = b, a",
+			[]string{},
+			[]string{"b", "a"},
+			[]code.Instructions{
+				code.Make(code.OpGetGlobal, 0),
+				code.Make(code.OpGetGlobal, 1),
+				code.Make(code.OpDestructure, 0),
+			},
+		},
+		{
+			"a, b = l",
+			[]string{},
+			[]string{"l"},
+			[]code.Instructions{
+				code.Make(code.OpGetGlobal, 0),
+				code.Make(code.OpDestructure, 0),
+			},
+		},
+		{
+			"a = b = 0",
+			[]string{"0"},
+			[]string{"b", "a"},
+			[]code.Instructions{
+				code.Make(code.OpConstant, 0),
+				code.Make(code.OpSetGlobal, 0),
+				code.Make(code.OpGetGlobal, 0),
+				code.Make(code.OpSetGlobal, 1),
+			},
+		},
+		{
+			"'(x + 1)",
+			[]string{},
+			[]string{},
+			[]code.Instructions{
+				code.Make(code.OpQuote, 0),
+				code.Make(code.OpResult),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		p := parser.New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		testingutils.Assert(t, !p.HasErrors(), "parser errors: %v", p.Errors())
+
+		bc, err := Compile(program)
+		testingutils.Assert(t, err == nil, "compiler error: %s", err)
+
+		expected := code.Instructions{}
+		for _, ins := range tt.expected {
+			expected = append(expected, ins...)
+		}
+		testingutils.Equals(t, expected.String(), bc.Instructions.String(), tt.input)
+
+		constants := []string{}
+		for _, c := range bc.Constants {
+			constants = append(constants, c.String())
+		}
+		testingutils.Equals(t, tt.constants, constants, "constants of "+tt.input)
+		testingutils.Equals(t, tt.names, bc.Names, "names of "+tt.input)
+	}
+}
+
+func TestMaxDepth(t *testing.T) {
+	p := parser.New(lexer.New("1; -(-1); [len([1])]"))
+	bc, err := Compile(p.ParseProgram())
+	testingutils.Assert(t, err == nil, "compiler error: %s", err)
+	testingutils.Equals(t, 5, bc.MaxDepth, "bc.MaxDepth")
+}
@@ -3,32 +3,174 @@ package environment
 import (
 	"bytes"
 	"gocalc/object"
+	"sort"
+	"sync"
 )
 
+// Environment binds names to objects. Lookups fall back to the enclosing
+// environment, while Set only ever writes to the receiver, so an enclosing
+// environment shared between several children is never modified by them.
+// Environments are safe for concurrent use
 type Environment struct {
-	store map[string]object.Object
+	mu       sync.RWMutex
+	store    map[string]object.Object
+	consts   map[string]bool
+	declared map[string]*object.Type
+	docs     map[string]string
+	parent   *Environment
 }
 
 func New() *Environment {
 	s := make(map[string]object.Object)
-	return &Environment{s}
+	return &Environment{store: s, consts: map[string]bool{}, declared: map[string]*object.Type{}, docs: map[string]string{}}
 }
 
+func NewEnclosed(parent *Environment) *Environment {
+	env := New()
+	env.parent = parent
+	return env
+}
+
+func (e *Environment) Parent() *Environment { return e.parent }
+
 func (e *Environment) Get(ident string) (object.Object, bool) {
+	e.mu.RLock()
 	res, ok := e.store[ident]
+	e.mu.RUnlock()
+
+	if !ok && e.parent != nil {
+		return e.parent.Get(ident)
+	}
 	return res, ok
 }
 
 func (e *Environment) Set(name string, obj object.Object) object.Object {
+	e.mu.Lock()
 	e.store[name] = obj
+	delete(e.consts, name)
+	delete(e.declared, name)
+	e.mu.Unlock()
 	return obj
 }
 
+// SetConst binds name to obj and marks the binding as constant, see IsConst
+func (e *Environment) SetConst(name string, obj object.Object) object.Object {
+	e.mu.Lock()
+	e.store[name] = obj
+	e.consts[name] = true
+	delete(e.declared, name)
+	e.mu.Unlock()
+	return obj
+}
+
+// IsConst reports whether the visible binding of name is constant. Set does
+// not check it, callers decide whether constants can be shadowed
+func (e *Environment) IsConst(name string) bool {
+	e.mu.RLock()
+	_, ok := e.store[name]
+	isConst := e.consts[name]
+	e.mu.RUnlock()
+
+	if !ok && e.parent != nil {
+		return e.parent.IsConst(name)
+	}
+	return isConst
+}
+
+// Declare records the type the values of name must have, which callers enforce.
+// Set and SetConst forget it
+func (e *Environment) Declare(name string, typ *object.Type) {
+	e.mu.Lock()
+	e.declared[name] = typ
+	e.mu.Unlock()
+}
+
+// Declared returns the declared type of the visible binding of name, see Declare
+func (e *Environment) Declared(name string) (*object.Type, bool) {
+	e.mu.RLock()
+	_, ok := e.store[name]
+	typ, declared := e.declared[name]
+	e.mu.RUnlock()
+
+	if !ok && e.parent != nil {
+		return e.parent.Declared(name)
+	}
+	return typ, declared
+}
+
+// SetDoc documents the binding of name in the receiver, see Doc
+func (e *Environment) SetDoc(name, doc string) {
+	e.mu.Lock()
+	e.docs[name] = doc
+	e.mu.Unlock()
+}
+
+// Doc returns the documentation of the visible binding of name
+func (e *Environment) Doc(name string) string {
+	e.mu.RLock()
+	_, ok := e.store[name]
+	doc := e.docs[name]
+	e.mu.RUnlock()
+
+	if !ok && e.parent != nil {
+		return e.parent.Doc(name)
+	}
+	return doc
+}
+
+// Delete removes the binding of name from the receiver, making the enclosing
+// binding visible again if there is one
+func (e *Environment) Delete(name string) {
+	e.mu.Lock()
+	delete(e.store, name)
+	delete(e.consts, name)
+	delete(e.declared, name)
+	delete(e.docs, name)
+	e.mu.Unlock()
+}
+
+// Locals returns the bindings of the receiver, without the enclosing ones
+func (e *Environment) Locals() map[string]object.Object {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	res := make(map[string]object.Object, len(e.store))
+	for name, value := range e.store {
+		res[name] = value
+	}
+	return res
+}
+
+// Bindings returns every visible binding, including the enclosing ones
+func (e *Environment) Bindings() map[string]object.Object {
+	var res map[string]object.Object
+	if e.parent != nil {
+		res = e.parent.Bindings()
+	} else {
+		res = make(map[string]object.Object)
+	}
+
+	e.mu.RLock()
+	for name, value := range e.store {
+		res[name] = value
+	}
+	e.mu.RUnlock()
+	return res
+}
+
 func (e *Environment) String() string {
 	var buff bytes.Buffer
 
+	bindings := e.Bindings()
+	names := make([]string, 0, len(bindings))
+	for name := range bindings {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
 	buff.WriteString("Environment inspection")
-	for name, value := range e.store {
+	for _, name := range names {
+		value := bindings[name]
 		buff.WriteString("\n\t")
 		buff.WriteString(name)
 		buff.WriteString(": ")
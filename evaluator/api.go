@@ -0,0 +1,86 @@
+package evaluator
+
+import (
+	"context"
+	"fmt"
+	"gocalc/environment"
+	"gocalc/object"
+)
+
+// NewWithLibraries creates an evaluator that only has access to the natives
+// of the given libraries
+func NewWithLibraries(names ...string) (*Evaluator, error) {
+	ev := &Evaluator{base: environment.New(), engine: DefaultEngine, random: newRandom(), modules: newModules(names), evalState: newEvalState(context.Background())}
+	ev.natives = environment.NewEnclosed(ev.base)
+	ev.global = environment.NewEnclosed(ev.natives)
+
+	for _, name := range names {
+		lib, ok := library(name)
+		if !ok {
+			return nil, fmt.Errorf("unknown library %q", name)
+		}
+		if lib.Namespaced {
+			continue
+		}
+		for member, obj := range lib.Members {
+			ev.base.Set(member, obj)
+		}
+	}
+
+	return ev, nil
+}
+
+// Session returns an evaluator with its own variables that shares the base
+// environment and limits of ev. It sees the natives registered in ev, while
+// the ones registered in the session stay in it
+func (ev *Evaluator) Session() *Evaluator {
+	session := ev.fork(context.Background())
+	session.natives = environment.NewEnclosed(ev.natives)
+	session.global = environment.NewEnclosed(session.natives)
+	return session
+}
+
+// Register makes fn callable as name in ev and its sessions. If sig is not nil,
+// calls are checked against it before fn is invoked
+func (ev *Evaluator) Register(name string, sig *Signature, fn NativeFn) {
+	nf := newNativeFunction(fn, name)
+	nf.Signature = sig
+	ev.natives.Set(name, nf)
+}
+
+// SetVar binds name to the object representation of a Go value
+func (ev *Evaluator) SetVar(name string, value interface{}) error {
+	obj, err := object.FromGo(value)
+	if err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+
+	ev.global.Set(name, obj)
+	return nil
+}
+
+// GetVar returns the value bound to name converted to a Go value
+func (ev *Evaluator) GetVar(name string) (interface{}, error) {
+	obj, ok := ev.global.Get(name)
+	if !ok {
+		return nil, newError(object.IDENTIFIER_NOT_FOUND_ERROR, name)
+	}
+
+	return object.ToGo(obj)
+}
+
+// Evaluate evaluates input and converts the result to a Go value.
+// Evaluation failures are returned as *object.Error
+func (ev *Evaluator) Evaluate(input string) (interface{}, error) {
+	return ev.EvaluateContext(context.Background(), input)
+}
+
+// EvaluateContext is like Evaluate but stops once ctx is done
+func (ev *Evaluator) EvaluateContext(ctx context.Context, input string) (interface{}, error) {
+	res := ev.EvalContext(ctx, input)
+	if err, ok := res.(*object.Error); ok {
+		return nil, err
+	}
+
+	return object.ToGo(res)
+}
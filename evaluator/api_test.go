@@ -0,0 +1,115 @@
+package evaluator
+
+import (
+	"gocalc/object"
+	"gocalc/testing_utils"
+	"testing"
+)
+
+func TestNewWithLibraries(t *testing.T) {
+	ev, err := NewWithLibraries("lists")
+	testingutils.Assert(t, err == nil, "unexpected error %v", err)
+	testFloatObject(t, ev.Eval("len([1, 2])"), 2)
+
+	res := ev.Eval("sin(0)")
+	errObj, ok := res.(*object.Error)
+	testingutils.Assert(t, ok, "no error object returned, got %T", res)
+	testingutils.Equals(t, "Identifier not found sin", errObj.Message, "Error message")
+
+	_, err = NewWithLibraries("nope")
+	testingutils.Assert(t, err != nil, "expected error for unknown library")
+}
+
+func TestRegister(t *testing.T) {
+	ev := New()
+	sig := &Signature{Params: []object.ObjectType{object.FLOAT, object.FLOAT}}
+	ev.Register("hypot", sig, func(ev *Evaluator, args ...object.Object) object.Object {
+		x, y := args[0].(*object.Float).Value, args[1].(*object.Float).Value
+		return newFloat(x*x + y*y)
+	})
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"hypot(3, 4)", "25"},
+		{"hypot(3)", "hypot expects arguments (Float, Float), got 1"},
+		{"hypot(3, true)", "Argument 2 of hypot must be of type Float, got Bool"},
+		{"hypot(3, 1 / 0)", "Cannot divide by zero (1 / 0)"},
+	}
+	for _, tt := range tests {
+		testingutils.Equals(t, tt.expected, ev.Eval(tt.input).String(), tt.input)
+	}
+
+	ev.Register("count", &Signature{Params: []object.ObjectType{object.ANY}, Variadic: true},
+		func(ev *Evaluator, args ...object.Object) object.Object {
+			return newFloat(float64(len(args)))
+		})
+	testFloatObject(t, ev.Eval("count(1, true, [])"), 3)
+	testFloatObject(t, ev.Eval("count()"), 0)
+
+	ev.Register("failed", &Signature{Params: []object.ObjectType{object.ERROR}},
+		func(ev *Evaluator, args ...object.Object) object.Object {
+			return object.NewString("caught " + args[0].String())
+		})
+	testingutils.Equals(t, "caught Cannot divide by zero (1 / 0)", ev.Eval("failed(1 / 0)").String(), "error parameter")
+}
+
+func TestNativeArguments(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"str(1 / 0)", "Cannot divide by zero (1 / 0)"},
+		{"bool(1 / 0)", "Cannot divide by zero (1 / 0)"},
+		{"list(1 / 0)", "Cannot divide by zero (1 / 0)"},
+		{"import \"stats\"; stats.mean(1 / 0)", "Cannot divide by zero (1 / 0)"},
+		{"import \"random\"; random.choice(1 / 0)", "Cannot divide by zero (1 / 0)"},
+		{"import \"linalg\"; linalg.vector(1 / 0)", "Cannot divide by zero (1 / 0)"},
+		{"import \"linalg\"; linalg.norm(1 / 0)", "Cannot divide by zero (1 / 0)"},
+		{"is(1 / 0, Float)", "Cannot divide by zero (1 / 0)"},
+		{"sin()", "sin expects arguments (Any), got 0"},
+		{"sin(1, 2)", "sin expects arguments (Any), got 2"},
+		{"tail([])", "tail can only be applied to non empty lists"},
+		{"get([1, 2], -1)", "Index -1 not found (len = 2)"},
+		{"get([1, 2], 2)", "Index 2 not found (len = 2)"},
+	}
+
+	for _, tt := range tests {
+		res := testEval(tt.input)
+		testingutils.Assert(t, res != nil, "%s: no result", tt.input)
+		testingutils.Equals(t, tt.expected, res.String(), tt.input)
+	}
+}
+
+func TestGoValues(t *testing.T) {
+	ev := New()
+	testingutils.Assert(t, ev.SetVar("rate", 0.5) == nil, "SetVar(rate)")
+	testingutils.Assert(t, ev.SetVar("prices", []float64{10, 20}) == nil, "SetVar(prices)")
+	testingutils.Assert(t, ev.SetVar("opts", map[string]interface{}{"on": true}) == nil, "SetVar(opts)")
+	testingutils.Assert(t, ev.SetVar("ch", make(chan int)) != nil, "SetVar(ch) should fail")
+
+	res, err := ev.Evaluate("rate * 4")
+	testingutils.Assert(t, err == nil, "unexpected error %v", err)
+	testingutils.Equals(t, 2.0, res, "rate * 4")
+
+	res, err = ev.Evaluate("[rate, head(prices)]")
+	testingutils.Assert(t, err == nil, "unexpected error %v", err)
+	testingutils.Equals(t, []float64{0.5, 10}, res, "[rate, head(prices)]")
+
+	res, err = ev.GetVar("opts")
+	testingutils.Assert(t, err == nil, "unexpected error %v", err)
+	testingutils.Equals(t, map[string]interface{}{"on": true}, res, "opts")
+
+	testingutils.Assert(t, ev.SetVar("m", [][]float64{{1, 2}, {3, 4}}) == nil, "SetVar(m)")
+	res, err = ev.Evaluate(`import "linalg"; linalg.transpose(m)`)
+	testingutils.Assert(t, err == nil, "unexpected error %v", err)
+	testingutils.Equals(t, [][]float64{{1, 3}, {2, 4}}, res, "transpose(m)")
+
+	_, err = ev.Evaluate("1 / 0")
+	_, ok := err.(*object.Error)
+	testingutils.Assert(t, ok, "expected *object.Error, got %T", err)
+
+	_, err = ev.GetVar("missing")
+	testingutils.Assert(t, err != nil, "expected error for missing variable")
+}
@@ -0,0 +1,101 @@
+package evaluator
+
+import (
+	"gocalc/ast"
+	"gocalc/object"
+)
+
+// SetEcho enables or disables echoing assignments: programs ending with an
+// assignment evaluate to the assigned value instead of nothing
+func (ev *Evaluator) SetEcho(echo bool) { ev.echo = echo }
+
+func (ev *Evaluator) Echo() bool { return ev.echo }
+
+// echoed returns the value assigned by the last statement of program, the
+// values of destructurings as a list, or nil for other statements
+func (ev *Evaluator) echoed(program *ast.Program) object.Object {
+	if len(program.Statements) == 0 {
+		return nil
+	}
+
+	var names []*ast.Identifier
+	switch s := program.Statements[len(program.Statements)-1].(type) {
+	case *ast.AssignmentStatement:
+		names = []*ast.Identifier{s.Name}
+	case *ast.CompoundAssignmentStatement:
+		names = []*ast.Identifier{s.Name}
+	case *ast.DestructuringStatement:
+		names = s.Names
+	default:
+		return nil
+	}
+
+	values := make([]object.Object, len(names))
+	for i, name := range names {
+		values[i], _ = ev.global.Get(name.Value)
+	}
+	if len(values) == 1 {
+		return values[0]
+	}
+	return &object.List{Values: values}
+}
+
+func (ev *Evaluator) CompoundAssignmentStatement(cs *ast.CompoundAssignmentStatement) object.Object {
+	val := ev.evaluate(cs.Infix())
+	if isError(val) {
+		return val
+	}
+	return ev.assign(cs.Name.Value, val, nil)
+}
+
+// DestructuringStatement evaluates every value before assigning them, so
+// a, b = b, a swaps the variables
+func (ev *Evaluator) DestructuringStatement(ds *ast.DestructuringStatement) object.Object {
+	values := make([]object.Object, len(ds.Values))
+	for i, v := range ds.Values {
+		values[i] = ev.evaluate(v)
+	}
+	return ev.destructure(ds, values)
+}
+
+// destructure assigns the values of ds, or the elements of its single value.
+// Nothing is assigned if one of them is an error or cannot be assigned, and
+// the variables are assigned from right to left
+func (ev *Evaluator) destructure(ds *ast.DestructuringStatement, values []object.Object) object.Object {
+	if ds.Unpacks() {
+		var err object.Object
+		if values, err = unpack(values[0], len(ds.Names)); err != nil {
+			return err
+		}
+	}
+	for i, v := range values {
+		if isError(v) {
+			return v
+		}
+		if _, err := ev.assignable(ds.Names[i].Value, v, nil); err != nil {
+			return err
+		}
+	}
+
+	for i := len(ds.Names) - 1; i >= 0; i-- {
+		if err := ev.assign(ds.Names[i].Value, values[i], nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// unpack returns the n elements of a list, vector or matrix
+func unpack(obj object.Object, n int) ([]object.Object, object.Object) {
+	if isError(obj) {
+		return nil, obj
+	}
+	l, ok := asList(obj)
+	if !ok {
+		return nil, newError(object.UNPACK_TYPE_ERROR, obj.Type())
+	}
+	if len(l.Values) != n {
+		return nil, newError(object.UNPACK_LENGTH_ERROR, len(l.Values), n)
+	}
+	return l.Values, nil
+}
@@ -0,0 +1,83 @@
+package evaluator
+
+import (
+	"gocalc/testing_utils"
+	"testing"
+)
+
+func TestAssignments(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"x = 1; x += 2; x", "3"},
+		{"x = 10; x -= 2 * 3; x", "4"},
+		{"x = 3; x *= 2; x /= 4; x", "1.5"},
+		{"x = 2; x ^= 10; x", "1024"},
+		{`s = "a"; s += "b"; s`, "ab"},
+		{"l = [1, 2]; l *= 2; l", "[2, 4]"},
+		{"y += 1", "Identifier not found y"},
+		{"const c = 1; c += 1", "Cannot assign to constant c"},
+		{"x = true; x += 1", "Unknown operator Bool + Float"},
+		{"a, b = 1, 2; [a, b]", "[1, 2]"},
+		{"a = 1; b = 2; a, b = b, a; [a, b]", "[2, 1]"},
+		{"a, b, c = [1, 2, 3]; a + b * c", "7"},
+		{"[x, y] = [3, 4]; x * y", "12"},
+		{"[x] = [5]; x", "5"},
+		{"import \"linalg\"; x, y = linalg.vector([1, 2]); y", "2"},
+		{"r1, r2 = [[1, 2], [3, 4]]; r2", "[3, 4]"},
+		{"x, y = [1, 2, 3]", "Cannot unpack 3 values into 2 variables"},
+		{"x, y = 1", "Cannot unpack value of type Float"},
+		{"x, y = nope", "Identifier not found nope"},
+		{"x, y = 1, nope", "Identifier not found nope"},
+		{"a = 1; b = 1; a, b = zz, 2", "Identifier not found zz"},
+		{"a = 1; b = 1; x, l = 0, [1]; a, b = 2, l + true", "Unknown operator Float + Bool"},
+		{"a = b = 0; [a, b]", "[0, 0]"},
+		{"a = b = c = 2; a + b + c", "6"},
+		{"a: Float = b = 1; b", "1"},
+		{`a: Float = b = "x"`, "Cannot assign Str to b of type Float"},
+		{"const a = b = 1; b = 2", "Cannot assign to constant b"},
+	}
+
+	for _, tt := range tests {
+		res := testEval(tt.input)
+		testingutils.Assert(t, res != nil, "%s: no result", tt.input)
+		testingutils.Equals(t, tt.expected, res.String(), tt.input)
+	}
+}
+
+func TestFailedDestructuring(t *testing.T) {
+	for _, input := range []string{"a, b = zz, 2", "a, b = 2, zz", "a, b = [2, zz]", "a, b = 2, 1 / true"} {
+		ev := New()
+		ev.Eval("a = 1; b = 1")
+		res := ev.Eval(input)
+		testingutils.Assert(t, isError(res), "%s: expected an error, got %v", input, res)
+		testingutils.Equals(t, "[1, 1]", ev.Eval("[a, b]").String(), input)
+	}
+}
+
+func TestEcho(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"x = 2", "2"},
+		{"x = 2; x += 1", "3"},
+		{"a, b = 1, 2", "[1, 2]"},
+		{"a = b = 4", "4"},
+		{"x = 1; x + 1", "2"},
+	}
+
+	for _, tt := range tests {
+		ev := New()
+		ev.SetEcho(true)
+		res := ev.Eval(tt.input)
+		testingutils.Assert(t, res != nil, "%s: no result", tt.input)
+		testingutils.Equals(t, tt.expected, res.String(), tt.input)
+	}
+
+	ev := New()
+	testingutils.Assert(t, ev.Eval("x = 2") == nil, "assignments are not echoed by default")
+	ev.SetEcho(true)
+	testingutils.Assert(t, ev.Eval(`import "math"`) == nil, "imports are not echoed")
+}
@@ -0,0 +1,101 @@
+package evaluator
+
+import (
+	"gocalc/object"
+)
+
+func isList(obj object.Object) bool {
+	return obj.Type() == object.LIST
+}
+
+// evalInfixExpressionList applies operator element by element. A scalar, or a
+// list of a single element, is repeated to the length of the other operand
+func evalInfixExpressionList(operator string, left, right object.Object) object.Object {
+	l, lok := left.(*object.List)
+	r, rok := right.(*object.List)
+
+	n := 0
+	switch {
+	case lok && rok:
+		n = len(l.Values)
+		if len(l.Values) != len(r.Values) && len(l.Values) != 1 && len(r.Values) != 1 {
+			return newError(object.BROADCAST_ERROR, len(l.Values), len(r.Values))
+		}
+		if len(l.Values) == 1 {
+			n = len(r.Values)
+		}
+	case lok:
+		n = len(l.Values)
+	default:
+		n = len(r.Values)
+	}
+
+	values := make([]object.Object, n)
+	for i := range values {
+		res := evalInfixExpression(operator, broadcastElement(left, i), broadcastElement(right, i))
+		if isError(res) {
+			return res
+		}
+		values[i] = res
+	}
+	return newList(values)
+}
+
+func broadcastElement(obj object.Object, i int) object.Object {
+	list, ok := obj.(*object.List)
+	if !ok {
+		return obj
+	}
+	if len(list.Values) == 1 {
+		return list.Values[0]
+	}
+	return list.Values[i]
+}
+
+func evalPrefixExpressionList(operator string, right object.Object) object.Object {
+	list := right.(*object.List)
+	values := make([]object.Object, len(list.Values))
+	for i, v := range list.Values {
+		res := evalPrefixExpression(operator, v)
+		if isError(res) {
+			return res
+		}
+		values[i] = res
+	}
+	return newList(values)
+}
+
+// mapElements calls fn on every element of a list, vector or matrix, keeping
+// vectors and matrices when every result is a number
+func mapElements(obj object.Object, fn func(object.Object) object.Object) object.Object {
+	switch obj := obj.(type) {
+	case *object.List:
+		values := make([]object.Object, len(obj.Values))
+		for i, v := range obj.Values {
+			res := mapElements(v, fn)
+			if isError(res) {
+				return res
+			}
+			values[i] = res
+		}
+		return newList(values)
+
+	case *object.Vector:
+		list, _ := asList(obj)
+		res := mapElements(list, fn)
+		if v, ok := floatsOf(res); ok {
+			return &object.Vector{Values: v}
+		}
+		return res
+
+	case *object.Matrix:
+		rows := make([]object.Object, obj.Value.Rows)
+		for i := range rows {
+			row, _ := asList(&object.Vector{Values: obj.Value.Row(i)})
+			rows[i] = row
+		}
+		return mapElements(&object.List{Values: rows}, fn)
+	}
+
+	return fn(obj)
+}
@@ -0,0 +1,43 @@
+package evaluator
+
+import (
+	"gocalc/testing_utils"
+	"testing"
+)
+
+func TestBroadcasting(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"[1, 2, 3] * 2", "[2, 4, 6]"},
+		{"2 - [1, 2, 3]", "[1, 0, -1]"},
+		{"[1, 2, 3] + [10, 20, 30]", "[11, 22, 33]"},
+		{"[1] + [1, 2, 3]", "[2, 3, 4]"},
+		{"[2, 4] ^ 2 / [2]", "[2, 8]"},
+		{"[1, 2, 3] > 2", "[False, False, True]"},
+		{"[1, 2] == [1, 3]", "[True, False]"},
+		{"[true, false] && true", "[True, False]"},
+		{"[true, false] || [false, false]", "[True, False]"},
+		{"-[1, 2]", "[-1, -2]"},
+		{"![true, false]", "[False, True]"},
+		{"[1, [2, 3]] * 2", "[2, [4, 6]]"},
+		{"[[1, 2], [3]] + 1", "[[2, 3], [4]]"},
+		{"typeof([1, 2] * 2)", "List"},
+		{"[] * 2", "[]"},
+		{"sqrt([1, 4, 9])", "[1, 2, 3]"},
+		{"sqrt([[1, 4], [9, 16]])", "[[1, 2], [3, 4]]"},
+		{"import \"linalg\"; typeof(sqrt(linalg.vector([1, 4])))", "Vector"},
+		{"cos([])", "[]"},
+		{"[1, 2] + [1, 2, 3]", "Cannot broadcast lists of length 2 and 3"},
+		{"[1, true] * 2", "Unknown operator Bool * Float"},
+		{"[1, 2] / 0", "Cannot divide by zero (1 / 0)"},
+		{"-[true]", "Unknown operator -Bool"},
+	}
+
+	for _, tt := range tests {
+		res := testEval(tt.input)
+		testingutils.Assert(t, res != nil, "%s: no result", tt.input)
+		testingutils.Equals(t, tt.expected, res.String(), tt.input)
+	}
+}
@@ -0,0 +1,134 @@
+package evaluator
+
+import (
+	"gocalc/ast"
+	"gocalc/object"
+	"gocalc/types"
+	"path/filepath"
+	"strings"
+)
+
+// SetTypeCheck enables or disables checking the types of programs before they
+// are evaluated. Programs with type errors are not evaluated
+func (ev *Evaluator) SetTypeCheck(check bool) { ev.typeCheck = check }
+
+func (ev *Evaluator) TypeCheck() bool { return ev.typeCheck }
+
+// Check infers the type of input without evaluating it, see types.Checker
+func (ev *Evaluator) Check(input string) (types.Type, *object.Error) {
+	program, err := ev.parse(input)
+	if err != nil {
+		return nil, err
+	}
+	return ev.check(program)
+}
+
+func (ev *Evaluator) check(program *ast.Program) (types.Type, *object.Error) {
+	bindings := ev.global.Bindings()
+	scope := make(map[string]types.Type, len(bindings))
+	for name, obj := range bindings {
+		scope[name] = staticType(obj)
+	}
+
+	c := types.NewChecker(scope)
+	for name := range bindings {
+		if ev.global.IsConst(name) {
+			c.Constant(name)
+		}
+		if typ, ok := ev.global.Declared(name); ok {
+			c.Declare(name, staticType(typ).(*types.TypeOf).Of)
+		}
+	}
+	c.Symbolic = ev.symbolic
+	c.Import = ev.importType
+	t, errs := c.Check(program)
+	if len(errs) > 0 {
+		return nil, newErrorKind(object.ERR_TYPE, object.TYPE_ERROR, strings.Join(errs, "\n\t\t"))
+	}
+	return t, nil
+}
+
+// staticType returns the type the checker gives to obj
+func staticType(obj object.Object) types.Type {
+	switch obj := obj.(type) {
+	case nil:
+		return types.Any
+	case *NativeFunction:
+		f := &types.Function{Name: obj.Name, Result: obj.Result, Elementwise: obj.Elementwise, Quoted: obj.Quoted}
+		if sig := obj.Signature; sig != nil {
+			f.Params = make([]types.Type, len(sig.Params))
+			for i, p := range sig.Params {
+				f.Params[i] = types.FromKind(p)
+			}
+			f.Variadic, f.Optional = sig.Variadic, sig.Optional
+		}
+		return f
+	case *object.Type:
+		if obj.Record != nil {
+			return &types.TypeOf{Of: recordType(obj.Record)}
+		}
+		return &types.TypeOf{Of: types.FromKind(obj.Value)}
+	case *object.Record:
+		return recordType(obj.Of)
+	case *object.List:
+		var elem types.Type = types.Any
+		for i, v := range obj.Values {
+			if i == 0 {
+				elem = staticType(v)
+			} else {
+				elem = types.Join(elem, staticType(v))
+			}
+		}
+		return &types.List{Elem: elem}
+	case *object.Module:
+		return moduleType(obj.Name, obj.Members)
+	}
+	return types.FromKind(obj.Type())
+}
+
+func recordType(rt *object.RecordType) *types.Record {
+	return &types.Record{Name: rt.Name, Fields: rt.Fields}
+}
+
+func moduleType(name string, members map[string]object.Object) *types.Module {
+	m := &types.Module{Name: name, Members: make(map[string]types.Type, len(members))}
+	for member, obj := range members {
+		m.Members[member] = staticType(obj)
+	}
+	return m
+}
+
+// importType returns the type of an imported module without loading it. The
+// members of files are only known once they have been imported
+func (ev *Evaluator) importType(path string) (types.Type, error) {
+	if filepath.Ext(path) != ModuleExt {
+		lib, ok := library(path)
+		if !ok || !ev.modules.libraries[path] {
+			return nil, newError(object.UNKNOWN_MODULE_ERROR, path)
+		}
+		return moduleType(path, lib.Members), nil
+	}
+
+	name := (&ast.ImportStatement{Path: path}).Name()
+	if file, ok := ev.findModule(path); ok {
+		if mod, ok := ev.modules.get(file); ok {
+			return moduleType(name, mod.Members), nil
+		}
+	}
+	return &types.Module{Name: name}, nil
+}
+
+// annotationType returns the type named by an annotation, a builtin type or a
+// record type
+func (ev *Evaluator) annotationType(annotation string) (*object.Type, object.Object) {
+	t, ok := ev.global.Get(annotation)
+	typ, isType := t.(*object.Type)
+	if !ok || !isType {
+		kind, ok := object.ParseType(annotation)
+		if !ok {
+			return nil, newError(object.UNKNOWN_TYPE_ERROR, annotation)
+		}
+		typ = &object.Type{Value: kind}
+	}
+	return typ, nil
+}
@@ -0,0 +1,120 @@
+package evaluator
+
+import (
+	"gocalc/object"
+	"gocalc/testing_utils"
+	"testing"
+)
+
+func TestAnnotations(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"x: Float = 1; x", "1"},
+		{`s: Str = "a"; s`, "a"},
+		{"l: List = [1, 2]; l", "[1, 2]"},
+		{"a: Any = true; a", "True"},
+		{"type Point = {x, y}; p: Point = Point(1, 2); p.y", "2"},
+		{`x: Float = "a"`, "Cannot assign Str to x of type Float"},
+		{"type P = {x}; type Q = {x}; p: P = Q(1)", "Cannot assign Q to p of type P"},
+		{"x: Number = 1", "Unknown type Number"},
+		{"x: Float = nope", "Identifier not found nope"},
+		{`x: Float = 1; x = "a"`, "Cannot assign Str to x of type Float"},
+		{`x: Float = 1; x = 2; x = "a"`, "Cannot assign Str to x of type Float"},
+		{`x: Float = 1; x: Str = "a"; x`, "a"},
+		{"import \"time\"; t: Time = time.now(); t -= time.now()", "Cannot assign Duration to t of type Time"},
+		{`x: Float = 1; a = 0; a, x = 2, "b"`, "Cannot assign Str to x of type Float"},
+		{`x: Float = 1; x, y = ["b", 2]`, "Cannot assign Str to x of type Float"},
+	}
+
+	for _, tt := range tests {
+		res := testEval(tt.input)
+		testingutils.Assert(t, res != nil, "%s: no result", tt.input)
+		testingutils.Equals(t, tt.expected, res.String(), tt.input)
+	}
+
+	// declared types outlive the program declaring them
+	ev := New()
+	ev.Eval("x: Float = 1; a = 0")
+	for _, input := range []string{`x = "a"`, `x += "a"`, `a, x = 2, "b"`} {
+		res := ev.Eval(input)
+		testingutils.Assert(t, isError(res), "%s: expected an error, got %v", input, res)
+	}
+	testingutils.Equals(t, "[0, 1]", ev.Eval("[a, x]").String(), "[a, x]")
+	_, err := ev.Check(`x = "a"`)
+	testingutils.Assert(t, err != nil, "the checker must know the declared type of x")
+	ev.SetTypeCheck(true)
+	testingutils.Equals(t, object.ERR_TYPE, ev.Eval(`x = "a"`).(*object.Error).Kind, "with :check on")
+}
+
+func TestTypeCheck(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"1 + 2", "Float"},
+		{"[1, 2] * 2", "List[Float]"},
+		{`["a", 1]`, "List"},
+		{"sin", "(Any) -> Float"},
+		{"sin([1, 2])", "List[Float]"},
+		{"typeof(1)", "Any"},
+		{`import "strings"; strings.upper("a")`, "Str"},
+		{"type Point = {x, y}; Point(1, 2)", "Point"},
+		{"import \"time\"; time.now() + 1d", "Time"},
+		{"x: Float = 1; x", "Float"},
+		{"Float == Bool", "Bool"},
+		{`float("1") + int(2)`, "Float"},
+		{"is(1, Float)", "Bool"},
+		{"list(Float)", "List"},
+		{"x = 1; x += 2", "Float"},
+		{"a, b = 1, 2", "List[Float]"},
+		{"a, b = [1, 2]; b", "Float"},
+		{"a = b = true; b", "Bool"},
+	}
+
+	for _, tt := range tests {
+		typ, err := New().Check(tt.input)
+		testingutils.Assert(t, err == nil, "%s: %s", tt.input, err)
+		testingutils.Equals(t, tt.expected, typ.String(), tt.input)
+	}
+
+	errors := []struct {
+		input    string
+		expected string
+	}{
+		{"true + 1", "Unknown operator Bool + Float"},
+		{"x = 1; x(2)", "Cannot call value of type Float"},
+		{`x: Float = "a"`, "Cannot assign Str to x of type Float"},
+		{`x: Float = 1; x = "a"`, "Cannot assign Str to x of type Float"},
+		{"nope + 1", "Identifier not found nope"},
+		{"is(1, 2)", "Argument 2 of is must be of type Type, got Float"},
+		{"str(1) + 1", "Unknown operator Str + Float"},
+		{`x = "a"; x -= 1`, "Unknown operator Str - Float"},
+		{"a, b = 1", "Cannot unpack value of type Float"},
+		{`x: Float = 1; x += "a"`, "Unknown operator Float + Str"},
+		{`import "strings"; strings.upper(1)`, "Argument 1 of upper must be of type Str, got Float"},
+		{"type Point = {x, y}; Point(1).x", "Point expects arguments (x, y), got 1"},
+		{`import "strings"; strings.nope`, "module strings has no member nope"},
+		{"1 + true; 2 - false", "Unknown operator Float + Bool\n\t\tUnknown operator Float - Bool"},
+	}
+
+	for _, tt := range errors {
+		_, err := New().Check(tt.input)
+		testingutils.Assert(t, err != nil, "%s: no error", tt.input)
+		testingutils.Equals(t, object.ERR_TYPE, err.Kind, tt.input)
+		testingutils.Equals(t, "Type error: \n\t\t"+tt.expected, err.Message, tt.input)
+	}
+
+	ev := New()
+	ev.SetTypeCheck(true)
+	res := ev.Eval("x = 1; y = x + true")
+	testingutils.Assert(t, isError(res), "checked program was evaluated")
+	_, defined := ev.global.Get("x")
+	testingutils.Assert(t, !defined, "program with type errors was evaluated")
+
+	ev.SetSymbolic(true)
+	typ, err := ev.Check("a + 1")
+	testingutils.Assert(t, err == nil, "symbolic: %s", err)
+	testingutils.Equals(t, "Expr", typ.String(), "symbolic identifiers")
+}
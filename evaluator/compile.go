@@ -0,0 +1,201 @@
+package evaluator
+
+import (
+	"context"
+	"fmt"
+	"gocalc/ast"
+	"gocalc/compiler"
+	"gocalc/environment"
+	"gocalc/object"
+	"gocalc/optimizer"
+)
+
+// Compiled is a parsed and validated program that can be run many times
+// with different bindings without parsing it again
+type Compiled struct {
+	ev        *Evaluator
+	program   *ast.Program
+	optimized *ast.Program
+	inlined   map[string]object.Object
+	bytecode  *compiler.Bytecode
+
+	// FreeVars lists, in order of appearance, the identifiers the program
+	// reads before assigning them that are not known to the evaluator
+	FreeVars []string
+}
+
+// Compile parses input, checks that every called function is known and optimises it
+func (ev *Evaluator) Compile(input string) (*Compiled, error) {
+	program, parseErr := ev.parse(input)
+	if parseErr != nil {
+		return nil, parseErr
+	}
+
+	c := &Compiled{ev: ev, program: program}
+	if err := c.resolve(); err != nil {
+		return nil, err
+	}
+
+	c.optimize()
+
+	if ev.engine == VM {
+		bc, err := compiler.Compile(c.optimized)
+		if err != nil {
+			return nil, err
+		}
+		c.bytecode = bc
+	}
+	return c, nil
+}
+
+func (c *Compiled) resolve() error {
+	assigned := map[string]bool{}
+	seen := map[string]bool{}
+	var err error
+
+	var visit func(ast.Node) bool
+	visit = func(node ast.Node) bool {
+		switch n := node.(type) {
+		case *ast.AssignmentStatement:
+			ast.Inspect(n.Value, visit)
+			for _, name := range n.Names() {
+				assigned[name.Value] = true
+			}
+			return false
+		case *ast.CompoundAssignmentStatement:
+			ast.Inspect(n.Infix(), visit)
+			assigned[n.Name.Value] = true
+			return false
+		case *ast.DestructuringStatement:
+			for _, v := range n.Values {
+				ast.Inspect(v, visit)
+			}
+			for _, name := range n.Names {
+				assigned[name.Value] = true
+			}
+			return false
+		case *ast.ImportStatement:
+			assigned[n.Name()] = true
+		case *ast.TypeStatement:
+			assigned[n.Name.Value] = true
+			return false
+		case *ast.QuoteExpression:
+			return false
+		case *ast.MemberExpression:
+			ast.Inspect(n.Object, visit)
+			return false
+		case *ast.CallExpression:
+			id, ok := n.Function.(*ast.Identifier)
+			if !ok {
+				ast.Inspect(n.Function, visit)
+				for _, a := range n.Arguments {
+					ast.Inspect(a, visit)
+				}
+				return false
+			}
+			name := id.Value
+			fn, ok := c.ev.global.Get(name)
+			if !ok && !assigned[name] && !c.ev.symbolic && err == nil {
+				err = newError(object.IDENTIFIER_NOT_FOUND_ERROR, name)
+			}
+			// arguments of quoted natives are symbolic rather than free variables
+			if nf, ok := fn.(*NativeFunction); ok && nf.Quoted {
+				return false
+			}
+			for _, a := range n.Arguments {
+				ast.Inspect(a, visit)
+			}
+			return false
+		case *ast.Identifier:
+			if _, ok := c.ev.global.Get(n.Value); !ok && !assigned[n.Value] && !seen[n.Value] {
+				seen[n.Value] = true
+				c.FreeVars = append(c.FreeVars, n.Value)
+			}
+		}
+		return true
+	}
+
+	ast.Inspect(c.program, visit)
+	return err
+}
+
+// optimize folds the program inlining the float constants of the base environment
+func (c *Compiled) optimize() {
+	constants := map[string]float64{}
+	quoted := []string{}
+	for name, obj := range c.ev.global.Bindings() {
+		if nf, ok := obj.(*NativeFunction); ok && nf.Quoted {
+			quoted = append(quoted, name)
+		}
+	}
+	for name, obj := range c.ev.base.Bindings() {
+		f, ok := obj.(*object.Float)
+		if visible, _ := c.ev.global.Get(name); ok && visible == obj {
+			constants[name] = f.Value
+		}
+	}
+
+	o := optimizer.New(constants)
+	o.Keep(quoted...)
+	c.optimized = o.Program(c.program)
+	c.inlined = map[string]object.Object{}
+	for name := range constants {
+		if o.Inlined(name) {
+			c.inlined[name], _ = c.ev.global.Get(name)
+		}
+	}
+}
+
+func (c *Compiled) String() string { return c.program.String() }
+
+// Optimized returns the program that is actually run
+func (c *Compiled) Optimized() *ast.Program { return c.optimized }
+
+// Run evaluates the program with the given bindings and converts the result to a Go value.
+// Assignments made by the program are only visible to that run
+func (c *Compiled) Run(bindings map[string]interface{}) (interface{}, error) {
+	return c.RunContext(context.Background(), bindings)
+}
+
+func (c *Compiled) RunContext(ctx context.Context, bindings map[string]interface{}) (interface{}, error) {
+	env := environment.NewEnclosed(c.ev.global)
+	for name, value := range bindings {
+		obj, err := object.FromGo(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		env.Set(name, obj)
+	}
+
+	res := c.run(ctx, env)
+	if err, ok := res.(*object.Error); ok {
+		return nil, err
+	}
+	return object.ToGo(res)
+}
+
+// Eval is like Run for bindings that are already objects
+func (c *Compiled) Eval(bindings map[string]object.Object) object.Object {
+	env := environment.NewEnclosed(c.ev.global)
+	for name, obj := range bindings {
+		env.Set(name, obj)
+	}
+	return c.run(context.Background(), env)
+}
+
+func (c *Compiled) run(ctx context.Context, env *environment.Environment) object.Object {
+	run := c.ev.fork(ctx)
+	run.global = env
+
+	// fall back to the original program if an inlined constant has been shadowed since
+	for name, obj := range c.inlined {
+		if visible, _ := env.Get(name); visible != obj {
+			return run.run(c.program)
+		}
+	}
+
+	if c.bytecode != nil {
+		return run.runBytecode(c.bytecode)
+	}
+	return run.Program(c.optimized)
+}
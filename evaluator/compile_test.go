@@ -0,0 +1,155 @@
+package evaluator
+
+import (
+	"fmt"
+	"gocalc/object"
+	"gocalc/testing_utils"
+	"testing"
+)
+
+func TestCompile(t *testing.T) {
+	tests := []struct {
+		input    string
+		freeVars []string
+	}{
+		{"price * (1 + tax)", []string{"price", "tax"}},
+		{"2 * pi * r", []string{"r"}},
+		{"y = x * 2; y + x + sin(z)", []string{"x", "z"}},
+		{"[a, b, a]", []string{"a", "b"}},
+		{"1 + 2", nil},
+	}
+
+	ev := New()
+	for _, tt := range tests {
+		c, err := ev.Compile(tt.input)
+		testingutils.Assert(t, err == nil, "%s: unexpected error %v", tt.input, err)
+		testingutils.Equals(t, tt.freeVars, c.FreeVars, tt.input)
+	}
+
+	_, err := ev.Compile("1 +")
+	errObj, ok := err.(*object.Error)
+	testingutils.Assert(t, ok, "expected syntax error, got %T", err)
+	testingutils.Equals(t, object.ERR_SYNTAX, errObj.Kind, "errObj.Kind")
+
+	_, err = ev.Compile("nope(1)")
+	testingutils.Assert(t, err != nil, "expected error calling an unknown function")
+}
+
+func TestCompiledRun(t *testing.T) {
+	ev := New()
+	c, err := ev.Compile("total = price * (1 + tax); total")
+	testingutils.Assert(t, err == nil, "unexpected error %v", err)
+
+	tests := []struct {
+		price, tax, expected float64
+	}{
+		{100, 0.5, 150},
+		{10, 0, 10},
+		{0, 2, 0},
+	}
+	for _, tt := range tests {
+		res, err := c.Run(map[string]interface{}{"price": tt.price, "tax": tt.tax})
+		testingutils.Assert(t, err == nil, "unexpected error %v", err)
+		testingutils.Equals(t, tt.expected, res, "c.Run")
+	}
+
+	_, err = ev.GetVar("total")
+	testingutils.Assert(t, err != nil, "assignments of a run must not leak into the evaluator")
+
+	_, err = c.Run(map[string]interface{}{"price": 1})
+	testingutils.Assert(t, err != nil, "expected error for a missing binding")
+
+	testFloatObject(t, c.Eval(map[string]object.Object{"price": newFloat(2), "tax": newFloat(1)}), 4)
+}
+
+const benchmarkFormula = "base * (1 + rate) ^ years - fee * years"
+
+func BenchmarkEval(b *testing.B) {
+	ev := New()
+	for i := 0; i < b.N; i++ {
+		ev.SetVar("base", 1000.0)
+		ev.SetVar("rate", 0.05)
+		ev.SetVar("years", float64(i%30))
+		ev.SetVar("fee", 2.5)
+		if res := ev.Eval(benchmarkFormula); isError(res) {
+			b.Fatal(res)
+		}
+	}
+}
+
+func BenchmarkCompiledRun(b *testing.B) {
+	c, err := New().Compile(benchmarkFormula)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		bindings := map[string]interface{}{"base": 1000.0, "rate": 0.05, "years": float64(i % 30), "fee": 2.5}
+		if _, err := c.Run(bindings); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkCompiledEval(b *testing.B) {
+	c, err := New().Compile(benchmarkFormula)
+	if err != nil {
+		b.Fatal(err)
+	}
+	bindings := map[string]object.Object{"base": newFloat(1000), "rate": newFloat(0.05), "fee": newFloat(2.5)}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		bindings["years"] = newFloat(float64(i % 30))
+		if res := c.Eval(bindings); isError(res) {
+			b.Fatal(res)
+		}
+	}
+}
+
+func TestOptimizedEquivalence(t *testing.T) {
+	inputs := []string{
+		"2 * pi * x",
+		"x * 1 + 0 * x - (x - 0) / 1",
+		"--x + -(-(x ^ 1))",
+		"(1 + 2) * x ^ (4 / 2) - e",
+		"x > 2 * 1 && !(x == phi)",
+		"[x * 1, 2 + 2, sqrt(x + 0)]",
+		"y = x + 0; y * (3 - 2) + pi",
+		"x / (1 - 1)",
+		"sin(pi / 2 * x) + cos(0 * x)",
+	}
+
+	for _, input := range inputs {
+		for _, x := range []float64{-2, 0, 0.5, 3} {
+			ev := New()
+			ev.SetVar("x", x)
+			expected := ev.Eval(input)
+
+			c, err := ev.Compile(input)
+			testingutils.Assert(t, err == nil, "%s: unexpected error %v", input, err)
+			actual := c.Eval(nil)
+			testingutils.Equals(t, expected.String(), actual.String(), c.Optimized().String())
+		}
+	}
+
+	// identities must not hide the errors of operators on other types
+	for _, input := range []string{"x * 1", "1 * x", "x + 0", "x - 0", "0 - x", "x / 1", "x ^ 1", "--x", "!!x", "!!1"} {
+		for _, x := range []interface{}{true, "a", []float64{1, 2}} {
+			ev := New()
+			ev.SetVar("x", x)
+			expected := ev.Eval(input)
+
+			c, err := ev.Compile(input)
+			testingutils.Assert(t, err == nil, "%s: unexpected error %v", input, err)
+			actual := c.Eval(nil)
+			testingutils.Equals(t, expected.String(), actual.String(), fmt.Sprintf("%s with x = %v", input, x))
+		}
+	}
+
+	ev := New()
+	c, _ := ev.Compile("!!x * 1")
+	testingutils.Equals(t, ev.Eval("!!true * 1").String(), c.Eval(map[string]object.Object{"x": newBool(true)}).String(), "Bool binding")
+	c, _ = ev.Compile("2 * pi")
+	testingutils.Equals(t, "6.283185307179586", c.Optimized().String(), "c.Optimized()")
+	testFloatObject(t, c.Eval(map[string]object.Object{"pi": newFloat(3)}), 6)
+}
@@ -0,0 +1,119 @@
+package evaluator
+
+import (
+	"fmt"
+	"gocalc/object"
+	"gocalc/testing_utils"
+	"sync"
+	"testing"
+)
+
+const goroutines = 64
+
+func TestConcurrentEval(t *testing.T) {
+	ev := New()
+	var wg sync.WaitGroup
+	results := make([]object.Object, goroutines)
+
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				ev.Eval(fmt.Sprintf("x%d = %d; y = x%d * 2", i, i, i))
+				results[i] = ev.Eval(fmt.Sprintf("x%d * 2 + len([1, 2])", i))
+				ev.Eval("ans")
+				ev.Eval("inspect()")
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	for i, res := range results {
+		testFloatObject(t, res, float64(i*2+2))
+	}
+}
+
+func TestConcurrentSessions(t *testing.T) {
+	ev := New()
+	var wg sync.WaitGroup
+	errs := make(chan error, goroutines)
+
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			session := ev.Session()
+			name := fmt.Sprintf("twice%d", i)
+			session.Register(name, nil, func(ev *Evaluator, args ...object.Object) object.Object {
+				return newFloat(args[0].(*object.Float).Value * 2)
+			})
+			for j := 0; j < 50; j++ {
+				if err := session.SetVar("x", float64(i)); err != nil {
+					errs <- err
+					return
+				}
+				res, err := session.Evaluate(fmt.Sprintf("x = %s(x) + sqrt(4); x", name))
+				if err != nil {
+					errs <- err
+					return
+				}
+				if res != float64(i*2+2) {
+					errs <- fmt.Errorf("session %d: expected %d, got %v", i, i*2+2, res)
+					return
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Error(err)
+	}
+
+	_, err := ev.GetVar("x")
+	testingutils.Assert(t, err != nil, "session variables leaked into the parent evaluator")
+	_, err = ev.Evaluate("twice0(1)")
+	testingutils.Assert(t, err != nil, "natives registered in a session leaked into the parent evaluator")
+	_, err = ev.Session().Evaluate("twice0(1)")
+	testingutils.Assert(t, err != nil, "natives registered in a session leaked into another session")
+}
+
+func TestSessionRegistrations(t *testing.T) {
+	ev := New()
+	ev.Register("triple", nil, func(ev *Evaluator, args ...object.Object) object.Object {
+		return newFloat(args[0].(*object.Float).Value * 3)
+	})
+	session := ev.Session()
+	res, err := session.Evaluate("triple(2)")
+	testingutils.Assert(t, err == nil, "sessions must see the natives of their parent, got %v", err)
+	testingutils.Equals(t, 6.0, res, "triple(2)")
+
+	session.Register("triple", nil, func(ev *Evaluator, args ...object.Object) object.Object { return newFloat(0) })
+	res, _ = ev.Evaluate("triple(2)")
+	testingutils.Equals(t, 6.0, res, "the parent keeps its own native")
+}
+
+func TestConcurrentLibraries(t *testing.T) {
+	var wg sync.WaitGroup
+	results := make([]object.Object, goroutines)
+
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			name := fmt.Sprintf("extra%d", i)
+			RegisterLibrary(&Library{Name: name, Namespaced: true, Members: map[string]object.Object{"one": newFloat(1)}})
+			Libraries()
+			if ev, err := NewWithLibraries("core", name); err == nil {
+				results[i] = ev.Eval(fmt.Sprintf("import %q; %s.one", name, name))
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	for _, res := range results {
+		testFloatObject(t, res, 1)
+	}
+}
@@ -0,0 +1,92 @@
+package evaluator
+
+import (
+	"fmt"
+	"gocalc/ast"
+	"gocalc/object"
+)
+
+// SHADOW_WARNING is reported when an assignment hides a builtin
+const SHADOW_WARNING = "%s shadows a builtin, restore(%s) brings it back"
+
+// OnWarning sets the function called with the warnings of evaluations, like
+// assignments shadowing a builtin. It must be set before ev is shared
+func (ev *Evaluator) OnWarning(fn func(msg string)) { ev.warn = fn }
+
+func (ev *Evaluator) warnf(format string, v ...interface{}) {
+	if ev.warn != nil {
+		ev.warn(fmt.Sprintf(format, v...))
+	}
+}
+
+// assign binds name to val in the session environment. Constants cannot be
+// assigned again, variables declared with a type only take values of that type,
+// and builtins are shadowed rather than overwritten. decl is the assignment
+// statement declaring the variable, nil for plain assignments: its annotation
+// replaces the declared type, and its doc comment is recorded once the variable
+// is bound
+func (ev *Evaluator) assign(name string, val object.Object, decl *ast.AssignmentStatement) object.Object {
+	typ, err := ev.assignable(name, val, decl)
+	if err != nil {
+		return err
+	}
+	if builtin, ok := ev.natives.Get(name); ok {
+		if visible, _ := ev.global.Get(name); visible == builtin {
+			ev.warnf(SHADOW_WARNING, name, name)
+		}
+	}
+
+	if decl != nil && decl.Const {
+		ev.global.SetConst(name, val)
+	} else {
+		ev.global.Set(name, val)
+	}
+	if typ != nil {
+		ev.global.Declare(name, typ)
+	}
+	if decl != nil && decl.Doc != "" {
+		ev.global.SetDoc(name, decl.Doc)
+	}
+	return nil
+}
+
+// assignable checks that val can be assigned to name, returning the type the
+// variable is declared with, if any
+func (ev *Evaluator) assignable(name string, val object.Object, decl *ast.AssignmentStatement) (*object.Type, object.Object) {
+	if ev.global.IsConst(name) {
+		return nil, newError(object.CONST_ASSIGNMENT_ERROR, name)
+	}
+
+	typ, _ := ev.global.Declared(name)
+	if decl != nil && decl.Annotation != nil {
+		var err object.Object
+		if typ, err = ev.annotationType(decl.Annotation.Value); err != nil {
+			return nil, err
+		}
+	}
+	if typ != nil && !typ.Matches(val) {
+		return nil, newError(object.ANNOTATION_ERROR, typeOf(val), name, typ)
+	}
+	return typ, nil
+}
+
+// nativeRestore removes the variable shadowing a builtin and returns the builtin
+func nativeRestore(ev *Evaluator, args ...object.Object) object.Object {
+	if len(args) != 1 {
+		return newError(object.WRONG_ARGUMENTS_ERROR, "restore", "(Ident)", len(args))
+	}
+	name, ok := variableArg(args[0])
+	if !ok {
+		return newError("restore: the argument must be an identifier, got %s", args[0])
+	}
+
+	builtin, ok := ev.natives.Get(name)
+	if !ok {
+		return newError(object.NOT_BUILTIN_ERROR, name)
+	}
+	if ev.global.IsConst(name) {
+		return newError(object.CONST_ASSIGNMENT_ERROR, name)
+	}
+	ev.global.Delete(name)
+	return builtin
+}
@@ -0,0 +1,56 @@
+package evaluator
+
+import (
+	"gocalc/testing_utils"
+	"testing"
+)
+
+func TestConstants(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"const x = 2; x * 3", "6"},
+		{"const x: Float = 2; x", "2"},
+		{"const x = 2; x = 3", "Cannot assign to constant x"},
+		{"const x = 2; const x = 3", "Cannot assign to constant x"},
+		{`const m = 1; import "math"; m = 2`, "Cannot assign to constant m"},
+		{"const P = 1; type P = {x}", "Cannot assign to constant P"},
+		{"const x: Str = 2", "Cannot assign Float to x of type Str"},
+		{"pi = 3; pi", "3"},
+		{"sin = 4; sin + 1", "5"},
+		{"sin = 4; restore(sin); sin(0)", "0"},
+		{"pi = 3; restore(pi)", "3.141592653589793"},
+		{"restore(pi); pi", "3.141592653589793"},
+		{"x = 1; restore(x)", "x is not a builtin"},
+		{"const pi = 3; restore(pi)", "Cannot assign to constant pi"},
+		{"restore(1)", "restore: the argument must be an identifier, got 1"},
+	}
+
+	for _, tt := range tests {
+		res := testEval(tt.input)
+		testingutils.Assert(t, res != nil, "%s: no result", tt.input)
+		testingutils.Equals(t, tt.expected, res.String(), tt.input)
+	}
+
+	ev := New()
+	var warnings []string
+	ev.OnWarning(func(msg string) { warnings = append(warnings, msg) })
+	ev.Eval("pi = 3; pi = 4; x = 1; sin = 2")
+	testingutils.Equals(t, []string{
+		"pi shadows a builtin, restore(pi) brings it back",
+		"sin shadows a builtin, restore(sin) brings it back",
+	}, warnings, "shadowing warnings")
+
+	warnings = nil
+	ev.Eval(`rate = 0.05; pv = 1; mean = 2; day = 3; format = "%d"; import "finance"; finance.pv(rate, 1, 0, 100)`)
+	testingutils.Equals(t, []string(nil), warnings, "names of modules do not shadow builtins")
+
+	session := ev.Session()
+	testingutils.Equals(t, "0", session.Eval("sin(0)").String(), "builtins of other sessions")
+
+	ev.Eval("const c = 1")
+	_, err := ev.Check("c = 2")
+	testingutils.Assert(t, err != nil, "checker allowed assigning a constant")
+	testingutils.Equals(t, "Type error: \n\t\tCannot assign to constant c", err.Message, "checking constants")
+}
@@ -0,0 +1,24 @@
+package evaluator
+
+import "gocalc/object"
+
+// nativeHelp describes a variable by its type and the doc comment of its assignment
+func nativeHelp(ev *Evaluator, args ...object.Object) object.Object {
+	if len(args) != 1 {
+		return newError(object.WRONG_ARGUMENTS_ERROR, "help", "(Ident)", len(args))
+	}
+	name, ok := variableArg(args[0])
+	if !ok {
+		return newError("help: the argument must be an identifier, got %s", args[0])
+	}
+
+	val, ok := ev.global.Get(name)
+	if !ok {
+		return newError(object.IDENTIFIER_NOT_FOUND_ERROR, name)
+	}
+	res := name + ": " + staticType(val).String()
+	if doc := ev.global.Doc(name); doc != "" {
+		res += "\n" + doc
+	}
+	return object.NewString(res)
+}
@@ -0,0 +1,43 @@
+package evaluator
+
+import (
+	"gocalc/testing_utils"
+	"testing"
+)
+
+func TestDocsOfFailedAssignments(t *testing.T) {
+	ev := New()
+	ev.Eval("// speed of light\nconst c = 3e8")
+	res := ev.Eval("// hijacked\nc = 2")
+	testingutils.Assert(t, isError(res), "expected an error, got %v", res)
+	res = ev.Eval("// failed\nd = nope")
+	testingutils.Assert(t, isError(res), "expected an error, got %v", res)
+	ev.Eval("d = 1")
+
+	testingutils.Equals(t, "c: Float\nspeed of light", ev.Eval("help(c)").String(), "help(c)")
+	testingutils.Equals(t, "d: Float", ev.Eval("help(d)").String(), "help(d)")
+}
+
+func TestHelp(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"# The yearly rate\nr = 0.05; help(r)", "r: Float\nThe yearly rate"},
+		{"/* Tax\n   on sales */ const tax = 0.2; help(tax)", "tax: Float\nTax\non sales"},
+		{"/**\n * Tax\n * on sales\n */\nconst tax = 0.2; help(tax)", "tax: Float\nTax\non sales"},
+		{"/** Tax */ const tax = 0.2; help(tax)", "tax: Float\nTax"},
+		{"x = 1 # not a doc\ny = [1, 2]; help(y)", "y: List[Float]"},
+		{"# Kept\nx = 1; x = 2; help(x)", "x: Float\nKept"},
+		{"help(sqrt)", "sqrt: (Any) -> Float"},
+		{"help(nope)", "Identifier not found nope"},
+		{"help(1 + 2)", "help: the argument must be an identifier, got (1 + 2)"},
+		{"x = 2 // twice\n/* x squared */ x ^ 2", "4"},
+	}
+
+	for _, tt := range tests {
+		res := testEval(tt.input)
+		testingutils.Assert(t, res != nil, "%s: no result", tt.input)
+		testingutils.Equals(t, tt.expected, res.String(), tt.input)
+	}
+}
@@ -1,14 +1,19 @@
 package evaluator
 
 import (
+	"context"
 	"fmt"
 	"gocalc/ast"
+	"gocalc/compiler"
 	"gocalc/environment"
 	"gocalc/lexer"
+	"gocalc/numeric"
 	"gocalc/object"
 	"gocalc/parser"
+	"gocalc/types"
 	"math"
 	"strings"
+	"time"
 )
 
 var (
@@ -16,34 +21,47 @@ var (
 	ANS  = "ans"
 )
 
+// Evaluator evaluates programs against a base environment holding the
+// natives and constants, and a session environment enclosed by it where
+// assignments and ans are stored.
+//
+// An Evaluator is safe for concurrent use: every call to Eval runs with its
+// own evaluation state and the environments are synchronised. Calls sharing
+// an Evaluator also share its variables, use Session to get an isolated set
+// of variables over the same base environment. The base environment is never
+// written, Register only writes the natives of ev, and limits and numeric
+// options must be set before the Evaluator is shared
 type Evaluator struct {
-	global *environment.Environment
-	lexer  *lexer.Lexer
-	parser *parser.Parser
-}
-
-// TODO: Libraries
-var nativelib = map[string]object.Object{
-	"typeof":  newNativeFunction(nativeTypeof, "typeof"),
-	"typeofS": newNativeFunction(nativeTypeofS, "typeofS"),
-	"inspect": newNativeFunction(nativeInspect, "inspect"),
-
-	// arrays
-	"len":  newNativeFunction(arrLen, "len"),
-	"get":  newNativeFunction(arrGet, "get"),
-	"head": newNativeFunction(arrHead, "head"),
-	"tail": newNativeFunction(arrTail, "tail"),
-
-	// math
-	"sin":   newNativeFunction(math2NativeFn(math.Sin), "sin"),
-	"cos":   newNativeFunction(math2NativeFn(math.Cos), "cos"),
-	"ln":    newNativeFunction(math2NativeFn(math.Log), "ln"),
-	"log2":  newNativeFunction(math2NativeFn(math.Log2), "log2"),
-	"log10": newNativeFunction(math2NativeFn(math.Log10), "log10"),
-	"sqrt":  newNativeFunction(math2NativeFn(math.Sqrt), "sqrt"),
-	"e":     newFloat(math.E),
-	"pi":    newFloat(math.Pi),
-	"phi":   newFloat(math.Phi),
+	// base holds the natives of the libraries and is not modified once the
+	// evaluator is created, natives the ones registered in this evaluator
+	base    *environment.Environment
+	natives *environment.Environment
+	global  *environment.Environment
+	lexer   *lexer.Lexer
+	parser  *parser.Parser
+	limits  Limits
+	engine  Engine
+	// numericOpts are the options of the numeric methods, see SetNumericOptions
+	numericOpts numeric.Options
+	clock       func() time.Time
+	random      *random
+	modules     *modules
+
+	// dir is the directory of the file being evaluated, imports are resolved from it
+	dir string
+
+	// symbolic evaluation keeps unknown identifiers as symbols
+	symbolic bool
+	// typeCheck runs the type checker before evaluating programs
+	typeCheck bool
+	// warn receives the warnings of evaluations, see OnWarning
+	warn func(msg string)
+	// echo makes assignments evaluate to the assigned value, see SetEcho
+	echo bool
+	// notation parses programs in the mathematical notation, see SetNotation
+	notation bool
+
+	*evalState
 }
 
 func newNativeFunction(fn NativeFn, name string) *NativeFunction {
@@ -55,17 +73,11 @@ func newFloat(val float64) *object.Float {
 }
 
 func New() *Evaluator {
-	ev := &Evaluator{}
-	ev.global = environment.New()
-
-	for name, nf := range nativelib {
-		ev.global.Set(name, nf)
-	}
-
+	ev, _ := NewWithLibraries(DefaultLibraries...)
 	return ev
 }
 
-// TODO: Libraries, return multiple values
+// TODO: return multiple values
 func mathSin(ev *Evaluator, objs ...object.Object) object.Object {
 	num, ok := objs[0].(*object.Float)
 	if !ok {
@@ -78,8 +90,16 @@ func mathSin(ev *Evaluator, objs ...object.Object) object.Object {
 
 type mathFn func(float64) float64
 
-func math2NativeFn(fn mathFn) NativeFn {
+func newMathFunction(name string, fn mathFn) *NativeFunction {
+	sig := &Signature{Params: []object.ObjectType{object.ANY}}
+	return &NativeFunction{Function: math2NativeFn(name, fn), Name: name, Signature: sig, Elementwise: true, Result: types.Float}
+}
+
+func math2NativeFn(name string, fn mathFn) NativeFn {
 	return func(ev *Evaluator, objs ...object.Object) object.Object {
+		if isExpression(objs[0]) {
+			return symbolicCall(name, objs)
+		}
 		num, ok := objs[0].(*object.Float)
 		if !ok {
 			// TODO: handle error
@@ -119,7 +139,14 @@ func nativeTypeof(ev *Evaluator, objs ...object.Object) object.Object {
 	if len(objs) == 0 {
 		return &object.Type{Value: object.NATIVE_FUNCTION}
 	}
-	obj := objs[0]
+	return typeOf(objs[0])
+}
+
+// typeOf returns the type of obj, the declared type for records
+func typeOf(obj object.Object) *object.Type {
+	if rec, ok := obj.(*object.Record); ok {
+		return &object.Type{Value: object.RECORD, Record: rec.Of}
+	}
 	return &object.Type{Value: obj.Type()}
 }
 
@@ -127,10 +154,10 @@ func arrHead(ev *Evaluator, objs ...object.Object) object.Object {
 	if len(objs) == 0 {
 		return NULL
 	}
-	obj, ok := objs[0].(*object.List)
+	obj, ok := asList(objs[0])
 
 	if !ok {
-		return newError("Len can only be applied to lists. Got %s", obj.Type())
+		return newError("Len can only be applied to lists. Got %s", objs[0].Type())
 	}
 
 	return _arrGet(obj, 0)
@@ -140,10 +167,13 @@ func arrTail(ev *Evaluator, objs ...object.Object) object.Object {
 	if len(objs) == 0 {
 		return NULL
 	}
-	obj, ok := objs[0].(*object.List)
+	obj, ok := asList(objs[0])
 
 	if !ok {
-		return newError("Len can only be applied to lists. Got %s", obj.Type())
+		return newError("Len can only be applied to lists. Got %s", objs[0].Type())
+	}
+	if len(obj.Values) == 0 {
+		return newError("tail can only be applied to non empty lists")
 	}
 
 	return _arrGet(obj, len(obj.Values)-1)
@@ -154,10 +184,10 @@ func arrGet(ev *Evaluator, objs ...object.Object) object.Object {
 		return NULL
 	}
 
-	list, ok := objs[0].(*object.List)
+	list, ok := asList(objs[0])
 
 	if !ok {
-		return newError("get can only be applied to lists. Got %s", list.Type())
+		return newError("get can only be applied to lists. Got %s", objs[0].Type())
 	}
 
 	index, ok := objs[1].(*object.Float)
@@ -170,7 +200,7 @@ func arrGet(ev *Evaluator, objs ...object.Object) object.Object {
 }
 
 func _arrGet(list *object.List, index int) object.Object {
-	if len(list.Values) <= index {
+	if index < 0 || len(list.Values) <= index {
 		return newError("Index %d not found (len = %d)", index, len(list.Values))
 	}
 
@@ -181,10 +211,10 @@ func arrLen(ev *Evaluator, objs ...object.Object) object.Object {
 	if len(objs) == 0 {
 		return newFloat(0)
 	}
-	obj, ok := objs[0].(*object.List)
+	obj, ok := asList(objs[0])
 
 	if !ok {
-		return newError("Len can only be applied to lists. Got %s", obj.Type())
+		return newError("Len can only be applied to lists. Got %s", objs[0].Type())
 	}
 
 	return newFloat(float64(len(obj.Values)))
@@ -192,22 +222,85 @@ func arrLen(ev *Evaluator, objs ...object.Object) object.Object {
 }
 
 func (ev *Evaluator) Eval(input string) object.Object {
-	lexer := lexer.New(input)
-	parser := parser.New(lexer)
-	program := parser.ParseProgram()
+	return ev.EvalContext(context.Background(), input)
+}
 
-	if parser.HasErrors() {
-		errs := strings.Join(parser.Errors(), "\n\t\t")
-		return newError(object.SYNTAX_ERROR, errs)
+// EvalContext evaluates input, stopping with an error once ctx is done
+func (ev *Evaluator) EvalContext(ctx context.Context, input string) object.Object {
+	program, err := ev.parse(input)
+	if err != nil {
+		return err
+	}
+	if ev.typeCheck {
+		if _, err := ev.check(program); err != nil {
+			return err
+		}
 	}
 
-	res := ev.Program(program)
+	res := ev.fork(ctx).run(program)
 	if !isError(res) {
 		ev.global.Set(ANS, res)
 	}
+	if res == nil && ev.echo {
+		return ev.echoed(program)
+	}
 	return res
 }
 
+func (ev *Evaluator) parse(input string) (*ast.Program, *object.Error) {
+	var mode parser.Mode
+	if ev.notation {
+		mode |= parser.Notation
+	}
+
+	p := parser.NewWithMode(lexer.New(input), mode)
+	program := p.ParseProgram()
+
+	if p.HasErrors() {
+		errs := strings.Join(p.Errors(), "\n\t\t")
+		return nil, newErrorKind(object.ERR_SYNTAX, object.SYNTAX_ERROR, errs)
+	}
+	return program, nil
+}
+
+// fork returns an evaluator sharing the environments of ev with a fresh evaluation state
+func (ev *Evaluator) fork(ctx context.Context) *Evaluator {
+	run := ev.with(ev.global)
+	run.evalState = newEvalState(ctx)
+	return run
+}
+
+// with returns a copy of ev taking part in the same evaluation that uses env for its variables
+func (ev *Evaluator) with(env *environment.Environment) *Evaluator {
+	res := *ev
+	res.global = env
+	return &res
+}
+
+// SetNotation enables or disables the mathematical notation, see parser.Notation
+func (ev *Evaluator) SetNotation(notation bool) { ev.notation = notation }
+
+func (ev *Evaluator) Notation() bool { return ev.notation }
+
+// SetSymbolic enables or disables the symbolic mode, in which unknown identifiers
+// evaluate to symbols and operations on symbols build expressions
+func (ev *Evaluator) SetSymbolic(symbolic bool) { ev.symbolic = symbolic }
+
+func (ev *Evaluator) Symbolic() bool { return ev.symbolic }
+
+// run executes program with the engine selected for ev
+func (ev *Evaluator) run(program *ast.Program) object.Object {
+	if ev.engine == VM {
+		bc, err := compiler.Compile(program)
+		if err != nil {
+			return newError("%s", err)
+		}
+		return ev.runBytecode(bc)
+	}
+
+	return ev.Program(program)
+}
+
 func (ev *Evaluator) Program(program *ast.Program) object.Object {
 	var result object.Object
 	for _, statement := range program.Statements {
@@ -221,10 +314,17 @@ func (ev *Evaluator) Program(program *ast.Program) object.Object {
 }
 
 func (ev *Evaluator) Identifier(id *ast.Identifier) object.Object {
-	val, ok := ev.global.Get(id.Value)
+	return ev.lookup(id.Value)
+}
+
+func (ev *Evaluator) lookup(name string) object.Object {
+	val, ok := ev.global.Get(name)
 
 	if !ok {
-		return newError(object.IDENTIFIER_NOT_FOUND_ERROR, id.Value)
+		if ev.symbolic {
+			return &Expression{Node: ast.NewIdentifier(name)}
+		}
+		return newError(object.IDENTIFIER_NOT_FOUND_ERROR, name)
 	}
 
 	return val
@@ -243,14 +343,18 @@ func (ev *Evaluator) AssignmentStatement(as *ast.AssignmentStatement) object.Obj
 	if isError(val) {
 		return val
 	}
-
-	ev.global.Set(as.Name.Value, val)
-
+	// chained variables are assigned from right to left, like in the compiled program
+	names := as.Names()
+	for i := len(names) - 1; i >= 0; i-- {
+		if err := ev.assign(names[i].Value, val, as); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
 func (ev *Evaluator) ListLiteral(ll *ast.ListLiteral) object.Object {
-	return &object.List{Values: ev.evalExpressions(ll.Values)}
+	return newList(ev.evalExpressions(ll.Values))
 }
 
 func (ev *Evaluator) ExpressionStatement(es *ast.ExpressionStatement) object.Object {
@@ -284,20 +388,46 @@ func (ev *Evaluator) InfixExpression(ie *ast.InfixExpression) object.Object {
 	return evalInfixExpression(ie.Operator, l, r)
 }
 
+func (ev *Evaluator) QuoteExpression(qe *ast.QuoteExpression) object.Object {
+	return &Expression{Node: qe.Expression}
+}
+
 func (ev *Evaluator) CallExpression(ce *ast.CallExpression) object.Object {
-	val, ok := ev.global.Get(ce.Function.TokenLiteral())
+	var fn object.Object
+	if id, ok := ce.Function.(*ast.Identifier); ok {
+		fn = ev.lookup(id.Value)
+	} else {
+		fn = ev.evaluate(ce.Function)
+	}
 
-	if !ok {
-		return newError(object.IDENTIFIER_NOT_FOUND_ERROR, ce.Function.TokenLiteral())
+	if isError(fn) {
+		return fn
+	}
+
+	if nf, ok := fn.(*NativeFunction); ok && nf.Quoted {
+		return nf.call(ev, quote(ce.Arguments))
 	}
 
-	switch fn := val.(type) {
+	return ev.applyFunction(fn, ev.evalExpressions(ce.Arguments))
+}
+
+// applyFunction calls fn with already evaluated arguments. Values that are
+// not functions are returned as they are
+func (ev *Evaluator) applyFunction(fn object.Object, args []object.Object) object.Object {
+	switch fn := fn.(type) {
 	case *NativeFunction:
-		args := ev.evalExpressions(ce.Arguments)
-		return fn.Function(ev, args...)
+		return fn.call(ev, args)
+	case *object.Type:
+		if fn.Record != nil {
+			return newRecord(fn.Record, args)
+		}
+	case *Expression:
+		if id, ok := fn.Node.(*ast.Identifier); ok && ev.symbolic {
+			return symbolicCall(id.Value, args)
+		}
 	}
 
-	return val
+	return fn
 }
 
 func getError(objs []object.Object) (err object.Object, ok bool) {
@@ -323,7 +453,16 @@ func (ev *Evaluator) evalExpressions(expressions []ast.Expression) (result []obj
 }
 
 func (ev *Evaluator) evaluate(node ast.Node) object.Object {
-	return node.Accept(ev)
+	if err := ev.enter(); err != nil {
+		return err
+	}
+	defer ev.leave()
+
+	res := node.Accept(ev)
+	if _, ok := node.(*ast.Identifier); ok {
+		return res
+	}
+	return ev.track(res)
 }
 
 func isError(obj object.Object) bool {
@@ -332,6 +471,20 @@ func isError(obj object.Object) bool {
 
 func evalInfixExpression(operator string, left, right object.Object) object.Object {
 	switch {
+	case isExpression(left) || isExpression(right):
+		return evalInfixExpressionSymbolic(operator, left, right)
+	case isLinalg(left) || isLinalg(right):
+		return evalInfixExpressionLinalg(operator, left, right)
+	case isList(left) || isList(right):
+		return evalInfixExpressionList(operator, left, right)
+	case isRecord(left) || isRecord(right):
+		return evalInfixExpressionRecord(operator, left, right)
+	case isTime(left) || isTime(right):
+		return evalInfixExpressionTime(operator, left, right)
+	case isType(left) && isType(right):
+		return evalInfixExpressionType(operator, left, right)
+	case isString(left) && isString(right):
+		return evalInfixExpressionString(operator, left, right)
 	case isFloat(left) && isFloat(right):
 		return evalInfixExpressionFloat(operator, left, right)
 	case isBoolean(left) && isBoolean(right):
@@ -407,6 +560,14 @@ func evalInfixExpressionFloat(operator string, left, right object.Object) object
 
 func evalPrefixExpression(operator string, right object.Object) object.Object {
 	switch {
+	case isExpression(right):
+		return evalPrefixExpressionSymbolic(operator, right)
+	case isLinalg(right):
+		return evalPrefixExpressionLinalg(operator, right)
+	case isList(right):
+		return evalPrefixExpressionList(operator, right)
+	case isTime(right):
+		return evalPrefixExpressionTime(operator, right)
 	case isFloat(right):
 		return evalPrefixExpressionFloat(operator, right)
 	case isBoolean(right):
@@ -439,3 +600,7 @@ func evalPrefixExpressionFloat(operator string, right object.Object) object.Obje
 func newError(msg string, v ...interface{}) *object.Error {
 	return &object.Error{Message: fmt.Sprintf(msg, v...)}
 }
+
+func newErrorKind(kind object.ErrorKind, msg string, v ...interface{}) *object.Error {
+	return &object.Error{Kind: kind, Message: fmt.Sprintf(msg, v...)}
+}
@@ -0,0 +1,208 @@
+package evaluator
+
+import (
+	"gocalc/ast"
+	"gocalc/environment"
+	"gocalc/object"
+	"gocalc/symbolic"
+)
+
+// Expression is an unevaluated expression used as a value
+type Expression struct {
+	Node ast.Expression
+}
+
+func (e *Expression) Type() object.ObjectType { return object.EXPRESSION }
+func (e *Expression) TypeS() string           { return e.Type().Stringf(e.String()) }
+func (e *Expression) String() string          { return e.Node.String() }
+
+func quote(nodes []ast.Expression) []object.Object {
+	args := make([]object.Object, len(nodes))
+	for i, n := range nodes {
+		args[i] = &Expression{Node: n}
+	}
+	return args
+}
+
+// newQuotedFunction creates a native that receives its arguments unevaluated, as *Expression
+func newQuotedFunction(fn NativeFn, name string) *NativeFunction {
+	return &NativeFunction{Function: fn, Name: name, Quoted: true}
+}
+
+func isExpression(obj object.Object) bool {
+	_, ok := obj.(*Expression)
+	return ok
+}
+
+// toNode converts a value taking part in a symbolic expression into a node
+func toNode(obj object.Object) (ast.Expression, bool) {
+	switch obj := obj.(type) {
+	case *Expression:
+		return obj.Node, true
+	case *object.Float:
+		return ast.NewFloatLiteral(obj.Value), true
+	}
+	return nil, false
+}
+
+// expressionResult returns numbers as floats and anything else as an expression
+func expressionResult(node ast.Expression) object.Object {
+	if lit, ok := node.(*ast.FloatLiteral); ok {
+		return &object.Float{Value: lit.Value}
+	}
+	return &Expression{Node: node}
+}
+
+var symbolicOperators = map[string]bool{"+": true, "-": true, "*": true, "/": true, "^": true}
+
+func evalInfixExpressionSymbolic(operator string, left, right object.Object) object.Object {
+	l, lok := toNode(left)
+	r, rok := toNode(right)
+	if !lok || !rok || !symbolicOperators[operator] {
+		return newError(object.UNKNOWN_INFIX_OPERATOR_ERROR, left.Type(), operator, right.Type())
+	}
+	return &Expression{Node: ast.NewInfixExpression(operator, l, r)}
+}
+
+func evalPrefixExpressionSymbolic(operator string, right object.Object) object.Object {
+	if operator != "-" {
+		return newError(object.UNKNOWN_PREFIX_OPERATOR_ERROR, operator, right.Type())
+	}
+	return &Expression{Node: ast.NewPrefixExpression(operator, right.(*Expression).Node)}
+}
+
+// symbolicCall builds the call of a function on symbolic arguments
+func symbolicCall(name string, args []object.Object) object.Object {
+	nodes := make([]ast.Expression, len(args))
+	for i, arg := range args {
+		if isError(arg) {
+			return arg
+		}
+		node, ok := toNode(arg)
+		if !ok {
+			return newError(object.ARGUMENT_TYPE_ERROR, i+1, name, object.EXPRESSION, arg.Type())
+		}
+		nodes[i] = node
+	}
+	return &Expression{Node: ast.NewCallExpression(name, nodes...)}
+}
+
+// symbolicArg evaluates a quoted argument in symbolic mode with the given names
+// bound and returns the resulting expression
+func (ev *Evaluator) symbolicArg(arg object.Object, bindings map[string]object.Object) (ast.Expression, object.Object) {
+	res := ev.symbolicValue(arg, bindings)
+	if isError(res) {
+		return nil, res
+	}
+
+	node, ok := toNode(res)
+	if !ok {
+		return nil, newError("expected an expression, got %s", res.Type())
+	}
+	return node, nil
+}
+
+// symbolicValue evaluates a quoted argument in symbolic mode with the given
+// names bound. Expressions held by variables are evaluated once more so the
+// bindings also apply to them
+func (ev *Evaluator) symbolicValue(arg object.Object, bindings map[string]object.Object) object.Object {
+	env := environment.NewEnclosed(ev.global)
+	for name, val := range bindings {
+		env.Set(name, val)
+	}
+	sym := ev.with(env)
+	sym.symbolic = true
+
+	res := sym.evaluate(arg.(*Expression).Node)
+	if expr, ok := res.(*Expression); ok {
+		res = sym.evaluate(expr.Node)
+	}
+	return res
+}
+
+// variableArg returns the name of a quoted argument that must be an identifier
+func variableArg(arg object.Object) (string, bool) {
+	id, ok := arg.(*Expression).Node.(*ast.Identifier)
+	if !ok {
+		return "", false
+	}
+	return id.Value, true
+}
+
+func symbol(name string) *Expression { return &Expression{Node: ast.NewIdentifier(name)} }
+
+func symbolicDiff(ev *Evaluator, args ...object.Object) object.Object {
+	if len(args) < 2 || len(args) > 3 {
+		return newError(object.WRONG_ARGUMENTS_ERROR, "diff", "(Expr, Ident, Float)", len(args))
+	}
+
+	x, ok := variableArg(args[1])
+	if !ok {
+		return newError("diff: the variable must be an identifier, got %s", args[1])
+	}
+
+	order := 1
+	if len(args) == 3 {
+		n, ok := ev.evaluate(args[2].(*Expression).Node).(*object.Float)
+		if !ok || n.Value < 0 || n.Value != float64(int(n.Value)) {
+			return newError("diff: the order must be a non negative integer, got %s", args[2])
+		}
+		order = int(n.Value)
+	}
+
+	expr, err := ev.symbolicArg(args[0], map[string]object.Object{x: symbol(x)})
+	if err != nil {
+		return err
+	}
+
+	expr = symbolic.Simplify(expr)
+	for i := 0; i < order; i++ {
+		if err := ev.CountIteration(); err != nil {
+			return err
+		}
+		d, err := symbolic.Diff(expr, x)
+		if err != nil {
+			return newError("diff: %s", err)
+		}
+		expr = d
+	}
+
+	return &Expression{Node: expr}
+}
+
+// symbolicTransform makes a native applying fn to its symbolic argument
+func symbolicTransform(name string, fn func(ast.Expression) ast.Expression) NativeFn {
+	return func(ev *Evaluator, args ...object.Object) object.Object {
+		if len(args) != 1 {
+			return newError(object.WRONG_ARGUMENTS_ERROR, name, "(Expr)", len(args))
+		}
+		expr, err := ev.symbolicArg(args[0], nil)
+		if err != nil {
+			return err
+		}
+		return expressionResult(fn(expr))
+	}
+}
+
+func symbolicSubs(ev *Evaluator, args ...object.Object) object.Object {
+	if len(args) != 3 {
+		return newError(object.WRONG_ARGUMENTS_ERROR, "subs", "(Expr, Ident, Expr)", len(args))
+	}
+
+	x, ok := variableArg(args[1])
+	if !ok {
+		return newError("subs: the variable must be an identifier, got %s", args[1])
+	}
+
+	val, err := ev.symbolicArg(args[2], nil)
+	if err != nil {
+		return err
+	}
+
+	expr, err := ev.symbolicArg(args[0], map[string]object.Object{x: expressionResult(val)})
+	if err != nil {
+		return err
+	}
+
+	return expressionResult(symbolic.Simplify(expr))
+}
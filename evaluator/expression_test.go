@@ -0,0 +1,94 @@
+package evaluator
+
+import (
+	"gocalc/object"
+	"gocalc/testing_utils"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"import \"symbolic\"; symbolic.diff(x ^ 2 + 3 * x, x)", "((2 * x) + 3)"},
+		{"import \"symbolic\"; symbolic.diff(sin(x) * cos(x), x)", "((cos(x) * cos(x)) - (sin(x) * sin(x)))"},
+		{"import \"symbolic\"; symbolic.diff(x ^ 3, x, 2)", "(6 * x)"},
+		{"import \"symbolic\"; symbolic.diff(x ^ 3, x, 0)", "(x ^ 3)"},
+		{"import \"symbolic\"; e = 2; symbolic.diff(e ^ x, x)", "((2 ^ x) * ln(2))"},
+		{"import \"symbolic\"; symbolic.diff(e ^ x, x)", "(2.718281828459045 ^ x)"},
+		{"import \"symbolic\"; symbolic.diff(4 ^ x, x)", "((4 ^ x) * ln(4))"},
+		{"import \"symbolic\"; x = 10; symbolic.diff(x ^ 2, x)", "(2 * x)"},
+		{"import \"symbolic\"; f = symbolic.diff(x ^ 3, x); symbolic.diff(f, x)", "(6 * x)"},
+		{"import \"symbolic\"; typeof(symbolic.diff(x, x))", "Expr"},
+		{"import \"symbolic\"; symbolic.diff(ln(sqrt(x)), x)", "((1 / sqrt(x)) * (1 / (2 * sqrt(x))))"},
+		{"import \"symbolic\"; symbolic.diff(tan(x), x)", "diff: cannot differentiate function tan"},
+		{"import \"symbolic\"; symbolic.diff(x ^ 2, 2)", "diff: the variable must be an identifier, got 2"},
+		{"import \"symbolic\"; symbolic.diff(x)", "diff expects arguments (Expr, Ident, Float), got 1"},
+	}
+
+	for _, tt := range tests {
+		res := testEval(tt.input)
+		testingutils.Assert(t, res != nil, "%s: no result", tt.input)
+		testingutils.Equals(t, tt.expected, res.String(), tt.input)
+	}
+
+	res := testEval(`import "symbolic"; symbolic.diff(x ^ 2, x)`)
+	testingutils.Equals(t, object.EXPRESSION, res.Type(), "res.Type()")
+}
+
+func TestSymbolicAlgebra(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"'(x ^ 2 - 1)", "((x ^ 2) - 1)"},
+		{"typeof('x)", "Expr"},
+		{"f = '(x + 1); f * 2", "((x + 1) * 2)"},
+		{"f = '(x + 1); -f", "(-(x + 1))"},
+		{"f = '(x + 1); sqrt(f)", "sqrt((x + 1))"},
+		{"f = '(x + 1); f == 1", "Unknown operator Expr == Float"},
+		{"import \"symbolic\"; symbolic.expand((x + 1) ^ 2)", "(((x ^ 2) + (2 * x)) + 1)"},
+		{"import \"symbolic\"; symbolic.factor(x ^ 2 - 1)", "((x - 1) * (x + 1))"},
+		{"import \"symbolic\"; f = '(x ^ 2 - 1); symbolic.factor(f)", "((x - 1) * (x + 1))"},
+		{"import \"symbolic\"; symbolic.simplify(x + x)", "(2 * x)"},
+		{"import \"symbolic\"; symbolic.simplify(x * 1 + 0)", "x"},
+		{"import \"symbolic\"; a = 2; symbolic.simplify(a * x + x)", "(3 * x)"},
+		{"import \"symbolic\"; symbolic.subs(x ^ 2 + 1, x, 3)", "10"},
+		{"import \"symbolic\"; typeof(symbolic.subs(x ^ 2 + 1, x, 3))", "Float"},
+		{"import \"symbolic\"; symbolic.subs(x ^ 2 + y, x, 2)", "(4 + y)"},
+		{"import \"symbolic\"; f = '(x ^ 2); symbolic.subs(f, x, y + 1)", "((y + 1) ^ 2)"},
+		{"import \"symbolic\"; symbolic.subs(x, 2, 3)", "subs: the variable must be an identifier, got 2"},
+		{"import \"symbolic\"; symbolic.subs(x, x)", "subs expects arguments (Expr, Ident, Expr), got 2"},
+		{"import \"symbolic\"; symbolic.simplify(true)", "expected an expression, got Bool"},
+	}
+
+	for _, tt := range tests {
+		res := testEval(tt.input)
+		testingutils.Assert(t, res != nil, "%s: no result", tt.input)
+		testingutils.Equals(t, tt.expected, res.String(), tt.input)
+	}
+}
+
+func TestSymbolicMode(t *testing.T) {
+	ev := New()
+	res := ev.Eval("x + 1")
+	testingutils.Equals(t, object.ERROR, res.Type(), "x + 1 outside symbolic mode")
+
+	ev.SetSymbolic(true)
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"x + 1", "(x + 1)"},
+		{"2 * 3 * y", "(6 * y)"},
+		{"f(x, 2)", "f(x, 2)"},
+		{"import \"symbolic\"; e = x ^ 2; symbolic.diff(e, x)", "(2 * x)"},
+		{"import \"symbolic\"; symbolic.subs(e, x, 4)", "16"},
+	}
+
+	for _, tt := range tests {
+		res := ev.Eval(tt.input)
+		testingutils.Equals(t, tt.expected, res.String(), tt.input)
+	}
+}
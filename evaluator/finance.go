@@ -0,0 +1,152 @@
+package evaluator
+
+import (
+	"errors"
+	"fmt"
+	"gocalc/finance"
+	"gocalc/numeric"
+	"gocalc/object"
+	"gocalc/types"
+	"math"
+	"time"
+)
+
+// financeError reports the errors of the finance package prefixed by the native
+func financeError(name string, err error) object.Object {
+	if errors.Is(err, numeric.ErrNoConvergence) {
+		return newErrorKind(object.ERR_NO_CONVERGENCE, "%s: %s", name, err)
+	}
+	return newError("%s: %s", name, err)
+}
+
+// financial creates a native over numbers whose last arguments may be left out
+// and take the values of defaults
+func financial(name string, params int, defaults []float64, fn func(x []float64) (float64, error)) *NativeFunction {
+	return newFloatFunction(name, params, defaults, func(ev *Evaluator, x []float64) object.Object {
+		res, err := fn(x)
+		if err != nil {
+			return financeError(name, err)
+		}
+		return newFloat(res)
+	}).returns(types.Float)
+}
+
+// due reads the type argument of a spreadsheet function, 1 for payments at the
+// beginning of the periods and 0 for payments at the end
+func due(x float64) (bool, error) {
+	if x != 0 && x != 1 {
+		return false, fmt.Errorf("the type must be 0 or 1, got %g", x)
+	}
+	return x == 1, nil
+}
+
+// annuity creates a native over the rate, number of periods and two amounts
+// of an annuity, followed by the type of the payments
+func annuity(name string, fn func(a, b, c, d float64, due bool) (float64, error)) *NativeFunction {
+	return financial(name, 3, []float64{0, 0}, func(x []float64) (float64, error) {
+		d, err := due(x[4])
+		if err != nil {
+			return 0, err
+		}
+		return fn(x[0], x[1], x[2], x[3], d)
+	})
+}
+
+// rateNative is rate(nper, pmt, pv, [fv], [type], [guess]), solved with the
+// numeric options of the evaluator
+func rateNative() *NativeFunction {
+	return newFloatFunction("rate", 3, []float64{0, 0, 0.1}, func(ev *Evaluator, x []float64) object.Object {
+		d, err := due(x[4])
+		if err != nil {
+			return financeError("rate", err)
+		}
+		res, err := finance.Rate(x[0], x[1], x[2], x[3], d, x[5], ev.numericOptions())
+		if err != nil {
+			return financeError("rate", err)
+		}
+		return newFloat(res)
+	})
+}
+
+// cashFlows reads a list or vector of amounts
+func cashFlows(name string, pos int, arg object.Object) ([]float64, object.Object) {
+	values, ok := floatsOf(arg)
+	if !ok || len(values) == 0 {
+		return nil, newError("%s: argument %d must be a non empty list of numbers, got %s", name, pos, arg.Type())
+	}
+	return values, nil
+}
+
+func guessArg(args []object.Object, i int) float64 {
+	if i < len(args) {
+		return args[i].(*object.Float).Value
+	}
+	return 0.1
+}
+
+func nativeNPV(ev *Evaluator, args ...object.Object) object.Object {
+	values, err := cashFlows("npv", 2, args[1])
+	if err != nil {
+		return err
+	}
+	return newFloat(finance.NPV(args[0].(*object.Float).Value, values))
+}
+
+func nativeIRR(ev *Evaluator, args ...object.Object) object.Object {
+	values, err := cashFlows("irr", 1, args[0])
+	if err != nil {
+		return err
+	}
+	res, irrErr := finance.IRR(values, guessArg(args, 1), ev.numericOptions())
+	if irrErr != nil {
+		return financeError("irr", irrErr)
+	}
+	return newFloat(res)
+}
+
+func nativeXIRR(ev *Evaluator, args ...object.Object) object.Object {
+	values, err := cashFlows("xirr", 1, args[0])
+	if err != nil {
+		return err
+	}
+	list := args[1].(*object.List)
+	dates := make([]time.Time, len(list.Values))
+	for i, v := range list.Values {
+		t, ok := v.(*object.Time)
+		if !ok {
+			return newError("xirr: argument 2 must be a list of dates, got %s", v.Type())
+		}
+		dates[i] = t.Value
+	}
+
+	res, xirrErr := finance.XIRR(values, dates, guessArg(args, 2), ev.numericOptions())
+	if xirrErr != nil {
+		return financeError("xirr", xirrErr)
+	}
+	return newFloat(res)
+}
+
+// nativeAmortize returns the schedule of a loan as a list of rows
+// [period, payment, interest, principal, balance]
+func nativeAmortize(ev *Evaluator, args ...object.Object) object.Object {
+	n := args[1].(*object.Float).Value
+	if n != math.Trunc(n) {
+		return newError("amortize: the number of periods must be an integer, got %s", args[1])
+	}
+	if err := ev.checkSize(n); err != nil {
+		return err
+	}
+
+	schedule, err := finance.Amortize(args[0].(*object.Float).Value, int(n), args[2].(*object.Float).Value)
+	if err != nil {
+		return financeError("amortize", err)
+	}
+
+	rows := make([]object.Object, len(schedule))
+	for i, p := range schedule {
+		rows[i] = &object.List{Values: []object.Object{
+			newFloat(float64(p.Period)), newFloat(p.Payment), newFloat(p.Interest), newFloat(p.Principal), newFloat(p.Balance),
+		}}
+	}
+	return &object.List{Values: rows}
+}
@@ -0,0 +1,53 @@
+package evaluator
+
+import (
+	"gocalc/testing_utils"
+	"math"
+	"testing"
+)
+
+func TestFinance(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"import \"finance\"; round2(finance.pmt(0.08/12, 10, 10000))", "-1037.03"},
+		{"import \"finance\"; round2(finance.pmt(0.08/12, 10, 10000, 0, 1))", "-1030.16"},
+		{"import \"finance\"; round2(finance.fv(0.06/12, 10, -200, -500, 1))", "2581.4"},
+		{"import \"finance\"; round2(finance.pv(0.08/12, 12*20, 500))", "-59777.15"},
+		{"import \"finance\"; round2(finance.nper(0.01, -100, -1000, 10000))", "60.08"},
+		{"import \"finance\"; round2(finance.rate(48, -200, 8000) * 1200)", "9.24"},
+		{"import \"finance\"; round2(finance.npv(0.1, [-10000, 3000, 4200, 6800]))", "1188.44"},
+		{"import \"finance\"; round2(finance.irr([-70000, 12000, 15000, 18000, 21000, 26000]) * 100)", "8.66"},
+		{`import "finance"; import "time"; round2(finance.xirr([-10000, 2750, 4250, 3250, 2750], [time.date("2008-01-01"), time.date("2008-03-01"), time.date("2008-10-30"), time.date("2009-02-15"), time.date("2009-04-01")]) * 100)`, "37.34"},
+		{"import \"finance\"; round2(finance.compound(1000, 0.05, 10, 12))", "1647.01"},
+		{"import \"finance\"; round2(finance.compound(1000, 0.05, 10))", "1628.89"},
+		{"import \"finance\"; finance.sln(30000, 7500, 10)", "2250"},
+		{"import \"finance\"; round2(finance.syd(30000, 7500, 10, 1))", "4090.91"},
+		{"import \"finance\"; finance.ddb(2400, 300, 10, 1)", "480"},
+		{"import \"finance\"; round2(finance.ddb(2400, 300, 10, 2, 1.5))", "306"},
+		{"import \"finance\"; round2(finance.db(1000000, 100000, 6, 1, 7))", "186083.33"},
+		{"import \"finance\"; len(finance.amortize(0.01, 12, 10000))", "12"},
+		{"import \"finance\"; round2(head(finance.amortize(0.01, 12, 10000)))", "[1, 888.49, 100, 788.49, 9211.51]"},
+		{"import \"finance\"; finance.pmt(0.1, 10)", "pmt expects arguments (Float, Float, Float, [Float], [Float]), got 2"},
+		{"import \"finance\"; finance.pmt(0.1, 10, 1000, 0, 2)", "pmt: the type must be 0 or 1, got 2"},
+		{"import \"finance\"; finance.pmt(0.1, 0, 1000)", "pmt: invalid argument: the number of periods must not be zero"},
+		{"import \"finance\"; finance.irr([1, 2])", "irr: invalid argument: the cash flows must have a positive and a negative value"},
+		{"import \"finance\"; finance.irr(1)", "irr: argument 1 must be a non empty list of numbers, got Float"},
+		{`import "finance"; finance.xirr([-1, 2], [1, 2])`, "xirr: argument 2 must be a list of dates, got Float"},
+		{"import \"finance\"; finance.syd(30000, 7500, 10, 11)", "syd: invalid argument: the period must be between 1 and 10, got 11"},
+		{"import \"finance\"; finance.amortize(0.01, 1.5, 100)", "amortize: the number of periods must be an integer, got 1.5"},
+		{"import \"finance\"; finance.amortize(0.1, 1e15, 100)", "Cannot allocate 1e+15 elements (> 67108864)"},
+		{"import \"finance\"; finance.amortize(0.1, 2000000, 100)", "amortize: invalid argument: the number of periods must be at most 1048576, got 2000000"},
+		{"import \"finance\"; finance.rate(10, -100, 1000)", "0"},
+	}
+
+	for _, tt := range tests {
+		ev := New()
+		// the reference values are rounded to cents
+		ev.base.Set("round2", newMathFunction("round2", func(x float64) float64 { return math.Round(x*100) / 100 }))
+		res := ev.Eval(tt.input)
+		testingutils.Assert(t, res != nil, "%s: no result", tt.input)
+		testingutils.Equals(t, tt.expected, res.String(), tt.input)
+	}
+}
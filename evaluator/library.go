@@ -0,0 +1,243 @@
+package evaluator
+
+import (
+	"gocalc/calendar"
+	"gocalc/finance"
+	"gocalc/numeric"
+	"gocalc/object"
+	"gocalc/stats"
+	"gocalc/symbolic"
+	"gocalc/types"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Library is a named set of natives and constants that can be loaded into an evaluator.
+// Every library can be imported as a module of the same name, the members of
+// namespaced libraries are only reachable that way
+type Library struct {
+	Name       string
+	Members    map[string]object.Object
+	Namespaced bool
+}
+
+// libraries are guarded by librariesMu since RegisterLibrary may be called
+// while evaluators are running
+var (
+	librariesMu sync.RWMutex
+	libraries   = map[string]*Library{}
+)
+
+var DefaultLibraries = []string{"core", "lists", "math", "symbolic", "numeric", "linalg", "time", "finance", "random", "stats", "strings"}
+
+func init() {
+	core := map[string]object.Object{
+		"typeof":  newNativeFunction(nativeTypeof, "typeof"),
+		"typeofS": newNativeFunction(nativeTypeofS, "typeofS"),
+		"inspect": newNativeFunction(nativeInspect, "inspect"),
+		"restore": newQuotedFunction(nativeRestore, "restore"),
+		"help":    newQuotedFunction(nativeHelp, "help").returns(types.Str),
+		"float":   newTypedFunction(nativeFloat, "float", object.ANY).returns(types.Float),
+		"int":     newTypedFunction(nativeInt, "int", object.ANY).returns(types.Float),
+		"str":     newTypedFunction(nativeStr, "str", object.ANY).returns(types.Str),
+		"bool":    newTypedFunction(nativeBool, "bool", object.ANY).returns(types.Bool),
+		"list":    newTypedFunction(nativeList, "list", object.ANY).returns(types.FromKind(object.LIST)),
+		"is":      newTypedFunction(nativeIs, "is", object.ANY, object.TYPE).returns(types.Bool),
+	}
+	for _, t := range typeConstants {
+		core[t.String()] = &object.Type{Value: t}
+	}
+	RegisterLibrary(&Library{Name: "core", Members: core})
+
+	RegisterLibrary(&Library{Name: "lists", Members: map[string]object.Object{
+		"len":  newNativeFunction(arrLen, "len"),
+		"get":  newNativeFunction(arrGet, "get"),
+		"head": newNativeFunction(arrHead, "head"),
+		"tail": newNativeFunction(arrTail, "tail"),
+	}})
+
+	RegisterLibrary(&Library{Name: "math", Members: map[string]object.Object{
+		"sin":   newMathFunction("sin", math.Sin),
+		"cos":   newMathFunction("cos", math.Cos),
+		"ln":    newMathFunction("ln", math.Log),
+		"log2":  newMathFunction("log2", math.Log2),
+		"log10": newMathFunction("log10", math.Log10),
+		"sqrt":  newMathFunction("sqrt", math.Sqrt),
+		"abs":   newMathFunction("abs", math.Abs),
+		"factorial": newMathFunction("factorial", func(x float64) float64 {
+			return math.Gamma(x + 1)
+		}),
+		"e":   newFloat(math.E),
+		"pi":  newFloat(math.Pi),
+		"phi": newFloat(math.Phi),
+	}})
+
+	RegisterLibrary(&Library{Name: "symbolic", Namespaced: true, Members: map[string]object.Object{
+		"diff":     newQuotedFunction(symbolicDiff, "diff"),
+		"simplify": newQuotedFunction(symbolicTransform("simplify", symbolic.SimplifyAlgebraic), "simplify"),
+		"expand":   newQuotedFunction(symbolicTransform("expand", symbolic.Expand), "expand"),
+		"factor":   newQuotedFunction(symbolicTransform("factor", symbolic.Factor), "factor"),
+		"subs":     newQuotedFunction(symbolicSubs, "subs"),
+	}})
+
+	RegisterLibrary(&Library{Name: "numeric", Namespaced: true, Members: map[string]object.Object{
+		"solve":  newQuotedFunction(solver("solve"), "solve"),
+		"newton": newQuotedFunction(solver("newton"), "newton"),
+		"brent":  newQuotedFunction(solver("brent"), "brent"),
+		"bisect": newQuotedFunction(solver("bisect"), "bisect"),
+		"roots":  newQuotedFunction(polynomialRoots, "roots"),
+		"re":     complexPart("re", func(z complex128) float64 { return real(z) }),
+		"im":     complexPart("im", func(z complex128) float64 { return imag(z) }),
+
+		"integrate": newQuotedFunction(integrator("integrate", numeric.GaussKronrod), "integrate"),
+		"simpson":   newQuotedFunction(integrator("simpson", numeric.Simpson), "simpson"),
+		"deriv":     newQuotedFunction(numericDeriv, "deriv"),
+		"sum":       newQuotedFunction(numericSum, "sum"),
+	}})
+
+	RegisterLibrary(&Library{Name: "linalg", Namespaced: true, Members: map[string]object.Object{
+		"vector":      newTypedFunction(nativeVector, "vector", object.ANY),
+		"identity":    newTypedFunction(nativeIdentity, "identity", object.FLOAT),
+		"transpose":   newTypedFunction(nativeTranspose, "transpose", object.MATRIX),
+		"det":         newTypedFunction(nativeDet, "det", object.MATRIX),
+		"inv":         newTypedFunction(nativeInv, "inv", object.MATRIX),
+		"eigenvalues": newTypedFunction(nativeEigenvalues, "eigenvalues", object.MATRIX),
+		"dot":         newTypedFunction(nativeDot, "dot", object.ANY, object.ANY),
+		"cross":       newTypedFunction(nativeCross, "cross", object.ANY, object.ANY),
+		"norm":        newTypedFunction(nativeNorm, "norm", object.ANY),
+	}})
+
+	RegisterLibrary(&Library{Name: "time", Namespaced: true, Members: map[string]object.Object{
+		"date":            newNativeFunction(nativeDate, "date").returns(types.Time),
+		"now":             newTypedFunction(nativeNow, "now").returns(types.Time),
+		"duration":        newTypedFunction(nativeDuration, "duration", object.STRING),
+		"weekday":         newTypedFunction(nativeWeekday, "weekday", object.TIME),
+		"isweekend":       newTypedFunction(nativeIsWeekend, "isweekend", object.TIME),
+		"businessdays":    newTypedFunction(nativeBusinessDays, "businessdays", object.TIME, object.TIME),
+		"addbusinessdays": newTypedFunction(nativeAddBusinessDays, "addbusinessdays", object.TIME, object.FLOAT),
+		"tz":              newTypedFunction(nativeTimezone, "tz", object.TIME, object.STRING),
+		"format":          newTypedFunction(nativeFormat, "format", object.TIME, object.STRING),
+
+		"year":   timePart("year", time.Time.Year),
+		"month":  timePart("month", func(t time.Time) int { return int(t.Month()) }),
+		"day":    timePart("day", time.Time.Day),
+		"hour":   timePart("hour", time.Time.Hour),
+		"minute": timePart("minute", time.Time.Minute),
+		"second": timePart("second", time.Time.Second),
+
+		"days":    durationIn("days", calendar.Day),
+		"hours":   durationIn("hours", time.Hour),
+		"minutes": durationIn("minutes", time.Minute),
+		"seconds": durationIn("seconds", time.Second),
+	}})
+
+	RegisterLibrary(&Library{Name: "finance", Namespaced: true, Members: map[string]object.Object{
+		"pv": annuity("pv", func(rate, nper, pmt, fv float64, due bool) (float64, error) {
+			return finance.PV(rate, nper, pmt, fv, due), nil
+		}),
+		"fv": annuity("fv", func(rate, nper, pmt, pv float64, due bool) (float64, error) {
+			return finance.FV(rate, nper, pmt, pv, due), nil
+		}),
+		"pmt":  annuity("pmt", finance.PMT),
+		"nper": annuity("nper", finance.NPER),
+
+		"compound": financial("compound", 3, []float64{1}, func(x []float64) (float64, error) {
+			return finance.Compound(x[0], x[1], x[2], x[3])
+		}),
+		"sln": financial("sln", 3, nil, func(x []float64) (float64, error) {
+			return finance.SLN(x[0], x[1], x[2])
+		}),
+		"syd": financial("syd", 4, nil, func(x []float64) (float64, error) {
+			return finance.SYD(x[0], x[1], x[2], x[3])
+		}),
+		"ddb": financial("ddb", 4, []float64{2}, func(x []float64) (float64, error) {
+			return finance.DDB(x[0], x[1], x[2], x[3], x[4])
+		}),
+		"db": financial("db", 4, []float64{12}, func(x []float64) (float64, error) {
+			return finance.DB(x[0], x[1], x[2], x[3], x[4])
+		}),
+
+		"rate":     rateNative(),
+		"npv":      newTypedFunction(nativeNPV, "npv", object.FLOAT, object.ANY),
+		"irr":      optional(newTypedFunction(nativeIRR, "irr", object.ANY, object.FLOAT), 1),
+		"xirr":     optional(newTypedFunction(nativeXIRR, "xirr", object.ANY, object.LIST, object.FLOAT), 1),
+		"amortize": newTypedFunction(nativeAmortize, "amortize", object.FLOAT, object.FLOAT, object.FLOAT),
+	}})
+
+	RegisterLibrary(&Library{Name: "random", Namespaced: true, Members: map[string]object.Object{
+		"rand":    newTypedFunction(nativeRand, "rand"),
+		"randint": newTypedFunction(nativeRandint, "randint", object.FLOAT, object.FLOAT),
+		"choice":  newTypedFunction(nativeChoice, "choice", object.ANY),
+		"shuffle": newTypedFunction(nativeShuffle, "shuffle", object.ANY),
+		"seed":    newTypedFunction(nativeSeed, "seed", object.FLOAT),
+		"sample":  optional(newTypedFunction(nativeSample, "sample", object.DISTRIBUTION, object.FLOAT), 1),
+	}})
+
+	RegisterLibrary(&Library{Name: "stats", Namespaced: true, Members: map[string]object.Object{
+		"normal": distribution("normal", 0, []float64{0, 1}, func(x []float64) (stats.Distribution, error) {
+			return stats.NewNormal(x[0], x[1])
+		}),
+		"uniform": distribution("uniform", 0, []float64{0, 1}, func(x []float64) (stats.Distribution, error) {
+			return stats.NewUniform(x[0], x[1])
+		}),
+		"exponential": distribution("exponential", 0, []float64{1}, func(x []float64) (stats.Distribution, error) {
+			return stats.NewExponential(x[0])
+		}),
+		"binomial": distribution("binomial", 2, nil, func(x []float64) (stats.Distribution, error) {
+			return stats.NewBinomial(x[0], x[1])
+		}),
+		"poisson": distribution("poisson", 1, nil, func(x []float64) (stats.Distribution, error) {
+			return stats.NewPoisson(x[0])
+		}),
+
+		"pdf":      distributionFunction("pdf", func(d stats.Distribution, x float64) object.Object { return newFloat(d.PDF(x)) }),
+		"cdf":      distributionFunction("cdf", func(d stats.Distribution, x float64) object.Object { return newFloat(d.CDF(x)) }),
+		"quantile": distributionFunction("quantile", quantile),
+		"mean":     newTypedFunction(nativeMean, "mean", object.ANY),
+		"variance": newTypedFunction(nativeVariance, "variance", object.ANY),
+	}})
+
+	RegisterLibrary(&Library{Name: "strings", Namespaced: true, Members: map[string]object.Object{
+		"len":        newTypedFunction(nativeStringLen, "len", object.STRING),
+		"upper":      stringFunction("upper", strings.ToUpper),
+		"lower":      stringFunction("lower", strings.ToLower),
+		"trim":       stringFunction("trim", strings.TrimSpace),
+		"split":      newTypedFunction(nativeSplit, "split", object.STRING, object.STRING),
+		"join":       newTypedFunction(nativeJoin, "join", object.LIST, object.STRING),
+		"contains":   stringPredicate("contains", strings.Contains),
+		"startswith": stringPredicate("startswith", strings.HasPrefix),
+		"endswith":   stringPredicate("endswith", strings.HasSuffix),
+		"replace":    newTypedFunction(nativeReplace, "replace", object.STRING, object.STRING, object.STRING),
+	}})
+}
+
+// RegisterLibrary makes a library available to NewWithLibraries.
+// A library registered with an existing name replaces the previous one
+func RegisterLibrary(lib *Library) {
+	librariesMu.Lock()
+	defer librariesMu.Unlock()
+	libraries[lib.Name] = lib
+}
+
+// library returns the registered library called name
+func library(name string) (*Library, bool) {
+	librariesMu.RLock()
+	defer librariesMu.RUnlock()
+	lib, ok := libraries[name]
+	return lib, ok
+}
+
+// Libraries returns the names of every registered library
+func Libraries() []string {
+	librariesMu.RLock()
+	defer librariesMu.RUnlock()
+	names := make([]string, 0, len(libraries))
+	for name := range libraries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,118 @@
+package evaluator
+
+import (
+	"context"
+	"gocalc/object"
+)
+
+// Limits caps the resources a single evaluation may use. A zero value means no limit
+type Limits struct {
+	MaxDepth       int // nesting of evaluated nodes and native callbacks
+	MaxListLen     int
+	MaxStringLen   int
+	MaxIterations  int // loop iterations reported by natives through CountIteration
+	MaxAllocations int // objects produced while evaluating
+}
+
+// MAX_ALLOCATION_SIZE bounds the elements a native allocates at once, whatever
+// the limits, so that a huge size is an error rather than a failed allocation
+const MAX_ALLOCATION_SIZE = 1 << 26
+
+// checkSize reports an error when a native would allocate n elements over
+// MAX_ALLOCATION_SIZE or MaxListLen
+func (ev *Evaluator) checkSize(n float64) *object.Error {
+	if n > MAX_ALLOCATION_SIZE {
+		return newErrorKind(object.ERR_LIST_LIMIT, object.ALLOCATION_SIZE_ERROR, n, MAX_ALLOCATION_SIZE)
+	}
+	if max := ev.limits.MaxListLen; max > 0 && int(n) > max {
+		return newErrorKind(object.ERR_LIST_LIMIT, object.LIST_LIMIT_ERROR, int(n), max)
+	}
+	return nil
+}
+
+// evalState is the state of a running evaluation, shared by the evaluators taking part in it
+type evalState struct {
+	ctx         context.Context
+	depth       int
+	iterations  int
+	allocations int
+	importing   []string // files being imported, to detect cycles
+}
+
+func newEvalState(ctx context.Context) *evalState { return &evalState{ctx: ctx} }
+
+func (ev *Evaluator) SetLimits(limits Limits) { ev.limits = limits }
+
+func (ev *Evaluator) Limits() Limits { return ev.limits }
+
+// CountIteration must be called by natives on every iteration of their loops
+// so that long running computations honour the limits and cancellation
+func (ev *Evaluator) CountIteration() *object.Error {
+	if err := ev.checkContext(); err != nil {
+		return err
+	}
+
+	ev.iterations++
+	if max := ev.limits.MaxIterations; max > 0 && ev.iterations > max {
+		return newErrorKind(object.ERR_ITERATION_LIMIT, object.ITERATION_LIMIT_ERROR, max)
+	}
+	return nil
+}
+
+func (ev *Evaluator) checkContext() *object.Error {
+	if ev.ctx == nil {
+		ev.ctx = context.Background()
+	}
+	if err := ev.ctx.Err(); err != nil {
+		return newErrorKind(object.ERR_CANCELLED, object.CANCELLED_ERROR, err)
+	}
+	return nil
+}
+
+func (ev *Evaluator) enter() *object.Error {
+	if err := ev.checkContext(); err != nil {
+		return err
+	}
+
+	ev.depth++
+	if max := ev.limits.MaxDepth; max > 0 && ev.depth > max {
+		ev.depth--
+		return newErrorKind(object.ERR_DEPTH_LIMIT, object.DEPTH_LIMIT_ERROR, max)
+	}
+	return nil
+}
+
+func (ev *Evaluator) leave() { ev.depth-- }
+
+// track accounts for a newly produced object and checks its size
+func (ev *Evaluator) track(obj object.Object) object.Object {
+	if obj == nil || isError(obj) {
+		return obj
+	}
+
+	ev.allocations++
+	if max := ev.limits.MaxAllocations; max > 0 && ev.allocations > max {
+		return newErrorKind(object.ERR_ALLOCATION_LIMIT, object.ALLOCATION_LIMIT_ERROR, max)
+	}
+
+	switch obj := obj.(type) {
+	case *object.List:
+		if max := ev.limits.MaxListLen; max > 0 && len(obj.Values) > max {
+			return newErrorKind(object.ERR_LIST_LIMIT, object.LIST_LIMIT_ERROR, len(obj.Values), max)
+		}
+	case *object.Vector:
+		if max := ev.limits.MaxListLen; max > 0 && len(obj.Values) > max {
+			return newErrorKind(object.ERR_LIST_LIMIT, object.LIST_LIMIT_ERROR, len(obj.Values), max)
+		}
+	case *object.Matrix:
+		if max := ev.limits.MaxListLen; max > 0 && len(obj.Value.Data) > max {
+			return newErrorKind(object.ERR_LIST_LIMIT, object.LIST_LIMIT_ERROR, len(obj.Value.Data), max)
+		}
+	case *object.String:
+		if max := ev.limits.MaxStringLen; max > 0 && len(obj.Value) > max {
+			return newErrorKind(object.ERR_STRING_LIMIT, object.STRING_LIMIT_ERROR, len(obj.Value), max)
+		}
+	}
+
+	return obj
+}
@@ -0,0 +1,82 @@
+package evaluator
+
+import (
+	"context"
+	"gocalc/object"
+	"gocalc/testing_utils"
+	"testing"
+	"time"
+)
+
+func TestLimits(t *testing.T) {
+	tests := []struct {
+		input  string
+		limits Limits
+		kind   object.ErrorKind
+	}{
+		{"-(-(-(-(-(-(-(-1)))))))", Limits{MaxDepth: 5}, object.ERR_DEPTH_LIMIT},
+		{"[1, 2, 3]", Limits{MaxListLen: 2}, object.ERR_LIST_LIMIT},
+		{"import \"linalg\"; linalg.identity(3)", Limits{MaxListLen: 8}, object.ERR_LIST_LIMIT},
+		{"import \"finance\"; finance.amortize(0.1, 12, 100)", Limits{MaxListLen: 10}, object.ERR_LIST_LIMIT},
+		{"typeofS(12345)", Limits{MaxStringLen: 5}, object.ERR_STRING_LIMIT},
+		{"1 + 2 + 3 + 4", Limits{MaxAllocations: 3}, object.ERR_ALLOCATION_LIMIT},
+		{"spin(10)", Limits{MaxIterations: 5}, object.ERR_ITERATION_LIMIT},
+	}
+
+	for _, tt := range tests {
+		ev := New()
+		ev.Register("spin", nil, nativeSpin)
+		ev.SetLimits(tt.limits)
+		res := ev.Eval(tt.input)
+		errObj, ok := res.(*object.Error)
+		testingutils.Assert(t, ok, "%s: no error object returned, got %T (%v)", tt.input, res, res)
+		testingutils.Equals(t, tt.kind, errObj.Kind, tt.input)
+	}
+
+	ev := New()
+	ev.Register("spin", nil, nativeSpin)
+	ev.SetLimits(Limits{MaxDepth: 50, MaxListLen: 3, MaxIterations: 10, MaxAllocations: 20})
+	testFloatObject(t, ev.Eval("len([1, 2, 3])"), 3)
+	testFloatObject(t, ev.Eval("spin(10)"), 10)
+
+	// both engines count the 4 literals, the 3 sums and the result
+	ev.SetLimits(Limits{MaxAllocations: 8})
+	testFloatObject(t, ev.Eval("1 + 2 + 3 + 4"), 10)
+	ev.SetLimits(Limits{MaxAllocations: 7})
+	res := ev.Eval("1 + 2 + 3 + 4")
+	errObj, ok := res.(*object.Error)
+	testingutils.Assert(t, ok && errObj.Kind == object.ERR_ALLOCATION_LIMIT, "expected an allocation error, got %v", res)
+}
+
+func TestCancellation(t *testing.T) {
+	ev := New()
+	ev.Register("spin", nil, nativeSpin)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	res := ev.EvalContext(ctx, "1 + 1")
+	errObj, ok := res.(*object.Error)
+	testingutils.Assert(t, ok, "no error object returned, got %T", res)
+	testingutils.Equals(t, object.ERR_CANCELLED, errObj.Kind, "errObj.Kind")
+
+	ctx, cancel = context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err := ev.EvaluateContext(ctx, "spin(-1)")
+	errObj, ok = err.(*object.Error)
+	testingutils.Assert(t, ok, "no error object returned, got %T", err)
+	testingutils.Equals(t, object.ERR_CANCELLED, errObj.Kind, "errObj.Kind")
+
+	testFloatObject(t, ev.Eval("1 + 1"), 2)
+}
+
+// nativeSpin loops n times, forever when n is negative
+func nativeSpin(ev *Evaluator, args ...object.Object) object.Object {
+	n := args[0].(*object.Float).Value
+	i := 0.0
+	for ; n < 0 || i < n; i++ {
+		if err := ev.CountIteration(); err != nil {
+			return err
+		}
+	}
+	return newFloat(i)
+}
@@ -0,0 +1,358 @@
+package evaluator
+
+import (
+	"gocalc/linalg"
+	"gocalc/object"
+	"math"
+)
+
+// newList builds the value of a list literal. Lists of rows of numbers that
+// have the same length are matrices
+func newList(values []object.Object) object.Object {
+	if m, ok := matrixOf(values); ok {
+		return m
+	}
+	return &object.List{Values: values}
+}
+
+func matrixOf(values []object.Object) (*object.Matrix, bool) {
+	if len(values) == 0 {
+		return nil, false
+	}
+	rows := make([][]float64, len(values))
+	for i, v := range values {
+		row, ok := floatsOf(v)
+		if !ok || len(row) == 0 || len(row) != len(rows[0]) && i > 0 {
+			return nil, false
+		}
+		rows[i] = row
+	}
+	m, err := linalg.FromRows(rows)
+	if err != nil {
+		return nil, false
+	}
+	return &object.Matrix{Value: m}, true
+}
+
+// floatsOf returns the elements of a vector, or of a list made only of numbers
+func floatsOf(obj object.Object) ([]float64, bool) {
+	switch obj := obj.(type) {
+	case *object.Vector:
+		return obj.Values, true
+	case *object.List:
+		values := make([]float64, len(obj.Values))
+		for i, v := range obj.Values {
+			f, ok := v.(*object.Float)
+			if !ok {
+				return nil, false
+			}
+			values[i] = f.Value
+		}
+		return values, true
+	}
+	return nil, false
+}
+
+// asList returns the rows of a matrix and the elements of a vector as a list
+func asList(obj object.Object) (*object.List, bool) {
+	switch obj := obj.(type) {
+	case *object.List:
+		return obj, true
+	case *object.Vector:
+		values := make([]object.Object, len(obj.Values))
+		for i, v := range obj.Values {
+			values[i] = newFloat(v)
+		}
+		return &object.List{Values: values}, true
+	case *object.Matrix:
+		values := make([]object.Object, obj.Value.Rows)
+		for i := range values {
+			values[i] = &object.Vector{Values: obj.Value.Row(i)}
+		}
+		return &object.List{Values: values}, true
+	}
+	return nil, false
+}
+
+func isLinalg(obj object.Object) bool {
+	t := obj.Type()
+	return t == object.MATRIX || t == object.VECTOR
+}
+
+func elementOp(operator string) (func(a, b float64) float64, bool) {
+	switch operator {
+	case "+":
+		return func(a, b float64) float64 { return a + b }, true
+	case "-":
+		return func(a, b float64) float64 { return a - b }, true
+	case "*":
+		return func(a, b float64) float64 { return a * b }, true
+	case "/":
+		return func(a, b float64) float64 { return a / b }, true
+	case "^":
+		return math.Pow, true
+	}
+	return nil, false
+}
+
+func linalgError(err error) object.Object {
+	return newError("%s", err)
+}
+
+func evalInfixExpressionLinalg(operator string, left, right object.Object) object.Object {
+	unknown := newError(object.UNKNOWN_INFIX_OPERATOR_ERROR, left.Type(), operator, right.Type())
+
+	if operator == "/" {
+		if f, ok := right.(*object.Float); ok && f.Value == 0 {
+			return object.DivideByZeroError(left, right)
+		}
+	}
+
+	lm, lok := left.(*object.Matrix)
+	rm, rok := right.(*object.Matrix)
+	switch {
+	case lok && rok:
+		return evalMatrices(operator, lm.Value, rm.Value, unknown)
+	case lok:
+		return evalMatrixOperand(operator, lm.Value, right, false, unknown)
+	case rok:
+		return evalMatrixOperand(operator, rm.Value, left, true, unknown)
+	}
+
+	fn, isOp := elementOp(operator)
+	lv, lvok := floatsOf(left)
+	rv, rvok := floatsOf(right)
+	switch {
+	case lvok && rvok:
+		if operator == "==" || operator == "!=" {
+			return newBool(equalFloats(lv, rv) == (operator == "=="))
+		}
+		if !isOp {
+			return unknown
+		}
+		if len(lv) != len(rv) {
+			return newError("%s: vectors of length %d %s %d", linalg.ErrDimension, len(lv), operator, len(rv))
+		}
+		res := make([]float64, len(lv))
+		for i := range lv {
+			res[i] = fn(lv[i], rv[i])
+		}
+		return &object.Vector{Values: res}
+	case lvok && isFloat(right) && isOp:
+		c := right.(*object.Float).Value
+		return mapVector(lv, func(v float64) float64 { return fn(v, c) })
+	case rvok && isFloat(left) && isOp:
+		c := left.(*object.Float).Value
+		return mapVector(rv, func(v float64) float64 { return fn(c, v) })
+	}
+	return unknown
+}
+
+func evalMatrices(operator string, a, b *linalg.Matrix, unknown object.Object) object.Object {
+	var res *linalg.Matrix
+	var err error
+	switch operator {
+	case "+", "-":
+		fn, _ := elementOp(operator)
+		res, err = a.Apply(operator, b, fn)
+	case "*":
+		res, err = a.Mul(b)
+	case "==":
+		return newBool(a.Equal(b))
+	case "!=":
+		return newBool(!a.Equal(b))
+	default:
+		return unknown
+	}
+	if err != nil {
+		return linalgError(err)
+	}
+	return &object.Matrix{Value: res}
+}
+
+// evalMatrixOperand applies operator to a matrix and a scalar or a vector,
+// other being the left operand when swapped is set
+func evalMatrixOperand(operator string, m *linalg.Matrix, other object.Object, swapped bool, unknown object.Object) object.Object {
+	if v, ok := floatsOf(other); ok && operator == "*" {
+		var res []float64
+		var err error
+		if swapped {
+			res, err = m.VecMul(v)
+		} else {
+			res, err = m.MulVec(v)
+		}
+		if err != nil {
+			return linalgError(err)
+		}
+		return &object.Vector{Values: res}
+	}
+
+	f, ok := other.(*object.Float)
+	if !ok {
+		return unknown
+	}
+	c := f.Value
+
+	if operator == "^" && !swapped {
+		if c != math.Trunc(c) {
+			return newError("Matrix powers must be integers, got %s", f)
+		}
+		res, err := m.Pow(int(c))
+		if err != nil {
+			return linalgError(err)
+		}
+		return &object.Matrix{Value: res}
+	}
+
+	fn, ok := elementOp(operator)
+	if !ok || operator == "^" || (swapped && operator == "/") {
+		return unknown
+	}
+	if swapped {
+		return &object.Matrix{Value: m.Map(func(v float64) float64 { return fn(c, v) })}
+	}
+	return &object.Matrix{Value: m.Map(func(v float64) float64 { return fn(v, c) })}
+}
+
+func mapVector(values []float64, fn func(float64) float64) object.Object {
+	res := make([]float64, len(values))
+	for i, v := range values {
+		res[i] = fn(v)
+	}
+	return &object.Vector{Values: res}
+}
+
+func equalFloats(a, b []float64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func evalPrefixExpressionLinalg(operator string, right object.Object) object.Object {
+	if operator != "-" {
+		return newError(object.UNKNOWN_PREFIX_OPERATOR_ERROR, operator, right.Type())
+	}
+	neg := func(v float64) float64 { return -v }
+	if m, ok := right.(*object.Matrix); ok {
+		return &object.Matrix{Value: m.Value.Map(neg)}
+	}
+	return mapVector(right.(*object.Vector).Values, neg)
+}
+
+func vectorArg(name string, i int, arg object.Object) ([]float64, object.Object) {
+	v, ok := floatsOf(arg)
+	if !ok {
+		return nil, newError(object.ARGUMENT_TYPE_ERROR, i, name, object.VECTOR, arg.Type())
+	}
+	return v, nil
+}
+
+func nativeVector(ev *Evaluator, args ...object.Object) object.Object {
+	v, err := vectorArg("vector", 1, args[0])
+	if err != nil {
+		return err
+	}
+	return &object.Vector{Values: append([]float64{}, v...)}
+}
+
+func nativeIdentity(ev *Evaluator, args ...object.Object) object.Object {
+	n := args[0].(*object.Float).Value
+	if n < 1 || n != math.Trunc(n) {
+		return newError("identity: the size must be a positive integer, got %s", args[0])
+	}
+	if err := ev.checkSize(n * n); err != nil {
+		return err
+	}
+	return &object.Matrix{Value: linalg.Identity(int(n))}
+}
+
+func nativeTranspose(ev *Evaluator, args ...object.Object) object.Object {
+	return &object.Matrix{Value: args[0].(*object.Matrix).Value.Transpose()}
+}
+
+func nativeDet(ev *Evaluator, args ...object.Object) object.Object {
+	det, err := args[0].(*object.Matrix).Value.Det()
+	if err != nil {
+		return linalgError(err)
+	}
+	return newFloat(det)
+}
+
+func nativeInv(ev *Evaluator, args ...object.Object) object.Object {
+	inv, err := args[0].(*object.Matrix).Value.Inverse()
+	if err != nil {
+		return linalgError(err)
+	}
+	return &object.Matrix{Value: inv}
+}
+
+func nativeEigenvalues(ev *Evaluator, args ...object.Object) object.Object {
+	values, err := args[0].(*object.Matrix).Value.Eigenvalues(ev.numericOptions())
+	if err != nil {
+		return numericError(err)
+	}
+	return complexList(values)
+}
+
+func nativeDot(ev *Evaluator, args ...object.Object) object.Object {
+	a, err := vectorArg("dot", 1, args[0])
+	if err != nil {
+		return err
+	}
+	b, err := vectorArg("dot", 2, args[1])
+	if err != nil {
+		return err
+	}
+	res, dotErr := linalg.Dot(a, b)
+	if dotErr != nil {
+		return linalgError(dotErr)
+	}
+	return newFloat(res)
+}
+
+func nativeCross(ev *Evaluator, args ...object.Object) object.Object {
+	a, err := vectorArg("cross", 1, args[0])
+	if err != nil {
+		return err
+	}
+	b, err := vectorArg("cross", 2, args[1])
+	if err != nil {
+		return err
+	}
+	res, crossErr := linalg.Cross(a, b)
+	if crossErr != nil {
+		return linalgError(crossErr)
+	}
+	return &object.Vector{Values: res}
+}
+
+func nativeNorm(ev *Evaluator, args ...object.Object) object.Object {
+	v, err := vectorArg("norm", 1, args[0])
+	if err != nil {
+		return err
+	}
+	sum := 0.0
+	for _, x := range v {
+		sum += x * x
+	}
+	return newFloat(math.Sqrt(sum))
+}
+
+// linearSolve solves the system A x = b
+func linearSolve(a *linalg.Matrix, arg object.Object) object.Object {
+	b, err := vectorArg("solve", 2, arg)
+	if err != nil {
+		return err
+	}
+	x, solveErr := a.Solve(b)
+	if solveErr != nil {
+		return linalgError(solveErr)
+	}
+	return &object.Vector{Values: x}
+}
@@ -0,0 +1,66 @@
+package evaluator
+
+import (
+	"gocalc/testing_utils"
+	"testing"
+)
+
+func TestLinearAlgebra(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"typeof([[1, 2], [3, 4]])", "Matrix"},
+		{"typeof([[1, 2], [3]])", "List"},
+		{"typeof([[1, true], [3, 4]])", "List"},
+		{"import \"linalg\"; typeof(linalg.vector([1, 2]))", "Vector"},
+		{"A = [[1, 2], [3, 4]]; A * A", "[[7, 10], [15, 22]]"},
+		{"[[1, 2], [3, 4]] + [[1, 1], [1, 1]]", "[[2, 3], [4, 5]]"},
+		{"2 * [[1, 2], [3, 4]] - 1", "[[1, 3], [5, 7]]"},
+		{"[[2, 4], [6, 8]] / 2", "[[1, 2], [3, 4]]"},
+		{"-[[1, 2], [3, 4]]", "[[-1, -2], [-3, -4]]"},
+		{"[[1, 2], [3, 4]] ^ 2", "[[7, 10], [15, 22]]"},
+		{"[[1, 2], [3, 4]] * [1, 1]", "[3, 7]"},
+		{"[1, 1] * [[1, 2], [3, 4]]", "[4, 6]"},
+		{"import \"linalg\"; linalg.vector([1, 2]) * 3 + linalg.vector([1, 1])", "[4, 7]"},
+		{"[[1, 2], [3, 4]] == [[1, 2], [3, 4]]", "True"},
+		{"import \"linalg\"; [[1, 2], [3, 4]] != linalg.transpose([[1, 2], [3, 4]])", "True"},
+		{"import \"linalg\"; linalg.transpose([[1, 2, 3]])", "[[1], [2], [3]]"},
+		{"import \"linalg\"; linalg.det([[1, 2], [3, 4]])", "-2"},
+		{"import \"linalg\"; linalg.inv([[2, 0], [0, 4]])", "[[0.5, 0], [0, 0.25]]"},
+		{"[[2, 0], [0, 4]] ^ -1", "[[0.5, 0], [0, 0.25]]"},
+		{"import \"numeric\"; numeric.solve([[2, 0], [0, 4]], [2, 2])", "[1, 0.5]"},
+		{"import \"linalg\"; linalg.eigenvalues([[4, 1], [2, 3]])", "[2, 5]"},
+		{"import \"linalg\"; linalg.eigenvalues([[0, -1], [1, 0]])", "[0-1i, 0+1i]"},
+		{"import \"linalg\"; linalg.dot([1, 2, 3], linalg.vector([4, 5, 6]))", "32"},
+		{"import \"linalg\"; linalg.cross([1, 0, 0], [0, 1, 0])", "[0, 0, 1]"},
+		{"import \"linalg\"; linalg.norm([3, 4])", "5"},
+		{"import \"linalg\"; linalg.identity(2)", "[[1, 0], [0, 1]]"},
+		{"len([[1, 2], [3, 4]])", "2"},
+		{"get([[1, 2], [3, 4]], 1)", "[3, 4]"},
+		{"import \"linalg\"; get(linalg.vector([1, 2]), 0)", "1"},
+		{"[[1, 2, 3], [4, 5, 6]] * [[1, 2], [3, 4]]", "dimension mismatch: 2x3 * 2x2"},
+		{"[[1, 2], [3, 4]] + [[1, 2, 3]]", "dimension mismatch: 2x2 + 1x3"},
+		{"[[1, 2], [3, 4]] * [1, 2, 3]", "dimension mismatch: 2x2 * 3"},
+		{"import \"linalg\"; linalg.vector([1, 2]) + linalg.vector([1, 2, 3])", "dimension mismatch: vectors of length 2 + 3"},
+		{"import \"numeric\"; numeric.solve([[1, 2], [3, 4]], [1])", "dimension mismatch: 2x2 x = 1"},
+		{"import \"linalg\"; linalg.cross([1, 2], [3, 4])", "dimension mismatch: cross product of vectors of length 2 and 2, expected 3"},
+		{"import \"linalg\"; linalg.det([[1, 2, 3], [4, 5, 6]])", "dimension mismatch: expected a square matrix, got 2x3"},
+		{"import \"linalg\"; linalg.inv([[1, 2], [2, 4]])", "matrix is singular"},
+		{"[[1, 2], [3, 4]] ^ 0.5", "Matrix powers must be integers, got 0.5"},
+		{"[[1, 2], [3, 4]] / 0", "Cannot divide by zero ([[1, 2], [3, 4]] / 0)"},
+		{"1 / [[1, 2], [3, 4]]", "Unknown operator Float / Matrix"},
+		{"[[1, 2], [3, 4]] && true", "Unknown operator Matrix && Bool"},
+		{"import \"linalg\"; linalg.det(1)", "Argument 1 of det must be of type Matrix, got Float"},
+		{"import \"linalg\"; linalg.dot([true], [1])", "Argument 1 of dot must be of type Vector, got List"},
+		{"import \"linalg\"; linalg.identity(1e9)", "Cannot allocate 1e+18 elements (> 67108864)"},
+		{"import \"linalg\"; linalg.identity(-2)", "identity: the size must be a positive integer, got -2"},
+		{"import \"linalg\"; linalg.identity(1.5)", "identity: the size must be a positive integer, got 1.5"},
+	}
+
+	for _, tt := range tests {
+		res := testEval(tt.input)
+		testingutils.Assert(t, res != nil, "%s: no result", tt.input)
+		testingutils.Equals(t, tt.expected, res.String(), tt.input)
+	}
+}
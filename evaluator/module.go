@@ -0,0 +1,239 @@
+package evaluator
+
+import (
+	"context"
+	"gocalc/ast"
+	"gocalc/environment"
+	"gocalc/lexer"
+	"gocalc/object"
+	"gocalc/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// ModuleExt is the extension of the files that can be imported. Imports of
+// paths without it name the builtin modules, one for every library
+const ModuleExt = ".gc"
+
+// DefaultSearchPath lists the directories where evaluators created with New and
+// NewWithLibraries look for imported files not found next to the importing one
+var DefaultSearchPath []string
+
+// modules holds the modules loaded by an evaluator and its sessions
+type modules struct {
+	mu        sync.Mutex
+	loaded    map[string]*object.Module // by absolute path or builtin name
+	libraries map[string]bool
+	search    []string
+}
+
+func newModules(libraries []string) *modules {
+	m := &modules{loaded: map[string]*object.Module{}, libraries: map[string]bool{}}
+	for _, name := range libraries {
+		m.libraries[name] = true
+	}
+	m.search = append(m.search, DefaultSearchPath...)
+	return m
+}
+
+func (m *modules) get(key string) (*object.Module, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	mod, ok := m.loaded[key]
+	return mod, ok
+}
+
+func (m *modules) set(key string, mod *object.Module) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.loaded[key] = mod
+}
+
+// SetSearchPath sets the directories searched for imported files, for ev and
+// every session sharing its modules
+func (ev *Evaluator) SetSearchPath(dirs ...string) {
+	ev.modules.mu.Lock()
+	defer ev.modules.mu.Unlock()
+	ev.modules.search = append([]string{}, dirs...)
+}
+
+func (ev *Evaluator) SearchPath() []string {
+	ev.modules.mu.Lock()
+	defer ev.modules.mu.Unlock()
+	return append([]string{}, ev.modules.search...)
+}
+
+// EvalFile evaluates the program stored at path. The files it imports are
+// looked up relative to its directory first
+func (ev *Evaluator) EvalFile(path string) object.Object {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return newError("%s", err)
+	}
+	src, err := os.ReadFile(abs)
+	if err != nil {
+		return newError("%s", err)
+	}
+	program, parseErr := ev.parse(string(src))
+	if parseErr != nil {
+		return parseErr
+	}
+	if ev.typeCheck {
+		if _, err := ev.check(program); err != nil {
+			return err
+		}
+	}
+
+	run := ev.fork(context.Background())
+	run.dir = filepath.Dir(abs)
+	run.importing = []string{abs}
+	res := run.run(program)
+	if !isError(res) {
+		ev.global.Set(ANS, res)
+	}
+	return res
+}
+
+func (ev *Evaluator) ImportStatement(is *ast.ImportStatement) object.Object {
+	mod := ev.importModule(is.Path)
+	if isError(mod) {
+		return mod
+	}
+
+	return ev.assign(is.Name(), mod, nil)
+}
+
+func (ev *Evaluator) MemberExpression(me *ast.MemberExpression) object.Object {
+	obj := ev.evaluate(me.Object)
+	if isError(obj) {
+		return obj
+	}
+
+	return member(obj, me.Member.Value)
+}
+
+func member(obj object.Object, name string) object.Object {
+	switch obj := obj.(type) {
+	case *object.Module:
+		if val, ok := obj.Members[name]; ok {
+			return val
+		}
+		return newError(object.MEMBER_NOT_FOUND_ERROR, obj, name)
+	case *object.Record:
+		if i := obj.Of.Field(name); i >= 0 {
+			return obj.Values[i]
+		}
+		return newError(object.MEMBER_NOT_FOUND_ERROR, obj.Of.Name, name)
+	}
+
+	return newError(object.NO_MEMBERS_ERROR, obj.Type())
+}
+
+// importModule returns the module at path, loading it the first time it is imported
+func (ev *Evaluator) importModule(path string) object.Object {
+	if filepath.Ext(path) != ModuleExt {
+		return ev.builtinModule(path)
+	}
+
+	file, ok := ev.findModule(path)
+	if !ok {
+		return newError(object.MODULE_NOT_FOUND_ERROR, path)
+	}
+
+	for i, f := range ev.importing {
+		if f == file {
+			cycle := []string{}
+			for _, imp := range ev.importing[i:] {
+				cycle = append(cycle, filepath.Base(imp))
+			}
+			cycle = append(cycle, filepath.Base(file))
+			return newError(object.IMPORT_CYCLE_ERROR, strings.Join(cycle, " -> "))
+		}
+	}
+
+	if mod, ok := ev.modules.get(file); ok {
+		return mod
+	}
+
+	name := (&ast.ImportStatement{Path: path}).Name()
+	if !isIdentifier(name) {
+		return newError(object.MODULE_NAME_ERROR, name)
+	}
+
+	mod := ev.loadModule(name, file)
+	if m, ok := mod.(*object.Module); ok {
+		ev.modules.set(file, m)
+	}
+	return mod
+}
+
+// loadModule evaluates file in an environment of its own, whose variables become
+// the members of the module
+func (ev *Evaluator) loadModule(name, file string) object.Object {
+	src, err := os.ReadFile(file)
+	if err != nil {
+		return newError("%s", err)
+	}
+
+	ev.importing = append(ev.importing, file)
+	defer func() { ev.importing = ev.importing[:len(ev.importing)-1] }()
+
+	env := environment.NewEnclosed(ev.natives)
+	run := ev.with(env)
+	run.dir = filepath.Dir(file)
+
+	var res object.Object
+	if program, parseErr := ev.parse(string(src)); parseErr != nil {
+		res = parseErr
+	} else {
+		res = run.run(program)
+	}
+	if err, ok := res.(*object.Error); ok {
+		return newErrorKind(err.Kind, object.MODULE_ERROR, name, err.Message)
+	}
+
+	return &object.Module{Name: name, Path: file, Members: env.Locals()}
+}
+
+// findModule resolves path against the directory of the importing file and
+// then against the search path
+func (ev *Evaluator) findModule(path string) (string, bool) {
+	candidates := []string{path}
+	if !filepath.IsAbs(path) {
+		candidates = []string{filepath.Join(ev.dir, path)}
+		for _, dir := range ev.SearchPath() {
+			candidates = append(candidates, filepath.Join(dir, path))
+		}
+	}
+
+	for _, c := range candidates {
+		if info, err := os.Stat(c); err == nil && info.Mode().IsRegular() {
+			abs, err := filepath.Abs(c)
+			return abs, err == nil
+		}
+	}
+	return "", false
+}
+
+// builtinModule returns the module holding the members of a library the
+// evaluator was created with
+func (ev *Evaluator) builtinModule(name string) object.Object {
+	lib, ok := library(name)
+	if !ok || !ev.modules.libraries[name] {
+		return newError(object.UNKNOWN_MODULE_ERROR, name)
+	}
+
+	if mod, ok := ev.modules.get(name); ok {
+		return mod
+	}
+	mod := &object.Module{Name: name, Members: lib.Members}
+	ev.modules.set(name, mod)
+	return mod
+}
+
+func isIdentifier(name string) bool {
+	tok := lexer.New(name).NextToken()
+	return tok.Type == token.IDENT && tok.Literal == name
+}
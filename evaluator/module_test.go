@@ -0,0 +1,109 @@
+package evaluator
+
+import (
+	"gocalc/object"
+	"gocalc/testing_utils"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeModules(t *testing.T, dir string, files map[string]string) {
+	for name, src := range files {
+		path := filepath.Join(dir, name)
+		testingutils.Assert(t, os.MkdirAll(filepath.Dir(path), 0755) == nil, "mkdir %s", path)
+		testingutils.Assert(t, os.WriteFile(path, []byte(src), 0644) == nil, "write %s", path)
+	}
+}
+
+func TestBuiltinModules(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`import "math"; math.sqrt(16) + math.pi - pi`, "4"},
+		{`import "math"; m = math; m.e == e`, "True"},
+		{`import "math"; typeof(math)`, "Module"},
+		{`import "math"; math`, "module math"},
+		{`import "stats"; stats.cdf(stats.normal(), 0)`, "0.5"},
+		{`import "random"; import "stats"; random.seed(1); len(random.sample(stats.normal(), 2))`, "2"},
+		{`import "finance"; finance.pmt(0, 10, 100)`, "-10"},
+		{`import "stats"; stats.rand()`, "module stats has no member rand"},
+		{`mean([1, 3])`, "Identifier not found mean"},
+		{`import "strings"; strings.upper("abc") + strings.lower("DEF")`, "ABCdef"},
+		{`import "strings"; strings.len("héllo")`, "5"},
+		{`import "strings"; strings.split("a,b,c", ",")`, "[a, b, c]"},
+		{`import "strings"; strings.join(["a", "b"], "-")`, "a-b"},
+		{`import "strings"; strings.trim("  a ")`, "a"},
+		{`import "strings"; strings.replace("aXbX", "X", "y")`, "ayby"},
+		{`import "strings"; [strings.contains("abc", "b"), strings.startswith("abc", "b"), strings.endswith("abc", "c")]`, "[True, False, True]"},
+		{`upper("a")`, "Identifier not found upper"},
+		{`import "nope"`, `Unknown module "nope"`},
+		{`import "missing.gc"`, `Module "missing.gc" not found`},
+		{`import "math"; math.nope`, "module math has no member nope"},
+		{`x = 1; x.y`, "Values of type Float have no members"},
+		{`import "strings"; strings.join([1], "")`, "join: element 1 must be of type Str, got Float"},
+	}
+
+	for _, tt := range tests {
+		res := testEval(tt.input)
+		testingutils.Assert(t, res != nil, "%s: no result", tt.input)
+		testingutils.Equals(t, tt.expected, res.String(), tt.input)
+	}
+
+	ev, _ := NewWithLibraries("core")
+	testingutils.Equals(t, `Unknown module "math"`, ev.Eval(`import "math"`).String(), "library not loaded")
+}
+
+func TestFileModules(t *testing.T) {
+	dir, lib := t.TempDir(), t.TempDir()
+	writeModules(t, dir, map[string]string{
+		"main.gc":          `import "geometry/area.gc"; area.square * 2`,
+		"geometry/area.gc": `import "side.gc"; square = side.length ^ 2`,
+		"geometry/side.gc": `length = 3`,
+		"broken.gc":        `x = ;`,
+		"failing.gc":       `x = 1 / 0`,
+		"my-lib.gc":        `x = 1`,
+		"a.gc":             `import "b.gc"; x = 1`,
+		"b.gc":             `import "a.gc"; y = 2`,
+		"self.gc":          `import "self.gc"`,
+	})
+	writeModules(t, lib, map[string]string{"consts.gc": `g = 9.81; rate = 0.05`})
+
+	ev := New()
+	testingutils.Equals(t, "18", ev.EvalFile(filepath.Join(dir, "main.gc")).String(), "EvalFile")
+	testingutils.Equals(t, "18", ev.Eval("ans").String(), "ans")
+	testingutils.Equals(t, "Import cycle: self.gc -> self.gc", ev.EvalFile(filepath.Join(dir, "self.gc")).String(), "self import")
+
+	ev.SetSearchPath(lib)
+	testingutils.Equals(t, "9.81", ev.Eval(`import "consts.gc"; consts.g`).String(), "search path")
+
+	// a module is only evaluated the first time it is imported
+	writeModules(t, lib, map[string]string{"consts.gc": `g = 10`})
+	testingutils.Equals(t, "9.81", ev.Session().Eval(`import "consts.gc"; consts.g`).String(), "cached module")
+
+	ev.SetSearchPath(dir)
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`import "geometry/area.gc"; [area.square, area.side.length]`, "[9, 3]"},
+		{`import "my-lib.gc"`, `Module "my-lib" is not a valid identifier`},
+		{`import "a.gc"`, "In module a: In module b: Import cycle: a.gc -> b.gc -> a.gc"},
+		{`import "failing.gc"`, "In module failing: Cannot divide by zero (1 / 0)"},
+	}
+	for _, tt := range tests {
+		res := ev.Eval(tt.input)
+		testingutils.Assert(t, res != nil, "%s: no result", tt.input)
+		testingutils.Equals(t, tt.expected, res.String(), tt.input)
+	}
+
+	err, ok := ev.Eval(`import "broken.gc"`).(*object.Error)
+	testingutils.Assert(t, ok && err.Kind == object.ERR_SYNTAX, "expected a syntax error, got %v", err)
+}
+
+func TestCompileImports(t *testing.T) {
+	c, err := New().Compile(`import "math"; math.sin(x) + lib.y`)
+	testingutils.Assert(t, err == nil, "compile error: %s", err)
+	testingutils.Equals(t, []string{"x", "lib"}, c.FreeVars, "c.FreeVars")
+}
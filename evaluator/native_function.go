@@ -2,14 +2,68 @@ package evaluator
 
 import (
 	"gocalc/object"
+	"gocalc/types"
 	"reflect"
+	"strings"
 )
 
 type NativeFn func(*Evaluator, ...object.Object) object.Object
 
+// Signature declares the parameters a native function accepts.
+// When Variadic is set the last parameter type may be repeated any number of times,
+// the last Optional parameters may be left out
+type Signature struct {
+	Params   []object.ObjectType
+	Variadic bool
+	Optional int
+}
+
 type NativeFunction struct {
-	Name     string
-	Function NativeFn
+	Name      string
+	Function  NativeFn
+	Signature *Signature
+	Quoted    bool       // arguments are passed unevaluated, see Expression
+	Result    types.Type // type of the result for the type checker, nil when unknown
+
+	// Elementwise natives called with a single list, vector or matrix are
+	// applied to each of its elements
+	Elementwise bool
+}
+
+// newTypedFunction creates a native whose arguments are checked against params
+func newTypedFunction(fn NativeFn, name string, params ...object.ObjectType) *NativeFunction {
+	return &NativeFunction{Function: fn, Name: name, Signature: &Signature{Params: params}}
+}
+
+// returns sets the type of the results of nf for the type checker
+func (nf *NativeFunction) returns(t types.Type) *NativeFunction {
+	nf.Result = t
+	return nf
+}
+
+// newFloatFunction creates a native over numbers whose last arguments may be
+// left out and take the values of defaults
+func newFloatFunction(name string, params int, defaults []float64, fn func(ev *Evaluator, x []float64) object.Object) *NativeFunction {
+	floats := make([]object.ObjectType, params+len(defaults))
+	for i := range floats {
+		floats[i] = object.FLOAT
+	}
+
+	nf := newTypedFunction(func(ev *Evaluator, args ...object.Object) object.Object {
+		x := make([]float64, len(floats))
+		for i, arg := range args {
+			x[i] = arg.(*object.Float).Value
+		}
+		copy(x[len(args):], defaults[len(args)-params:])
+		return fn(ev, x)
+	}, name, floats...)
+	return optional(nf, len(defaults))
+}
+
+// optional lets the last n parameters of nf be left out
+func optional(nf *NativeFunction, n int) *NativeFunction {
+	nf.Signature.Optional = n
+	return nf
 }
 
 func (nf *NativeFunction) String() string {
@@ -18,3 +72,75 @@ func (nf *NativeFunction) String() string {
 func (nf *NativeFunction) Type() object.ObjectType     { return object.NATIVE_FUNCTION }
 func (nf *NativeFunction) TypeS() string               { return nf.Type().Stringf(nf.Name) }
 func (nf *NativeFunction) Is(t object.ObjectType) bool { return nf.Type() == t }
+
+func (nf *NativeFunction) call(ev *Evaluator, args []object.Object) object.Object {
+	if nf.Elementwise && len(args) == 1 && (isList(args[0]) || isLinalg(args[0])) {
+		return mapElements(args[0], func(elem object.Object) object.Object {
+			return nf.call(ev, []object.Object{elem})
+		})
+	}
+
+	if nf.Signature != nil {
+		if err := nf.Signature.check(nf.Name, args); err != nil {
+			return err
+		}
+	}
+
+	return nf.Function(ev, args...)
+}
+
+func (s *Signature) String() string {
+	params := make([]string, len(s.Params))
+	for i, p := range s.Params {
+		params[i] = p.String()
+	}
+	if s.Variadic && len(params) > 0 {
+		params[len(params)-1] += "..."
+	}
+	for i := len(params) - s.Optional; i < len(params); i++ {
+		params[i] = "[" + params[i] + "]"
+	}
+	return "(" + strings.Join(params, ", ") + ")"
+}
+
+func (s *Signature) check(name string, args []object.Object) *object.Error {
+	// errors are returned before the arguments are checked, unless the
+	// parameter is declared as an error. ANY does not accept them
+	for i, arg := range args {
+		if err, ok := arg.(*object.Error); ok && (len(s.Params) == 0 || s.param(i) != object.ERROR) {
+			return err
+		}
+	}
+
+	n, min := len(s.Params), len(s.Params)-s.Optional
+	if s.Variadic && s.Optional == 0 {
+		min = n - 1
+	}
+	if len(args) < min || (!s.Variadic && len(args) > n) {
+		return newError(object.WRONG_ARGUMENTS_ERROR, name, s, len(args))
+	}
+
+	for i, arg := range args {
+		if s.accepts(i, arg.Type()) {
+			continue
+		}
+		return newError(object.ARGUMENT_TYPE_ERROR, i+1, name, s.param(i), arg.Type())
+	}
+
+	return nil
+}
+
+func (s *Signature) param(i int) object.ObjectType {
+	if i >= len(s.Params) {
+		return s.Params[len(s.Params)-1]
+	}
+	return s.Params[i]
+}
+
+func (s *Signature) accepts(i int, t object.ObjectType) bool {
+	if len(s.Params) == 0 {
+		return false
+	}
+	p := s.param(i)
+	return p == object.ANY || p == t
+}
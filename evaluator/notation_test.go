@@ -0,0 +1,49 @@
+package evaluator
+
+import (
+	"gocalc/testing_utils"
+	"testing"
+)
+
+func TestNotation(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"x = 3; 2x^2", "18"},
+		{"2pi == 2 * pi", "True"},
+		{"3(4 + 5)", "27"},
+		{"x = 4; 1/2x", "0.125"},
+		{"5!", "120"},
+		{"0!", "1"},
+		{"3! ^ 2", "36"},
+		{"200 * 15%", "30"},
+		{"|-3| + |2 - 5|", "6"},
+		{"|[-1, 2]|", "[1, 2]"},
+		{"√16 + 2√9", "10"},
+		{"6 × 7 ÷ 2", "21"},
+		{"(1 ≤ 2) && (2 ≠ 3)", "True"},
+		{"x = 2; y = 3x; y", "6"},
+		{"2e == 2 * e", "True"},
+		{"d = 3; 2d", "6"},
+		{"s = 1; m = 2; h = 3; w = 4; [2s, 2m, 2h, 2w]", "[2, 4, 6, 8]"},
+		{"|1 - |-3||", "2"},
+		{"||-2| - 5|", "3"},
+		{"2e3", "2000"},
+		{"x = 2; 1_000x", "2000"},
+	}
+
+	for _, tt := range tests {
+		ev := New()
+		ev.SetNotation(true)
+		res := ev.Eval(tt.input)
+		testingutils.Assert(t, res != nil, "%s: no result", tt.input)
+		testingutils.Equals(t, tt.expected, res.String(), tt.input)
+	}
+
+	ev := New()
+	ev.SetNotation(true)
+	typ, err := ev.Check("x = 2; 3x!")
+	testingutils.Assert(t, err == nil, "check: %s", err)
+	testingutils.Equals(t, "Float", typ.String(), "type of the notation")
+}
@@ -0,0 +1,28 @@
+package evaluator
+
+import (
+	"gocalc/testing_utils"
+	"testing"
+)
+
+func TestNumbers(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"6.02e23 == 602 * 10^21", "True"},
+		{"1E-3 * 1_000", "1"},
+		{".5 + 5.", "5.5"},
+		{"1_000_000.000_1", "1.0000000001e+06"},
+		{"1_500ms", "1s 500ms"},
+		{"1.2.3", "Syntax error: \n\t\tMalformed number 1.2.3 at line 1, column 1: unexpected decimal point"},
+		{"x = 1_", "Syntax error: \n\t\tMalformed number 1_ at line 1, column 5: misplaced digit separator"},
+		{"1e", "Syntax error: \n\t\tMalformed number 1e at line 1, column 1: missing exponent digits"},
+	}
+
+	for _, tt := range tests {
+		res := testEval(tt.input)
+		testingutils.Assert(t, res != nil, "%s: no result", tt.input)
+		testingutils.Equals(t, tt.expected, res.String(), tt.input)
+	}
+}
@@ -0,0 +1,439 @@
+package evaluator
+
+import (
+	"errors"
+	"gocalc/ast"
+	"gocalc/environment"
+	"gocalc/numeric"
+	"gocalc/object"
+	"gocalc/symbolic"
+	"math"
+)
+
+// SetNumericOptions sets the tolerance and the maximum number of iterations of
+// the numeric methods, such as the solvers, deriv or irr. Zero values keep the
+// defaults of numeric.DefaultOptions
+func (ev *Evaluator) SetNumericOptions(opts numeric.Options) { ev.numericOpts = opts }
+
+func (ev *Evaluator) NumericOptions() numeric.Options { return ev.numericOptions() }
+
+// numericOptions returns the options of ev, the defaults for the ones not set
+func (ev *Evaluator) numericOptions() numeric.Options {
+	opts := ev.numericOpts
+	if opts.Tol <= 0 {
+		opts.Tol = numeric.DefaultOptions.Tol
+	}
+	if opts.MaxIter < 1 {
+		opts.MaxIter = numeric.DefaultOptions.MaxIter
+	}
+	return opts
+}
+
+// realFunction evaluates node as a function of the variable x. Every call
+// counts as an iteration of the evaluation
+func (ev *Evaluator) realFunction(node ast.Expression, x string) numeric.Func {
+	env := environment.NewEnclosed(ev.global)
+	run := ev.with(env)
+	run.symbolic = false
+
+	return func(v float64) (float64, error) {
+		if err := ev.CountIteration(); err != nil {
+			return 0, err
+		}
+		env.Set(x, &object.Float{Value: v})
+		switch res := run.evaluate(node).(type) {
+		case *object.Float:
+			return res.Value, nil
+		case *object.Error:
+			return 0, res
+		default:
+			return 0, newError("%s must evaluate to a Float, got %s", node, res.Type())
+		}
+	}
+}
+
+// numericError converts the errors of numeric methods, keeping the ones of the evaluation
+func numericError(err error) object.Object {
+	var objErr *object.Error
+	if errors.As(err, &objErr) {
+		return objErr
+	}
+	if errors.Is(err, numeric.ErrNoConvergence) {
+		return newErrorKind(object.ERR_NO_CONVERGENCE, "%s", err)
+	}
+	return newError("%s", err)
+}
+
+// equationArg returns the expression whose roots solve a quoted equation lhs == rhs,
+// expression or function name, keeping the variable x as a symbol
+func (ev *Evaluator) equationArg(arg object.Object, x string) (ast.Expression, object.Object) {
+	node := arg.(*Expression).Node
+	if id, ok := node.(*ast.Identifier); ok {
+		switch val, _ := ev.global.Get(id.Value); val := val.(type) {
+		case *NativeFunction:
+			if x == "" {
+				x = "x"
+			}
+			return ast.NewCallExpression(id.Value, ast.NewIdentifier(x)), nil
+		case *Expression:
+			node = val.Node
+		}
+	}
+
+	var bindings map[string]object.Object
+	if x != "" {
+		bindings = map[string]object.Object{x: symbol(x)}
+	}
+
+	eq, ok := node.(*ast.InfixExpression)
+	if !ok || eq.Operator != "==" {
+		return ev.symbolicArg(&Expression{Node: node}, bindings)
+	}
+
+	lhs, err := ev.symbolicArg(&Expression{Node: eq.Left}, bindings)
+	if err != nil {
+		return nil, err
+	}
+	rhs, err := ev.symbolicArg(&Expression{Node: eq.Right}, bindings)
+	if err != nil {
+		return nil, err
+	}
+	return ast.NewInfixExpression("-", lhs, rhs), nil
+}
+
+// freeVariables lists the identifiers of e that are not function names, in order of appearance
+func freeVariables(e ast.Expression) []string {
+	vars := []string{}
+	seen := map[string]bool{}
+	ast.Inspect(e, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.CallExpression:
+			for _, a := range n.Arguments {
+				for _, v := range freeVariables(a) {
+					if !seen[v] {
+						seen[v] = true
+						vars = append(vars, v)
+					}
+				}
+			}
+			return false
+		case *ast.MemberExpression:
+			// the member is a name inside the object, not a variable
+			for _, v := range freeVariables(n.Object) {
+				if !seen[v] {
+					seen[v] = true
+					vars = append(vars, v)
+				}
+			}
+			return false
+		case *ast.Identifier:
+			if !seen[n.Value] {
+				seen[n.Value] = true
+				vars = append(vars, n.Value)
+			}
+		}
+		return true
+	})
+	return vars
+}
+
+// equationArgs splits the arguments of a solver or of a calculus native into the
+// equation, its variable and the remaining arguments. The variable is either the
+// second argument, when it is an identifier used by the equation, or the only
+// unknown of the equation
+func (ev *Evaluator) equationArgs(name string, args []object.Object) (ast.Expression, string, []object.Object, object.Object) {
+	if len(args) == 0 {
+		return nil, "", nil, newError(object.WRONG_ARGUMENTS_ERROR, name, "(Expr, Ident, Float...)", 0)
+	}
+
+	if len(args) >= 2 {
+		if x, ok := variableArg(args[1]); ok {
+			node, err := ev.equationArg(args[0], x)
+			if err != nil {
+				return nil, "", nil, err
+			}
+			if symbolic.Contains(node, x) {
+				return node, x, args[2:], nil
+			}
+		}
+	}
+
+	node, err := ev.equationArg(args[0], "")
+	if err != nil {
+		return nil, "", nil, err
+	}
+	vars := freeVariables(node)
+	if len(vars) != 1 {
+		return nil, "", nil, newError("%s: expected an expression in one unknown, got %s", name, node)
+	}
+	return node, vars[0], args[1:], nil
+}
+
+// floatArgs evaluates the remaining quoted arguments of a solver
+func (ev *Evaluator) floatArgs(name string, args []object.Object) ([]float64, object.Object) {
+	values := make([]float64, len(args))
+	for i, arg := range args {
+		switch res := ev.evaluate(arg.(*Expression).Node).(type) {
+		case *object.Float:
+			values[i] = res.Value
+		case *object.Error:
+			return nil, res
+		default:
+			return nil, newError(object.ARGUMENT_TYPE_ERROR, i+2, name, object.FLOAT, res.Type())
+		}
+	}
+	return values, nil
+}
+
+// newton returns a Newton solver using the symbolic derivative of the equation when there is one
+func (ev *Evaluator) newton(node ast.Expression, x string, x0 float64) (float64, error) {
+	var df numeric.Func
+	if d, err := symbolic.Diff(node, x); err == nil {
+		df = ev.realFunction(symbolic.Simplify(d), x)
+	}
+	return numeric.Newton(ev.realFunction(node, x), df, x0, ev.numericOptions())
+}
+
+// linearSystem evaluates the first argument of solve(a, b) once, returning
+// either the matrix of a linear system or the equation as a quoted value. The
+// second argument is bound to a symbol when it is an identifier, as in
+// equationArgs
+func (ev *Evaluator) linearSystem(a, b object.Object) (*object.Matrix, object.Object, object.Object) {
+	switch node := a.(*Expression).Node.(type) {
+	case *ast.Identifier:
+		val, _ := ev.global.Get(node.Value)
+		m, _ := val.(*object.Matrix)
+		return m, a, nil
+	case *ast.InfixExpression:
+		if node.Operator == "==" {
+			return nil, a, nil
+		}
+	}
+
+	var bindings map[string]object.Object
+	if x, ok := variableArg(b); ok {
+		bindings = map[string]object.Object{x: symbol(x)}
+	}
+	res := ev.symbolicValue(a, bindings)
+	if m, ok := res.(*object.Matrix); ok {
+		return m, nil, nil
+	}
+	if isError(res) {
+		return nil, nil, res
+	}
+	node, ok := toNode(res)
+	if !ok {
+		return nil, nil, newError("expected an expression, got %s", res.Type())
+	}
+	return nil, &Expression{Node: node}, nil
+}
+
+// solver makes a native finding a root of an equation. Natives bracketing the
+// root take the interval [a, b], the others a starting point
+func solver(name string) NativeFn {
+	return func(ev *Evaluator, args ...object.Object) object.Object {
+		if name == "solve" && len(args) == 2 {
+			m, equation, err := ev.linearSystem(args[0], args[1])
+			if err != nil {
+				return err
+			}
+			if m != nil {
+				return linearSolve(m.Value, ev.evaluate(args[1].(*Expression).Node))
+			}
+			args = []object.Object{equation, args[1]}
+		}
+
+		node, x, rest, err := ev.equationArgs(name, args)
+		if err != nil {
+			return err
+		}
+		values, err := ev.floatArgs(name, rest)
+		if err != nil {
+			return err
+		}
+
+		f := ev.realFunction(node, x)
+		opts := ev.numericOptions()
+		var root float64
+		var solveErr error
+		switch {
+		case name == "solve" && len(values) == 0:
+			root, solveErr = ev.newton(node, x, 1)
+		case (name == "solve" || name == "newton") && len(values) == 1:
+			root, solveErr = ev.newton(node, x, values[0])
+		case (name == "solve" || name == "brent") && len(values) == 2:
+			root, solveErr = numeric.Brent(f, values[0], values[1], opts)
+		case name == "bisect" && len(values) == 2:
+			root, solveErr = numeric.Bisection(f, values[0], values[1], opts)
+		default:
+			return newError(object.WRONG_ARGUMENTS_ERROR, name, solverSignatures[name], len(args))
+		}
+
+		if solveErr != nil {
+			return numericError(solveErr)
+		}
+		return &object.Float{Value: root}
+	}
+}
+
+// integrator makes a native integrating an expression over [a, b] with method
+func integrator(name string, method func(numeric.Func, float64, float64, numeric.Options) (float64, error)) NativeFn {
+	return func(ev *Evaluator, args ...object.Object) object.Object {
+		node, x, rest, err := ev.equationArgs(name, args)
+		if err != nil {
+			return err
+		}
+		values, err := ev.floatArgs(name, rest)
+		if err != nil {
+			return err
+		}
+		if len(values) != 2 {
+			return newError(object.WRONG_ARGUMENTS_ERROR, name, "(Expr, Ident, Float, Float)", len(args))
+		}
+
+		res, solveErr := method(ev.realFunction(node, x), values[0], values[1], ev.numericOptions())
+		if solveErr != nil {
+			return numericError(solveErr)
+		}
+		return &object.Float{Value: res}
+	}
+}
+
+func numericDeriv(ev *Evaluator, args ...object.Object) object.Object {
+	node, x, rest, err := ev.equationArgs("deriv", args)
+	if err != nil {
+		return err
+	}
+	values, err := ev.floatArgs("deriv", rest)
+	if err != nil {
+		return err
+	}
+	if len(values) != 1 {
+		return newError(object.WRONG_ARGUMENTS_ERROR, "deriv", "(Expr, Ident, Float)", len(args))
+	}
+
+	res, derivErr := numeric.Derivative(ev.realFunction(node, x), values[0], ev.numericOptions())
+	if derivErr != nil {
+		return numericError(derivErr)
+	}
+	return &object.Float{Value: res}
+}
+
+// numericSum adds the values of a list, or of an expression over a range of integers
+func numericSum(ev *Evaluator, args ...object.Object) object.Object {
+	if len(args) == 1 {
+		switch list := ev.evaluate(args[0].(*Expression).Node).(type) {
+		case *object.Error:
+			return list
+		case *object.List:
+			res, err := numeric.Sum(func(k float64) (float64, error) {
+				if f, ok := list.Values[int(k)].(*object.Float); ok {
+					return f.Value, nil
+				}
+				return 0, newError("sum: element %d must be of type %s, got %s", int(k)+1, object.FLOAT, list.Values[int(k)].Type())
+			}, 0, len(list.Values)-1)
+			if err != nil {
+				return numericError(err)
+			}
+			return &object.Float{Value: res}
+		}
+	}
+
+	node, x, rest, err := ev.equationArgs("sum", args)
+	if err != nil {
+		return err
+	}
+	values, err := ev.floatArgs("sum", rest)
+	if err != nil {
+		return err
+	}
+	if len(values) != 2 {
+		return newError(object.WRONG_ARGUMENTS_ERROR, "sum", "(Expr, Ident, Float, Float)", len(args))
+	}
+	for _, v := range values {
+		if v != math.Trunc(v) || math.Abs(v) > math.MaxInt32 {
+			return newError("sum: the bounds must be integers, got %s", (&object.Float{Value: v}).String())
+		}
+	}
+
+	res, sumErr := numeric.Sum(ev.realFunction(node, x), int(values[0]), int(values[1]))
+	if sumErr != nil {
+		return numericError(sumErr)
+	}
+	return &object.Float{Value: res}
+}
+
+var solverSignatures = map[string]string{
+	"solve":  "(Expr, Ident, Float...)",
+	"newton": "(Expr, Ident, Float)",
+	"brent":  "(Expr, Ident, Float, Float)",
+	"bisect": "(Expr, Ident, Float, Float)",
+}
+
+// polynomialRoots returns every root of a polynomial, given by an expression in
+// one unknown or by its list of coefficients from the highest degree down
+func polynomialRoots(ev *Evaluator, args ...object.Object) object.Object {
+	if len(args) != 1 {
+		return newError(object.WRONG_ARGUMENTS_ERROR, "roots", "(Expr)", len(args))
+	}
+
+	var coefs []float64
+	if list, ok := ev.evaluate(args[0].(*Expression).Node).(*object.List); ok {
+		for i, v := range list.Values {
+			f, ok := v.(*object.Float)
+			if !ok {
+				return newError("roots: coefficient %d must be of type %s, got %s", i+1, object.FLOAT, v.Type())
+			}
+			coefs = append(coefs, f.Value)
+		}
+	} else {
+		node, err := ev.symbolicArg(args[0], nil)
+		if err != nil {
+			return err
+		}
+		poly, ok := symbolic.PolynomialOf(node)
+		vars := freeVariables(node)
+		if !ok || len(vars) > 1 {
+			return newError("roots: expected a polynomial in one unknown, got %s", node)
+		}
+		x := ""
+		if len(vars) == 1 {
+			x = vars[0]
+		}
+		for _, c := range poly.Coefficients(x) {
+			coefs = append([]float64{c}, coefs...)
+		}
+	}
+
+	roots, err := numeric.PolyRoots(coefs, ev.numericOptions())
+	if err != nil {
+		return numericError(err)
+	}
+	return complexList(roots)
+}
+
+// complexList returns real numbers as floats and the others as complex numbers
+func complexList(numbers []complex128) *object.List {
+	values := make([]object.Object, len(numbers))
+	for i, z := range numbers {
+		if imag(z) == 0 {
+			values[i] = &object.Float{Value: real(z)}
+		} else {
+			values[i] = &object.Complex{Value: z}
+		}
+	}
+	return &object.List{Values: values}
+}
+
+func complexPart(name string, part func(complex128) float64) *NativeFunction {
+	fn := func(ev *Evaluator, args ...object.Object) object.Object {
+		switch z := args[0].(type) {
+		case *object.Complex:
+			return &object.Float{Value: part(z.Value)}
+		case *object.Float:
+			return &object.Float{Value: part(complex(z.Value, 0))}
+		}
+		return newError(object.ARGUMENT_TYPE_ERROR, 1, name, object.COMPLEX, args[0].Type())
+	}
+	return newTypedFunction(fn, name, object.ANY)
+}
@@ -0,0 +1,100 @@
+package evaluator
+
+import (
+	"gocalc/numeric"
+	"gocalc/object"
+	"gocalc/testing_utils"
+	"testing"
+)
+
+func TestSolve(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"import \"numeric\"; numeric.solve(x ^ 2 == 2, x)", "1.414213562373095"},
+		{"import \"numeric\"; numeric.solve(cos(x) == x, 1)", "0.7390851332151607"},
+		{"import \"numeric\"; numeric.solve(cos, 1)", "1.5707963267948966"},
+		{"import \"numeric\"; x = 5; numeric.solve(x ^ 2 == 9, x, 1)", "3"},
+		{"import \"numeric\"; f = '(x ^ 2 - 4); (numeric.solve(f, x, 0, 5) - 2) ^ 2 < 0.000000000001", "True"},
+		{"import \"numeric\"; x0 = 3; numeric.solve(x ^ 2 - 4, x0)", "2"},
+		{"import \"random\"; import \"numeric\"; random.seed(3); a = random.rand(); random.seed(3); (numeric.solve(x - random.rand(), 5) - a) ^ 2 < 0.000000000001", "True"},
+		{"import \"numeric\"; a = [[2, 0], [0, 4]]; numeric.solve(a, [2, 2])", "[1, 0.5]"},
+		{"import \"numeric\"; numeric.brent(x ^ 3 - 2 * x - 5, x, 2, 3)", "2.094551481542327"},
+		{"import \"numeric\"; numeric.newton(x ^ 3 - 2 * x - 5, x, 2)", "2.0945514815423265"},
+		{"import \"numeric\"; numeric.bisect(x - 0.5, 0, 1)", "0.5"},
+		{"import \"numeric\"; numeric.solve(x ^ 2 + 1, x, 0, 1)", "brent: root not bracketed, f(0) and f(1) have the same sign"},
+		{"import \"numeric\"; numeric.solve(x + y, 1)", "solve: expected an expression in one unknown, got (x + y)"},
+		{"import \"numeric\"; numeric.solve(x ^ 2 == 4, true)", "Argument 2 of solve must be of type Float, got Bool"},
+		{"import \"numeric\"; numeric.bisect(x, 1)", "bisect expects arguments (Expr, Ident, Float, Float), got 2"},
+		{"import \"numeric\"; numeric.solve(x == [1], 1)", "expected an expression, got List"},
+		{"import \"numeric\"; numeric.roots(x ^ 2 - 3 * x + 2)", "[1, 2]"},
+		{"import \"numeric\"; numeric.roots([1, 0, 1])", "[0-1i, 0+1i]"},
+		{"import \"numeric\"; numeric.roots([1, -6, 11, -6])", "[1, 2, 3]"},
+		{"import \"numeric\"; typeof(get(numeric.roots([1, 0, 1]), 0))", "Complex"},
+		{"import \"numeric\"; numeric.im(get(numeric.roots(x ^ 2 + 4), 1))", "2"},
+		{"import \"numeric\"; numeric.roots(sin(x))", "roots: expected a polynomial in one unknown, got sin(x)"},
+		{"import \"numeric\"; numeric.roots([1, true])", "roots: coefficient 2 must be of type Float, got Bool"},
+	}
+
+	for _, tt := range tests {
+		res := testEval(tt.input)
+		testingutils.Assert(t, res != nil, "%s: no result", tt.input)
+		testingutils.Equals(t, tt.expected, res.String(), tt.input)
+	}
+}
+
+func TestSolveOptions(t *testing.T) {
+	ev := New()
+	ev.SetNumericOptions(numeric.Options{MaxIter: 3})
+	res := ev.Eval(`import "numeric"; numeric.solve(x ^ 2 - 2, 100)`)
+	err, ok := res.(*object.Error)
+	testingutils.Assert(t, ok, "expected an error, got %s", res.TypeS())
+	testingutils.Equals(t, object.ERR_NO_CONVERGENCE, err.Kind, "err.Kind")
+	testingutils.Equals(t, "newton: no convergence after 3 iterations", err.Message, "err.Message")
+
+	ev = New()
+	ev.SetNumericOptions(numeric.Options{Tol: 0.1})
+	testingutils.Equals(t, "0.3125", ev.Eval(`import "numeric"; numeric.bisect(x - 0.3, 0, 1)`).String(), "coarse tolerance")
+	testingutils.Equals(t, numeric.DefaultOptions.MaxIter, ev.NumericOptions().MaxIter, "unset options keep the default")
+
+	res = testEval(`import "numeric"; tol = 0.1; maxiter = 3; numeric.bisect(x - 0.3, 0, 1)`)
+	testingutils.Equals(t, "0.3000000000001819", res.String(), "variables do not change the options")
+
+	ev = New()
+	ev.SetLimits(Limits{MaxIterations: 3})
+	res = ev.Eval(`import "numeric"; numeric.solve(cos(x) - x, 0, 1)`)
+	err, ok = res.(*object.Error)
+	testingutils.Assert(t, ok, "expected an error, got %s", res.TypeS())
+	testingutils.Equals(t, object.ERR_ITERATION_LIMIT, err.Kind, "evaluations of the equation count as iterations")
+}
+
+func TestCalculus(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"import \"numeric\"; numeric.integrate(x ^ 2, 0, 3)", "9"},
+		{"import \"numeric\"; numeric.integrate(sin, 0, pi)", "2"},
+		{"import \"numeric\"; f = '(x ^ 2); numeric.integrate(f, x, 0, 3)", "9"},
+		{"import \"numeric\"; numeric.simpson(x ^ 2, 0, 3)", "9"},
+		{"import \"numeric\"; numeric.integrate(1 / x, 0, 1)", "integrate: no convergence after 100 iterations"},
+		{"import \"numeric\"; numeric.integrate(x, 0)", "integrate expects arguments (Expr, Ident, Float, Float), got 2"},
+		{"import \"numeric\"; numeric.integrate(x + y, 0, 1)", "integrate: expected an expression in one unknown, got (x + y)"},
+		{"import \"numeric\"; numeric.deriv(sin, 0)", "1"},
+		{"import \"numeric\"; (numeric.deriv(t ^ 3, t, 2) - 12) ^ 2 < 0.000000000001", "True"},
+		{"import \"numeric\"; numeric.deriv(x, 1, 2)", "deriv expects arguments (Expr, Ident, Float), got 3"},
+		{"import \"numeric\"; numeric.sum(k, k, 1, 100)", "5050"},
+		{"import \"numeric\"; numeric.sum(1 / 2 ^ k, 1, 10)", "0.9990234375"},
+		{"import \"numeric\"; numeric.sum([1, 2, 3])", "6"},
+		{"import \"numeric\"; numeric.sum([1, true])", "sum: element 2 must be of type Float, got Bool"},
+		{"import \"numeric\"; numeric.sum(k, 1, 2.5)", "sum: the bounds must be integers, got 2.5"},
+		{"import \"numeric\"; numeric.integrate(x == true, 0, 1)", "expected an expression, got Bool"},
+	}
+
+	for _, tt := range tests {
+		res := testEval(tt.input)
+		testingutils.Assert(t, res != nil, "%s: no result", tt.input)
+		testingutils.Equals(t, tt.expected, res.String(), tt.input)
+	}
+}
@@ -0,0 +1,207 @@
+package evaluator
+
+import (
+	"gocalc/object"
+	"gocalc/stats"
+	"gocalc/types"
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// random is the generator shared by the evaluations of an evaluator
+type random struct {
+	mu sync.Mutex
+	r  *rand.Rand
+}
+
+func newRandom() *random {
+	return &random{r: rand.New(rand.NewSource(time.Now().UnixNano()))}
+}
+
+// Seed makes the random numbers drawn by ev and its sessions reproducible
+func (ev *Evaluator) Seed(seed int64) {
+	ev.random.mu.Lock()
+	defer ev.random.mu.Unlock()
+	ev.random.r.Seed(seed)
+}
+
+// withRandom runs fn holding the generator
+func (ev *Evaluator) withRandom(fn func(r *rand.Rand)) {
+	ev.random.mu.Lock()
+	defer ev.random.mu.Unlock()
+	fn(ev.random.r)
+}
+
+// sampleCount reads the number of values to draw and checks it against the limits
+func (ev *Evaluator) sampleCount(name string, arg object.Object) (int, object.Object) {
+	n := arg.(*object.Float).Value
+	if n < 0 || n != math.Trunc(n) {
+		return 0, newError("%s: the number of values must be a natural number, got %s", name, arg)
+	}
+	if err := ev.checkSize(n); err != nil {
+		return 0, err
+	}
+	return int(n), nil
+}
+
+func nativeRand(ev *Evaluator, args ...object.Object) object.Object {
+	var x float64
+	ev.withRandom(func(r *rand.Rand) { x = r.Float64() })
+	return newFloat(x)
+}
+
+func nativeRandint(ev *Evaluator, args ...object.Object) object.Object {
+	a, b := args[0].(*object.Float).Value, args[1].(*object.Float).Value
+	if a != math.Trunc(a) || b != math.Trunc(b) || a > b {
+		return newError("randint: the bounds must be integers a <= b, got %s and %s", args[0], args[1])
+	}
+	if b-a >= math.MaxInt64 {
+		return newError("randint: the range %s to %s is too large", args[0], args[1])
+	}
+
+	var k int64
+	ev.withRandom(func(r *rand.Rand) { k = r.Int63n(int64(b-a) + 1) })
+	return newFloat(a + float64(k))
+}
+
+func nativeChoice(ev *Evaluator, args ...object.Object) object.Object {
+	list, ok := asList(args[0])
+	if !ok {
+		return newError(object.ARGUMENT_TYPE_ERROR, 1, "choice", object.LIST, args[0].Type())
+	}
+	if len(list.Values) == 0 {
+		return newError("choice: the list is empty")
+	}
+
+	var i int
+	ev.withRandom(func(r *rand.Rand) { i = r.Intn(len(list.Values)) })
+	return list.Values[i]
+}
+
+func nativeShuffle(ev *Evaluator, args ...object.Object) object.Object {
+	list, ok := asList(args[0])
+	if !ok {
+		return newError(object.ARGUMENT_TYPE_ERROR, 1, "shuffle", object.LIST, args[0].Type())
+	}
+
+	values := append([]object.Object{}, list.Values...)
+	ev.withRandom(func(r *rand.Rand) {
+		r.Shuffle(len(values), func(i, j int) { values[i], values[j] = values[j], values[i] })
+	})
+	return &object.List{Values: values}
+}
+
+func nativeSeed(ev *Evaluator, args ...object.Object) object.Object {
+	n := args[0].(*object.Float).Value
+	if n != math.Trunc(n) || math.Abs(n) > math.MaxInt64 {
+		return newError("seed: the seed must be an integer, got %s", args[0])
+	}
+	ev.Seed(int64(n))
+	return NULL
+}
+
+// distribution creates a native building a distribution from its parameters,
+// the last ones taking the values of defaults when left out
+func distribution(name string, params int, defaults []float64, fn func(x []float64) (stats.Distribution, error)) *NativeFunction {
+	return newFloatFunction(name, params, defaults, func(ev *Evaluator, x []float64) object.Object {
+		d, err := fn(x)
+		if err != nil {
+			return newError("%s: %s", name, err)
+		}
+		return &object.Distribution{Value: d}
+	}).returns(types.Dist)
+}
+
+// distributionFunction creates a native evaluating a function of a distribution
+// at a value or at every element of a list
+func distributionFunction(name string, fn func(d stats.Distribution, x float64) object.Object) *NativeFunction {
+	return newTypedFunction(func(ev *Evaluator, args ...object.Object) object.Object {
+		d := args[0].(*object.Distribution).Value
+		return mapElements(args[1], func(elem object.Object) object.Object {
+			x, ok := elem.(*object.Float)
+			if !ok {
+				return newError(object.ARGUMENT_TYPE_ERROR, 2, name, object.FLOAT, elem.Type())
+			}
+			return fn(d, x.Value)
+		})
+	}, name, object.DISTRIBUTION, object.ANY)
+}
+
+func quantile(d stats.Distribution, p float64) object.Object {
+	if p < 0 || p > 1 {
+		return newError("quantile: the probability must be between 0 and 1, got %s", newFloat(p))
+	}
+	return newFloat(d.Quantile(p))
+}
+
+func nativeSample(ev *Evaluator, args ...object.Object) object.Object {
+	d := args[0].(*object.Distribution).Value
+	if len(args) == 1 {
+		var x float64
+		ev.withRandom(func(r *rand.Rand) { x = d.Sample(r) })
+		return newFloat(x)
+	}
+
+	n, err := ev.sampleCount("sample", args[1])
+	if err != nil {
+		return err
+	}
+	values := make([]object.Object, n)
+	ev.withRandom(func(r *rand.Rand) {
+		for i := range values {
+			values[i] = newFloat(d.Sample(r))
+		}
+	})
+	return &object.List{Values: values}
+}
+
+// moments returns the mean and variance of a distribution or the mean and population
+// variance of a list of numbers. Booleans count as 1 and 0 so that the mean of a list
+// of conditions is the frequency they hold
+func moments(name string, arg object.Object) (mean, variance float64, err object.Object) {
+	if d, ok := arg.(*object.Distribution); ok {
+		return d.Value.Mean(), d.Value.Variance(), nil
+	}
+
+	list, ok := asList(arg)
+	if !ok || len(list.Values) == 0 {
+		return 0, 0, newError("%s: expected a distribution or a non empty list, got %s", name, arg.Type())
+	}
+	sum, sumSq := 0.0, 0.0
+	for i, elem := range list.Values {
+		var x float64
+		switch elem := elem.(type) {
+		case *object.Float:
+			x = elem.Value
+		case *object.Boolean:
+			if elem.Value {
+				x = 1
+			}
+		default:
+			return 0, 0, newError("%s: element %d must be of type %s, got %s", name, i+1, object.FLOAT, elem.Type())
+		}
+		sum += x
+		sumSq += x * x
+	}
+	n := float64(len(list.Values))
+	mean = sum / n
+	return mean, math.Max(0, sumSq/n-mean*mean), nil
+}
+
+func nativeMean(ev *Evaluator, args ...object.Object) object.Object {
+	mean, _, err := moments("mean", args[0])
+	if err != nil {
+		return err
+	}
+	return newFloat(mean)
+}
+
+func nativeVariance(ev *Evaluator, args ...object.Object) object.Object {
+	_, variance, err := moments("variance", args[0])
+	if err != nil {
+		return err
+	}
+	return newFloat(variance)
+}
@@ -0,0 +1,59 @@
+package evaluator
+
+import (
+	"gocalc/testing_utils"
+	"testing"
+)
+
+func TestRandom(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"import \"random\"; random.seed(42); a = random.rand(); random.seed(42); random.rand() == a", "True"},
+		{"import \"random\"; import \"stats\"; random.seed(1); a = random.sample(stats.normal(), 3); random.seed(1); random.sample(stats.normal(), 3) == a", "[True, True, True]"},
+		{"import \"random\"; x = random.rand(); (x >= 0) && (x < 1)", "True"},
+		{"import \"random\"; x = random.randint(1, 6); (x >= 1) && (x <= 6)", "True"},
+		{"import \"random\"; random.randint(3, 3)", "3"},
+		{"import \"random\"; random.choice([7])", "7"},
+		{"import \"random\"; len(random.shuffle([1, 2, 3, 4]))", "4"},
+		{"import \"random\"; l = [1, 2, 3, 4]; random.shuffle(l); l", "[1, 2, 3, 4]"},
+		{"import \"stats\"; stats.normal()", "normal(0, 1)"},
+		{"import \"stats\"; typeof(stats.binomial(10, 0.5))", "Dist"},
+		{"import \"stats\"; stats.pdf(stats.uniform(2, 6), 3)", "0.25"},
+		{"import \"stats\"; stats.cdf(stats.normal(), 0)", "0.5"},
+		{"import \"stats\"; stats.cdf(stats.binomial(10, 0.5), [-1, 10])", "[0, 1]"},
+		{"import \"stats\"; stats.quantile(stats.exponential(2), 0)", "0"},
+		{"import \"stats\"; stats.pdf(stats.poisson(2), 0) == 1 / e^2", "True"},
+		{"import \"stats\"; stats.mean(stats.binomial(20, 0.3))", "6"},
+		{"import \"stats\"; stats.variance(stats.uniform(0, 6))", "3"},
+		{"import \"stats\"; stats.mean([1, 2, 3, 6])", "3"},
+		{"import \"stats\"; stats.variance([1, 3])", "1"},
+		{"import \"stats\"; stats.mean([true, false, false, true])", "0.5"},
+		{"import \"random\"; import \"stats\"; len(random.sample(stats.poisson(4), 100))", "100"},
+		{"import \"random\"; import \"stats\"; random.seed(7); xs = random.sample(stats.uniform(), 4000); ys = random.sample(stats.uniform(), 4000); p = 4 * stats.mean(xs^2 + ys^2 < 1); (p > 3) && (p < 3.3)", "True"},
+		{"import \"random\"; import \"stats\"; random.seed(7); m = stats.mean(random.sample(stats.normal(10, 2), 4000)); (m > 9.8) && (m < 10.2)", "True"},
+		{"import \"random\"; random.randint(2, 1)", "randint: the bounds must be integers a <= b, got 2 and 1"},
+		{"import \"random\"; random.randint(1.5, 2)", "randint: the bounds must be integers a <= b, got 1.5 and 2"},
+		{"import \"random\"; random.choice([])", "choice: the list is empty"},
+		{"import \"random\"; random.choice(1)", "Argument 1 of choice must be of type List, got Float"},
+		{"import \"random\"; random.seed(0.5)", "seed: the seed must be an integer, got 0.5"},
+		{"import \"stats\"; stats.normal(0, -1)", "normal: invalid parameters: the standard deviation must be positive, got -1"},
+		{"import \"stats\"; stats.binomial(10)", "binomial expects arguments (Float, Float), got 1"},
+		{"import \"stats\"; stats.quantile(stats.normal(), 2)", "quantile: the probability must be between 0 and 1, got 2"},
+		{"import \"random\"; import \"stats\"; random.sample(stats.normal(), -1)", "sample: the number of values must be a natural number, got -1"},
+		{"import \"random\"; import \"stats\"; random.sample(stats.normal(), 1e18)", "Cannot allocate 1e+18 elements (> 67108864)"},
+		{"import \"stats\"; stats.mean([])", "mean: expected a distribution or a non empty list, got List"},
+		{"import \"stats\"; stats.pdf(1, 1)", "Argument 1 of pdf must be of type Dist, got Float"},
+	}
+
+	for _, tt := range tests {
+		res := testEval(tt.input)
+		testingutils.Assert(t, res != nil, "%s: no result", tt.input)
+		testingutils.Equals(t, tt.expected, res.String(), tt.input)
+	}
+
+	ev := New()
+	ev.SetLimits(Limits{MaxListLen: 10})
+	testingutils.Equals(t, "Maximum list length exceeded (11 > 10)", ev.Eval(`import "random"; import "stats"; random.sample(stats.normal(), 11)`).String(), "sample limit")
+}
@@ -0,0 +1,72 @@
+package evaluator
+
+import (
+	"gocalc/ast"
+	"gocalc/object"
+	"strings"
+)
+
+func (ev *Evaluator) TypeStatement(ts *ast.TypeStatement) object.Object {
+	return ev.assign(ts.Name.Value, object.NewRecordType(ts.Name.Value, ts.FieldNames()...), nil)
+}
+
+func isRecord(obj object.Object) bool {
+	return obj.Type() == object.RECORD
+}
+
+// newRecord calls the constructor of a record type, taking the fields in the
+// order they are declared
+func newRecord(rt *object.RecordType, args []object.Object) object.Object {
+	if err, ok := getError(args); !ok {
+		return err
+	}
+	if len(args) != len(rt.Fields) {
+		return newError(object.WRONG_ARGUMENTS_ERROR, rt.Name, "("+strings.Join(rt.Fields, ", ")+")", len(args))
+	}
+
+	return &object.Record{Of: rt, Values: append([]object.Object{}, args...)}
+}
+
+func evalInfixExpressionRecord(operator string, left, right object.Object) object.Object {
+	if isRecord(left) && isRecord(right) {
+		switch operator {
+		case "==":
+			return newBool(equalValues(left, right))
+		case "!=":
+			return newBool(!equalValues(left, right))
+		}
+	}
+
+	return newError(object.UNKNOWN_INFIX_OPERATOR_ERROR, left.Type(), operator, right.Type())
+}
+
+// equalValues reports whether two values are the same. Lists and records are
+// compared element by element, and records must also be of the same type
+func equalValues(a, b object.Object) bool {
+	switch a := a.(type) {
+	case *object.Record:
+		b, ok := b.(*object.Record)
+		return ok && a.Of == b.Of && equalObjects(a.Values, b.Values)
+	case *object.List:
+		b, ok := b.(*object.List)
+		return ok && equalObjects(a.Values, b.Values)
+	}
+
+	if a.Type() != b.Type() {
+		return false
+	}
+	res, ok := evalInfixExpression("==", a, b).(*object.Boolean)
+	return ok && res.Value
+}
+
+func equalObjects(a, b []object.Object) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !equalValues(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,43 @@
+package evaluator
+
+import (
+	"gocalc/testing_utils"
+	"testing"
+)
+
+func TestRecords(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"type Point = {x, y}; p = Point(1, 2); p", "Point{x: 1, y: 2}"},
+		{"type Point = {x, y}; p = Point(1, 2); p.x + p.y", "3"},
+		{"type Point = {x, y}; typeof(Point(1, 2))", "Point"},
+		{"type Point = {x, y}; Point", "Point"},
+		{"type Point = {x, y}; typeof(Point)", "Type"},
+		{"type Point = {x, y}; Point(1, 2) == Point(1, 2)", "True"},
+		{"type Point = {x, y}; Point(1, 2) != Point(2, 1)", "True"},
+		{"type P = {x}; type Q = {x}; P(1) == Q(1)", "False"},
+		{`type Line = {from, to, label}; type P = {x}; Line(P(0), P(1), "a") == Line(P(0), P(1), "a")`, "True"},
+		{"type Poly = {coefs}; Poly([1, 2]) == Poly([1, 2, 3])", "False"},
+		{"type Box = {inner}; type P = {x, y}; b = Box(P(1, 2)); b.inner.y", "2"},
+		{"type Pair = {a, b}; ps = [Pair(1, 2), Pair(3, 4)]; ps == Pair(1, 2)", "[True, False]"},
+		{"type Unit = {}; Unit()", "Unit{}"},
+		{"type Point = {x, y}; Point(1)", "Point expects arguments (x, y), got 1"},
+		{"type Point = {x, y}; Point(1, nope)", "Identifier not found nope"},
+		{"type Point = {x, y}; Point(1, 2).z", "Point has no member z"},
+		{"type Point = {x, y}; Point(1, 2) + 1", "Unknown operator Record + Float"},
+		{"type Point = {x, y}; Point(1, 2) < Point(1, 2)", "Unknown operator Record < Record"},
+	}
+
+	for _, tt := range tests {
+		res := testEval(tt.input)
+		testingutils.Assert(t, res != nil, "%s: no result", tt.input)
+		testingutils.Equals(t, tt.expected, res.String(), tt.input)
+	}
+
+	ev := New()
+	v, err := ev.Evaluate("type Point = {x, y}; Point(1, true)")
+	testingutils.Assert(t, err == nil, "Evaluate: %s", err)
+	testingutils.Equals(t, map[string]interface{}{"x": 1.0, "y": true}, v, "record as a Go value")
+}
@@ -0,0 +1,54 @@
+package evaluator
+
+import (
+	"gocalc/object"
+	"gocalc/types"
+	"strings"
+	"unicode/utf8"
+)
+
+// stringFunction creates a native transforming a string
+func stringFunction(name string, fn func(string) string) *NativeFunction {
+	return newTypedFunction(func(ev *Evaluator, args ...object.Object) object.Object {
+		return object.NewString(fn(args[0].(*object.String).Value))
+	}, name, object.STRING).returns(types.Str)
+}
+
+// stringPredicate creates a native testing a string against another one
+func stringPredicate(name string, fn func(s, t string) bool) *NativeFunction {
+	return newTypedFunction(func(ev *Evaluator, args ...object.Object) object.Object {
+		return newBool(fn(args[0].(*object.String).Value, args[1].(*object.String).Value))
+	}, name, object.STRING, object.STRING).returns(types.Bool)
+}
+
+// nativeStringLen counts characters rather than bytes
+func nativeStringLen(ev *Evaluator, args ...object.Object) object.Object {
+	return newFloat(float64(utf8.RuneCountInString(args[0].(*object.String).Value)))
+}
+
+func nativeSplit(ev *Evaluator, args ...object.Object) object.Object {
+	parts := strings.Split(args[0].(*object.String).Value, args[1].(*object.String).Value)
+	values := make([]object.Object, len(parts))
+	for i, p := range parts {
+		values[i] = object.NewString(p)
+	}
+	return &object.List{Values: values}
+}
+
+func nativeJoin(ev *Evaluator, args ...object.Object) object.Object {
+	list := args[0].(*object.List)
+	parts := make([]string, len(list.Values))
+	for i, v := range list.Values {
+		s, ok := v.(*object.String)
+		if !ok {
+			return newError("join: element %d must be of type %s, got %s", i+1, object.STRING, v.Type())
+		}
+		parts[i] = s.Value
+	}
+	return object.NewString(strings.Join(parts, args[1].(*object.String).Value))
+}
+
+func nativeReplace(ev *Evaluator, args ...object.Object) object.Object {
+	s, old, new := args[0].(*object.String).Value, args[1].(*object.String).Value, args[2].(*object.String).Value
+	return object.NewString(strings.ReplaceAll(s, old, new))
+}
@@ -0,0 +1,262 @@
+package evaluator
+
+import (
+	"gocalc/ast"
+	"gocalc/calendar"
+	"gocalc/object"
+	"math"
+	"time"
+)
+
+// SetClock replaces the clock used by now, time.Now when nil
+func (ev *Evaluator) SetClock(clock func() time.Time) { ev.clock = clock }
+
+func (ev *Evaluator) now() time.Time {
+	if ev.clock == nil {
+		return time.Now()
+	}
+	return ev.clock()
+}
+
+func (ev *Evaluator) StringLiteral(sl *ast.StringLiteral) object.Object {
+	return &object.String{Value: sl.Value}
+}
+
+func (ev *Evaluator) DurationLiteral(dl *ast.DurationLiteral) object.Object {
+	return &object.Duration{Value: dl.Value}
+}
+
+func isTime(obj object.Object) bool {
+	return obj.Type() == object.TIME || obj.Type() == object.DURATION
+}
+
+func isString(obj object.Object) bool {
+	return obj.Type() == object.STRING
+}
+
+func newTime(t time.Time) *object.Time { return &object.Time{Value: t} }
+
+func newDuration(d time.Duration) *object.Duration { return &object.Duration{Value: d} }
+
+func evalInfixExpressionTime(operator string, left, right object.Object) object.Object {
+	switch l := left.(type) {
+	case *object.Time:
+		switch r := right.(type) {
+		case *object.Duration:
+			switch operator {
+			case "+":
+				return newTime(calendar.Add(l.Value, r.Value))
+			case "-":
+				return newTime(calendar.Add(l.Value, -r.Value))
+			}
+		case *object.Time:
+			if operator == "-" {
+				return newDuration(l.Value.Sub(r.Value))
+			}
+			if res, ok := compare(operator, sign(l.Value.Before(r.Value), l.Value.After(r.Value))); ok {
+				return newBool(res)
+			}
+		}
+	case *object.Duration:
+		switch r := right.(type) {
+		case *object.Time:
+			if operator == "+" {
+				return newTime(calendar.Add(r.Value, l.Value))
+			}
+		case *object.Duration:
+			switch operator {
+			case "+":
+				return newDuration(l.Value + r.Value)
+			case "-":
+				return newDuration(l.Value - r.Value)
+			case "/":
+				if r.Value == 0 {
+					return object.DivideByZeroError(left, right)
+				}
+				return newFloat(float64(l.Value) / float64(r.Value))
+			}
+			if res, ok := compare(operator, sign(l.Value < r.Value, l.Value > r.Value)); ok {
+				return newBool(res)
+			}
+		case *object.Float:
+			switch operator {
+			case "*":
+				return scaleDuration(l.Value, r.Value)
+			case "/":
+				if r.Value == 0 {
+					return object.DivideByZeroError(left, right)
+				}
+				return scaleDuration(l.Value, 1/r.Value)
+			}
+		}
+	case *object.Float:
+		if r, ok := right.(*object.Duration); ok && operator == "*" {
+			return scaleDuration(r.Value, l.Value)
+		}
+	}
+
+	return newError(object.UNKNOWN_INFIX_OPERATOR_ERROR, left.Type(), operator, right.Type())
+}
+
+func scaleDuration(d time.Duration, factor float64) object.Object {
+	return newDuration(time.Duration(math.Round(float64(d) * factor)))
+}
+
+func evalPrefixExpressionTime(operator string, right object.Object) object.Object {
+	if d, ok := right.(*object.Duration); ok && operator == "-" {
+		return newDuration(-d.Value)
+	}
+	return newError(object.UNKNOWN_PREFIX_OPERATOR_ERROR, operator, right.Type())
+}
+
+func evalInfixExpressionString(operator string, left, right object.Object) object.Object {
+	s1, s2 := left.(*object.String).Value, right.(*object.String).Value
+
+	if operator == "+" {
+		return &object.String{Value: s1 + s2}
+	}
+	if res, ok := compare(operator, sign(s1 < s2, s1 > s2)); ok {
+		return newBool(res)
+	}
+
+	return newError(object.UNKNOWN_INFIX_OPERATOR_ERROR, left.Type(), operator, right.Type())
+}
+
+func sign(less, greater bool) int {
+	switch {
+	case less:
+		return -1
+	case greater:
+		return 1
+	}
+	return 0
+}
+
+// compare applies a comparison operator to the result of a three way comparison
+func compare(operator string, c int) (bool, bool) {
+	switch operator {
+	case "==":
+		return c == 0, true
+	case "!=":
+		return c != 0, true
+	case "<":
+		return c < 0, true
+	case "<=":
+		return c <= 0, true
+	case ">":
+		return c > 0, true
+	case ">=":
+		return c >= 0, true
+	}
+	return false, false
+}
+
+func location(name string) (*time.Location, *object.Error) {
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, newError("unknown time zone %q", name)
+	}
+	return loc, nil
+}
+
+// nativeDate builds a time from a string and an optional time zone, or from a year, month and day
+func nativeDate(ev *Evaluator, args ...object.Object) object.Object {
+	if err, ok := getError(args); !ok {
+		return err
+	}
+
+	switch {
+	case len(args) == 3 && isFloat(args[0]) && isFloat(args[1]) && isFloat(args[2]):
+		y, m, d := args[0].(*object.Float).Value, args[1].(*object.Float).Value, args[2].(*object.Float).Value
+		if y != math.Trunc(y) || m != math.Trunc(m) || d != math.Trunc(d) {
+			return newError("date: the year, month and day must be integers, got %s, %s, %s", args[0], args[1], args[2])
+		}
+		return newTime(time.Date(int(y), time.Month(m), int(d), 0, 0, 0, 0, time.UTC))
+
+	case (len(args) == 1 || len(args) == 2) && isString(args[0]):
+		loc := time.UTC
+		if len(args) == 2 {
+			name, ok := args[1].(*object.String)
+			if !ok {
+				return newError(object.ARGUMENT_TYPE_ERROR, 2, "date", object.STRING, args[1].Type())
+			}
+			var err *object.Error
+			if loc, err = location(name.Value); err != nil {
+				return err
+			}
+		}
+		t, err := calendar.ParseTime(args[0].(*object.String).Value, loc)
+		if err != nil {
+			return newError("date: %s", err)
+		}
+		return newTime(t)
+	}
+
+	return newError(object.WRONG_ARGUMENTS_ERROR, "date", "(Str), (Str, Str) or (Float, Float, Float)", len(args))
+}
+
+func nativeNow(ev *Evaluator, args ...object.Object) object.Object {
+	return newTime(ev.now())
+}
+
+func nativeDuration(ev *Evaluator, args ...object.Object) object.Object {
+	d, err := calendar.ParseDuration(args[0].(*object.String).Value)
+	if err != nil {
+		return newError("duration: %s", err)
+	}
+	return newDuration(d)
+}
+
+func nativeWeekday(ev *Evaluator, args ...object.Object) object.Object {
+	return &object.String{Value: args[0].(*object.Time).Value.Weekday().String()}
+}
+
+func nativeIsWeekend(ev *Evaluator, args ...object.Object) object.Object {
+	return newBool(calendar.IsWeekend(args[0].(*object.Time).Value))
+}
+
+func nativeBusinessDays(ev *Evaluator, args ...object.Object) object.Object {
+	start, end := args[0].(*object.Time).Value, args[1].(*object.Time).Value
+	return newFloat(float64(calendar.BusinessDays(start, end)))
+}
+
+func nativeAddBusinessDays(ev *Evaluator, args ...object.Object) object.Object {
+	n := args[1].(*object.Float).Value
+	if n != math.Trunc(n) {
+		return newError("addbusinessdays: the number of days must be an integer, got %s", args[1])
+	}
+	if math.Abs(n) > calendar.MaxBusinessDays {
+		return newError("addbusinessdays: the number of days must be between -%d and %d, got %s", calendar.MaxBusinessDays, calendar.MaxBusinessDays, args[1])
+	}
+	return newTime(calendar.AddBusinessDays(args[0].(*object.Time).Value, int(n)))
+}
+
+func nativeTimezone(ev *Evaluator, args ...object.Object) object.Object {
+	loc, err := location(args[1].(*object.String).Value)
+	if err != nil {
+		return err
+	}
+	return newTime(args[0].(*object.Time).Value.In(loc))
+}
+
+func nativeFormat(ev *Evaluator, args ...object.Object) object.Object {
+	s, err := calendar.Format(args[0].(*object.Time).Value, args[1].(*object.String).Value)
+	if err != nil {
+		return newError("format: %s", err)
+	}
+	return &object.String{Value: s}
+}
+
+// timePart creates a native returning a component of a time
+func timePart(name string, part func(time.Time) int) *NativeFunction {
+	return newTypedFunction(func(ev *Evaluator, args ...object.Object) object.Object {
+		return newFloat(float64(part(args[0].(*object.Time).Value)))
+	}, name, object.TIME)
+}
+
+// durationIn creates a native returning a duration as a number of units
+func durationIn(name string, unit time.Duration) *NativeFunction {
+	return newTypedFunction(func(ev *Evaluator, args ...object.Object) object.Object {
+		return newFloat(float64(args[0].(*object.Duration).Value) / float64(unit))
+	}, name, object.DURATION)
+}
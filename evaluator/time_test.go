@@ -0,0 +1,71 @@
+package evaluator
+
+import (
+	"gocalc/testing_utils"
+	"testing"
+	"time"
+)
+
+func TestTime(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`import "time"; time.date("2026-10-18")`, "2026-10-18"},
+		{`import "time"; time.date(2026, 10, 18) == time.date("2026-10-18")`, "True"},
+		{`import "time"; time.date("2026-10-18 14:30")`, "2026-10-18 14:30:00"},
+		{`import "time"; time.date("2026-10-18") + 90d`, "2027-01-16"},
+		{`import "time"; 90d + time.date("2026-10-18")`, "2027-01-16"},
+		{`import "time"; time.date("2026-10-18") - 1w`, "2026-10-11"},
+		{`import "time"; time.date("2026-12-25") - time.date("2026-10-18")`, "68d"},
+		{`import "time"; time.date("2026-10-18") < time.date("2026-10-19")`, "True"},
+		{`import "time"; time.now()`, "2026-10-18 09:15:00"},
+		{`import "time"; time.now() - time.date("2026-10-18")`, "9h 15m"},
+		{"3d 4h", "3d 4h"},
+		{"3d 4h + 20h", "4d"},
+		{"-1h 30m", "-1h 30m"},
+		{"2 * 1h 30m", "3h"},
+		{"1h / 4", "15m"},
+		{"1d / 1h", "24"},
+		{"1h > 59m", "True"},
+		{"import \"time\"; time.hours(1d 12h)", "36"},
+		{"import \"time\"; time.days(time.date(\"2027-01-01\") - time.date(\"2026-01-01\"))", "365"},
+		{`import "time"; time.duration("2h 30m") == 150m`, "True"},
+		{`import "time"; time.weekday(time.date("2026-10-18"))`, "Sunday"},
+		{`import "time"; time.isweekend(time.date("2026-10-18"))`, "True"},
+		{`import "time"; time.businessdays(time.date("2026-10-19"), time.date("2026-11-02"))`, "10"},
+		{`import "time"; time.addbusinessdays(time.date("2026-10-16"), 1)`, "2026-10-19"},
+		{`import "time"; time.addbusinessdays(time.date("2026-10-19"), -1)`, "2026-10-16"},
+		{`import "time"; time.addbusinessdays(time.date("2026-01-01"), -2600000)`, "-7940-01-08"},
+		{`import "time"; time.tz(time.date("2026-10-18 12:00"), "Europe/Madrid")`, "2026-10-18 14:00:00 CEST"},
+		{`import "time"; time.date("2026-10-18 12:00", "America/New_York") - time.date("2026-10-18 12:00")`, "4h"},
+		{`import "time"; time.date("2026-03-28 12:00", "Europe/Madrid") + 1d`, "2026-03-29 12:00:00 CEST"},
+		{`import "time"; time.format(time.date("2026-10-18"), "%A %d %B %Y")`, "Sunday 18 October 2026"},
+		{`import "time"; time.year(time.date("2026-10-18")) + time.month(time.date("2026-10-18")) + time.day(time.date("2026-10-18"))`, "2054"},
+		{`typeof(1h)`, "Duration"},
+		{`import "time"; typeof(time.now())`, "Time"},
+		{`"foo" + "bar"`, "foobar"},
+		{`"a" < "b"`, "True"},
+		{`"say \"hi\""`, `say "hi"`},
+		{`import "time"; time.date("18/10/2026")`, `date: invalid date "18/10/2026", expected a date like 2026-10-18`},
+		{`import "time"; time.date(2026, 10.5, 1)`, "date: the year, month and day must be integers, got 2026, 10.5, 1"},
+		{`import "time"; time.addbusinessdays(time.date("2026-01-01"), 1e15)`, "addbusinessdays: the number of days must be between -2600000 and 2600000, got 1e+15"},
+		{`import "time"; time.addbusinessdays(time.date("2026-01-01"), 1.5)`, "addbusinessdays: the number of days must be an integer, got 1.5"},
+		{`import "time"; time.tz(time.now(), "Mars/Olympus")`, `unknown time zone "Mars/Olympus"`},
+		{`import "time"; time.format(time.now(), "%Q")`, `format: unknown directive %Q in format "%Q"`},
+		{`1h / 0`, "Cannot divide by zero (1h / 0)"},
+		{`import "time"; time.now() + time.now()`, "Unknown operator Time + Time"},
+		{`1h + 1`, "Unknown operator Duration + Float"},
+		{`import "time"; -time.now()`, "Unknown operator -Time"},
+		{`import "time"; time.weekday(1)`, "Argument 1 of weekday must be of type Time, got Float"},
+	}
+
+	clock := func() time.Time { return time.Date(2026, 10, 18, 9, 15, 0, 0, time.UTC) }
+	for _, tt := range tests {
+		ev := New()
+		ev.SetClock(clock)
+		res := ev.Eval(tt.input)
+		testingutils.Assert(t, res != nil, "%s: no result", tt.input)
+		testingutils.Equals(t, tt.expected, res.String(), tt.input)
+	}
+}
@@ -0,0 +1,110 @@
+package evaluator
+
+import (
+	"gocalc/object"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// typeConstants are the builtin types bound to their names, as in is(x, Float)
+var typeConstants = []object.ObjectType{
+	object.FLOAT, object.BOOLEAN, object.STRING, object.LIST, object.VECTOR, object.MATRIX,
+	object.TIME, object.DURATION, object.EXPRESSION, object.DISTRIBUTION, object.TYPE,
+	object.RECORD, object.MODULE, object.NATIVE_FUNCTION, object.ANY,
+}
+
+func isType(obj object.Object) bool {
+	return obj.Type() == object.TYPE
+}
+
+func evalInfixExpressionType(operator string, left, right object.Object) object.Object {
+	l, r := left.(*object.Type), right.(*object.Type)
+	same := l.Value == r.Value && l.Record == r.Record
+
+	switch operator {
+	case "==":
+		return newBool(same)
+	case "!=":
+		return newBool(!same)
+	}
+	return newError(object.UNKNOWN_INFIX_OPERATOR_ERROR, left.Type(), operator, right.Type())
+}
+
+func conversionError(obj object.Object, to object.ObjectType) object.Object {
+	if s, ok := obj.(*object.String); ok {
+		return newError(object.CONVERSION_ERROR, strconv.Quote(s.Value), to)
+	}
+	return newError(object.CONVERSION_ERROR, obj.Type(), to)
+}
+
+func nativeFloat(ev *Evaluator, args ...object.Object) object.Object {
+	switch arg := args[0].(type) {
+	case *object.Float:
+		return arg
+	case *object.Boolean:
+		if arg.Value {
+			return newFloat(1)
+		}
+		return newFloat(0)
+	case *object.String:
+		if f, err := strconv.ParseFloat(strings.TrimSpace(arg.Value), 64); err == nil {
+			return newFloat(f)
+		}
+	}
+	return conversionError(args[0], object.FLOAT)
+}
+
+// nativeInt truncates towards zero, the result is still a Float
+func nativeInt(ev *Evaluator, args ...object.Object) object.Object {
+	f := nativeFloat(ev, args...)
+	if isError(f) {
+		return f
+	}
+	return newFloat(math.Trunc(f.(*object.Float).Value))
+}
+
+func nativeStr(ev *Evaluator, args ...object.Object) object.Object {
+	if s, ok := args[0].(*object.String); ok {
+		return s
+	}
+	return object.NewString(args[0].String())
+}
+
+func nativeBool(ev *Evaluator, args ...object.Object) object.Object {
+	switch arg := args[0].(type) {
+	case *object.Boolean:
+		return arg
+	case *object.Float:
+		return newBool(arg.Value != 0)
+	case *object.String:
+		switch strings.ToLower(strings.TrimSpace(arg.Value)) {
+		case "true":
+			return newBool(true)
+		case "false":
+			return newBool(false)
+		}
+	}
+	return conversionError(args[0], object.BOOLEAN)
+}
+
+// nativeList splits strings into characters and vectors and matrices into
+// their elements and rows
+func nativeList(ev *Evaluator, args ...object.Object) object.Object {
+	if s, ok := args[0].(*object.String); ok {
+		runes := []rune(s.Value)
+		values := make([]object.Object, len(runes))
+		for i, r := range runes {
+			values[i] = object.NewString(string(r))
+		}
+		return &object.List{Values: values}
+	}
+	if l, ok := asList(args[0]); ok {
+		return l
+	}
+	return conversionError(args[0], object.LIST)
+}
+
+func nativeIs(ev *Evaluator, args ...object.Object) object.Object {
+	return newBool(args[1].(*object.Type).Matches(args[0]))
+}
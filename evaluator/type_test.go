@@ -0,0 +1,55 @@
+package evaluator
+
+import (
+	"gocalc/testing_utils"
+	"testing"
+)
+
+func TestTypes(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"typeof(1) == typeof(2)", "True"},
+		{"typeof(1) == Float", "True"},
+		{`typeof("a") != Float`, "True"},
+		{"typeof([1, true]) == List", "True"},
+		{"type Point = {x, y}; typeof(Point(1, 2)) == Point", "True"},
+		{"type P = {x}; type Q = {x}; P == Q", "False"},
+		{"Float == 1", "Unknown operator Type == Float"},
+		{"Float < Bool", "Unknown operator Type < Type"},
+		{"[Float, Str] == Float", "[True, False]"},
+		{`float("3.2")`, "3.2"},
+		{`float(" 1e3 ")`, "1000"},
+		{"float(true)", "1"},
+		{`float("abc")`, `Cannot convert "abc" to Float`},
+		{"import \"time\"; float(time.now())", "Cannot convert Time to Float"},
+		{"int(3.7)", "3"},
+		{"int(-3.7)", "-3"},
+		{`int("42.9")`, "42"},
+		{"str(1.5)", "1.5"},
+		{"str([1, 2]) + \"!\"", "[1, 2]!"},
+		{`str("a")`, "a"},
+		{"bool(0)", "False"},
+		{"bool(2)", "True"},
+		{`bool("True")`, "True"},
+		{`bool("yes")`, `Cannot convert "yes" to Bool`},
+		{`list("héllo")`, "[h, é, l, l, o]"},
+		{"import \"linalg\"; list(linalg.vector([1, 2]))", "[1, 2]"},
+		{"len(list([[1, 2], [3, 4]]))", "2"},
+		{"list(1)", "Cannot convert Float to List"},
+		{"is(1, Float)", "True"},
+		{`is("a", Float)`, "False"},
+		{"is(true, Any)", "True"},
+		{"type Point = {x, y}; is(Point(1, 2), Point)", "True"},
+		{"type Point = {x, y}; is(Point(1, 2), Record)", "True"},
+		{"is(1, 2)", "Argument 2 of is must be of type Type, got Float"},
+		{"x: Float = float(\"2\"); x", "2"},
+	}
+
+	for _, tt := range tests {
+		res := testEval(tt.input)
+		testingutils.Assert(t, res != nil, "%s: no result", tt.input)
+		testingutils.Equals(t, tt.expected, res.String(), tt.input)
+	}
+}
@@ -0,0 +1,26 @@
+package evaluator
+
+import (
+	"gocalc/testing_utils"
+	"testing"
+)
+
+func TestUnicodeIdentifiers(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"θ = 30; θ * 2", "60"},
+		{"Δx = 0.5; α_2 = 4; Δx * α_2", "2"},
+		{`s = "héllo, 世界"; s`, "héllo, 世界"},
+		{`"ça" + " va"`, "ça va"},
+		{"ω", "Identifier not found ω"},
+		{"θ: Float = 1; θ", "1"},
+	}
+
+	for _, tt := range tests {
+		res := testEval(tt.input)
+		testingutils.Assert(t, res != nil, "%s: no result", tt.input)
+		testingutils.Equals(t, tt.expected, res.String(), tt.input)
+	}
+}
@@ -0,0 +1,226 @@
+package evaluator
+
+import (
+	"gocalc/code"
+	"gocalc/compiler"
+	"gocalc/object"
+)
+
+// Engine selects how programs are executed
+type Engine byte
+
+const (
+	TreeWalker Engine = iota // visits the AST directly
+	VM                       // compiles the AST to bytecode run by a stack machine
+)
+
+var engineNames = []string{
+	TreeWalker: "tree",
+	VM:         "vm",
+}
+
+func (e Engine) String() string { return engineNames[e] }
+
+// ParseEngine returns the engine with the given name
+func ParseEngine(name string) (Engine, bool) {
+	for e, n := range engineNames {
+		if n == name {
+			return Engine(e), true
+		}
+	}
+	return TreeWalker, false
+}
+
+// DefaultEngine is the engine used by evaluators created with New and NewWithLibraries
+var DefaultEngine = TreeWalker
+
+func (ev *Evaluator) SetEngine(engine Engine) { ev.engine = engine }
+
+func (ev *Evaluator) Engine() Engine { return ev.engine }
+
+// checkEvery is the number of instructions executed between context checks
+const checkEvery = 256
+
+var (
+	vmTrue  = &object.Boolean{Value: true}
+	vmFalse = &object.Boolean{Value: false}
+)
+
+// runBytecode executes bc with the environments and limits of ev.
+// It produces the same results as visiting the compiled program
+func (ev *Evaluator) runBytecode(bc *compiler.Bytecode) object.Object {
+	if max := ev.limits.MaxDepth; max > 0 && bc.MaxDepth > max {
+		return newErrorKind(object.ERR_DEPTH_LIMIT, object.DEPTH_LIMIT_ERROR, max)
+	}
+
+	var result object.Object
+	stack := make([]object.Object, 0, 64)
+	pop := func() object.Object {
+		obj := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		return obj
+	}
+
+	ins := bc.Instructions
+	for ip, executed := 0, 0; ip < len(ins); ip, executed = ip+1, executed+1 {
+		if executed%checkEvery == 0 {
+			if err := ev.checkContext(); err != nil {
+				return err
+			}
+		}
+
+		op := code.Opcode(ins[ip])
+		switch op {
+		case code.OpConstant:
+			idx := code.ReadUint16(ins[ip+1:])
+			ip += 2
+			// the constants are pooled but count as allocations, as literals do
+			// in the tree walker
+			stack = append(stack, ev.track(bc.Constants[idx]))
+
+		case code.OpTrue:
+			stack = append(stack, ev.track(vmTrue))
+
+		case code.OpFalse:
+			stack = append(stack, ev.track(vmFalse))
+
+		case code.OpGetGlobal:
+			name := bc.Names[code.ReadUint16(ins[ip+1:])]
+			ip += 2
+			stack = append(stack, ev.lookup(name))
+
+		case code.OpSetGlobal:
+			name := bc.Names[code.ReadUint16(ins[ip+1:])]
+			ip += 2
+			val := pop()
+			if isError(val) {
+				return val
+			}
+			if err := ev.assign(name, val, nil); err != nil {
+				return err
+			}
+			result = nil
+
+		case code.OpDeclare:
+			decl, name := bc.Declarations[code.ReadUint16(ins[ip+1:])], bc.Names[code.ReadUint16(ins[ip+3:])]
+			ip += 4
+			val := pop()
+			if isError(val) {
+				return val
+			}
+			if err := ev.assign(name, val, decl); err != nil {
+				return err
+			}
+			result = nil
+
+		case code.OpImport:
+			is := bc.Imports[code.ReadUint16(ins[ip+1:])]
+			ip += 2
+			mod := ev.importModule(is.Path)
+			if isError(mod) {
+				return mod
+			}
+			if err := ev.assign(is.Name(), mod, nil); err != nil {
+				return err
+			}
+			result = nil
+
+		case code.OpMember:
+			name := bc.Names[code.ReadUint16(ins[ip+1:])]
+			ip += 2
+			obj := pop()
+			if !isError(obj) {
+				obj = ev.track(member(obj, name))
+			}
+			stack = append(stack, obj)
+
+		case code.OpDestructure:
+			ds := bc.Destructurings[code.ReadUint16(ins[ip+1:])]
+			ip += 2
+			values := append([]object.Object{}, stack[len(stack)-len(ds.Values):]...)
+			stack = stack[:len(stack)-len(ds.Values)]
+			if err := ev.destructure(ds, values); err != nil {
+				return err
+			}
+			result = nil
+
+		case code.OpResult:
+			result = ev.track(pop())
+			if isError(result) {
+				return result
+			}
+
+		case code.OpMinus, code.OpBang:
+			r := pop()
+			if !isError(r) {
+				r = ev.track(evalPrefixExpression(code.Operators[op], r))
+			}
+			stack = append(stack, r)
+
+		case code.OpList:
+			n := int(code.ReadUint16(ins[ip+1:]))
+			ip += 2
+			values := collapseErrors(stack[len(stack)-n:])
+			stack = stack[:len(stack)-n]
+			stack = append(stack, ev.track(newList(values)))
+
+		case code.OpCallQuoted:
+			site := bc.Calls[code.ReadUint16(ins[ip+1:])]
+			end := int(code.ReadUint16(ins[ip+3:]))
+			ip += 4
+			if nf, ok := stack[len(stack)-1].(*NativeFunction); ok && nf.Quoted {
+				stack[len(stack)-1] = ev.track(nf.call(ev, quote(site.Arguments)))
+				ip = end - 1
+			}
+
+		case code.OpCall:
+			n := int(ins[ip+1])
+			ip++
+			args := collapseErrors(stack[len(stack)-n:])
+			fn := stack[len(stack)-n-1]
+			stack = stack[:len(stack)-n-1]
+			if !isError(fn) {
+				fn = ev.track(ev.applyFunction(fn, args))
+			}
+			stack = append(stack, fn)
+
+		case code.OpQuote:
+			quoted := bc.Quotes[code.ReadUint16(ins[ip+1:])]
+			ip += 2
+			stack = append(stack, ev.track(&Expression{Node: quoted.Expression}))
+
+		default:
+			operator, ok := code.Operators[op]
+			if !ok {
+				return newError("Unknown opcode %d", op)
+			}
+			l := pop()
+			r := pop()
+			var res object.Object
+			switch {
+			case isError(r):
+				res = r
+			case isError(l):
+				res = l
+			default:
+				res = ev.track(evalInfixExpression(operator, l, r))
+			}
+			stack = append(stack, res)
+		}
+	}
+
+	return result
+}
+
+// collapseErrors copies values, keeping only the first error if there is one,
+// the same way evalExpressions does
+func collapseErrors(values []object.Object) []object.Object {
+	for _, v := range values {
+		if isError(v) {
+			return []object.Object{v}
+		}
+	}
+
+	var res []object.Object
+	return append(res, values...)
+}
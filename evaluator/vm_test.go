@@ -0,0 +1,61 @@
+package evaluator
+
+import (
+	"gocalc/testing_utils"
+	"os"
+	"testing"
+)
+
+// TestMain runs every test of the package once per engine
+func TestMain(m *testing.M) {
+	for _, engine := range []Engine{TreeWalker, VM} {
+		DefaultEngine = engine
+		if code := m.Run(); code != 0 {
+			os.Exit(code)
+		}
+	}
+	os.Exit(0)
+}
+
+func TestEnginesAgree(t *testing.T) {
+	inputs := []string{
+		"1 + 2 * 3 - 4 / 5 ^ 2",
+		"a = 2; b = a * a; [a, b, a == b, !(a > b)]",
+		"a = 1; a = a + 1; a",
+		"x = 5",
+		"-true",
+		"1 / 0 + nope",
+		"[1, 1 / 0, nope]",
+		"len([1, 2, 3]) + sin(0)",
+		"typeof(1 / 0)",
+		"get([1, true], 5)",
+		"pi(1, 2)",
+		"true && 1",
+		`import "symbolic"; symbolic.diff(sin(x) * len([x]), x)`,
+		`import "symbolic"; [symbolic.diff(x ^ 2, x), sqrt(symbolic.diff(4 * x, x))]`,
+		`import "stats"; stats.mean([1, 2]) + stats.nope`,
+		`import "strings"; strings.upper("a").b`,
+		"",
+	}
+
+	for _, input := range inputs {
+		tree, vm := New(), New()
+		tree.SetEngine(TreeWalker)
+		vm.SetEngine(VM)
+
+		expected, actual := tree.Eval(input), vm.Eval(input)
+		if expected == nil || actual == nil {
+			testingutils.Equals(t, expected, actual, input)
+			continue
+		}
+		testingutils.Equals(t, expected.Type(), actual.Type(), input)
+		testingutils.Equals(t, expected.String(), actual.String(), input)
+	}
+}
+
+func TestParseEngine(t *testing.T) {
+	engine, ok := ParseEngine("vm")
+	testingutils.Assert(t, ok && engine == VM, "ParseEngine(vm) = %s, %v", engine, ok)
+	_, ok = ParseEngine("jit")
+	testingutils.Assert(t, !ok, "ParseEngine(jit) should fail")
+}
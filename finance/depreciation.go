@@ -0,0 +1,96 @@
+package finance
+
+import "math"
+
+func checkAsset(cost, salvage, life float64) error {
+	if cost < 0 || salvage < 0 {
+		return invalid("the cost and salvage must not be negative")
+	}
+	if life <= 0 {
+		return invalid("the life must be positive, got %g", life)
+	}
+	return nil
+}
+
+func checkPeriod(period, last float64) error {
+	if period < 1 || period > last {
+		return invalid("the period must be between 1 and %g, got %g", last, period)
+	}
+	return nil
+}
+
+// SLN is the straight line depreciation of an asset for one period
+func SLN(cost, salvage, life float64) (float64, error) {
+	if life == 0 {
+		return 0, invalid("the life must not be zero")
+	}
+	return (cost - salvage) / life, nil
+}
+
+// SYD is the sum of years' digits depreciation of an asset for a period
+func SYD(cost, salvage, life, period float64) (float64, error) {
+	if err := checkAsset(cost, salvage, life); err != nil {
+		return 0, err
+	}
+	if err := checkPeriod(period, life); err != nil {
+		return 0, err
+	}
+	return (cost - salvage) * (life - period + 1) * 2 / (life * (life + 1)), nil
+}
+
+// DDB is the declining balance depreciation of an asset for a period, at
+// factor times the straight line rate. The double declining balance uses a
+// factor of 2
+func DDB(cost, salvage, life, period, factor float64) (float64, error) {
+	if err := checkAsset(cost, salvage, life); err != nil {
+		return 0, err
+	}
+	if err := checkPeriod(period, life); err != nil {
+		return 0, err
+	}
+	if factor <= 0 {
+		return 0, invalid("the factor must be positive, got %g", factor)
+	}
+
+	rate := math.Min(factor/life, 1)
+	total, depreciation := 0.0, 0.0
+	for p := 1.0; p <= period; p++ {
+		depreciation = math.Max(0, math.Min((cost-total)*rate, cost-salvage-total))
+		total += depreciation
+	}
+	return depreciation, nil
+}
+
+// DB is the fixed declining balance depreciation of an asset for a period,
+// with month months in the first year. The rate is rounded to three decimals
+// as spreadsheets do
+func DB(cost, salvage, life, period, month float64) (float64, error) {
+	if err := checkAsset(cost, salvage, life); err != nil {
+		return 0, err
+	}
+	if month < 1 || month > 12 {
+		return 0, invalid("the months of the first year must be between 1 and 12, got %g", month)
+	}
+	last := life
+	if month < 12 {
+		last++
+	}
+	if err := checkPeriod(period, last); err != nil {
+		return 0, err
+	}
+	if cost == 0 {
+		return 0, nil
+	}
+
+	rate := math.Round((1-math.Pow(salvage/cost, 1/life))*1000) / 1000
+	total := cost * rate * month / 12
+	depreciation := total
+	for p := 2.0; p <= period; p++ {
+		depreciation = (cost - total) * rate
+		if p > life {
+			depreciation = depreciation * (12 - month) / 12
+		}
+		total += depreciation
+	}
+	return depreciation, nil
+}
@@ -0,0 +1,221 @@
+// Package finance implements the time value of money functions of spreadsheets.
+//
+// Amounts follow the sign convention of Excel: money paid out is negative and
+// money received is positive, so a loan has a positive present value and
+// negative payments. Payments are made at the end of each period unless due is
+// set, in which case they are made at the beginning
+package finance
+
+import (
+	"errors"
+	"fmt"
+	"gocalc/numeric"
+	"math"
+	"time"
+)
+
+var ErrInvalid = errors.New("invalid argument")
+
+func invalid(format string, v ...interface{}) error {
+	return fmt.Errorf("%w: %s", ErrInvalid, fmt.Sprintf(format, v...))
+}
+
+// annuity returns the growth factor (1+rate)^nper and the value of a series
+// of unit payments at the end of the last period. Both are computed from
+// log1p and expm1 so that they stay accurate for rates close to 0
+func annuity(rate, nper float64, due bool) (growth, payments float64) {
+	if rate == 0 {
+		return 1, nper
+	}
+	x := nper * math.Log1p(rate)
+	growth = math.Exp(x)
+	payments = math.Expm1(x) / rate
+	if due {
+		payments *= 1 + rate
+	}
+	return growth, payments
+}
+
+// FV is the future value of an investment
+func FV(rate, nper, pmt, pv float64, due bool) float64 {
+	growth, payments := annuity(rate, nper, due)
+	return -(pv*growth + pmt*payments)
+}
+
+// PV is the present value of an investment
+func PV(rate, nper, pmt, fv float64, due bool) float64 {
+	growth, payments := annuity(rate, nper, due)
+	return -(fv + pmt*payments) / growth
+}
+
+// PMT is the payment per period of a loan with constant payments and rate
+func PMT(rate, nper, pv, fv float64, due bool) (float64, error) {
+	if nper == 0 {
+		return 0, invalid("the number of periods must not be zero")
+	}
+	growth, payments := annuity(rate, nper, due)
+	return -(fv + pv*growth) / payments, nil
+}
+
+// NPER is the number of periods needed to pay a loan
+func NPER(rate, pmt, pv, fv float64, due bool) (float64, error) {
+	if rate == 0 {
+		if pmt == 0 {
+			return 0, invalid("the payment must not be zero without interest")
+		}
+		return -(pv + fv) / pmt, nil
+	}
+
+	if due {
+		pmt *= 1 + rate
+	}
+	ratio := (pmt - fv*rate) / (pmt + pv*rate)
+	if ratio <= 0 || math.IsInf(ratio, 0) || math.IsNaN(ratio) {
+		return 0, invalid("the loan is never paid")
+	}
+	return math.Log(ratio) / math.Log(1+rate), nil
+}
+
+// Rate is the interest rate per period of an annuity, found with Newton's
+// method from guess
+func Rate(nper, pmt, pv, fv float64, due bool, guess float64, opts numeric.Options) (float64, error) {
+	if nper <= 0 {
+		return 0, invalid("the number of periods must be positive, got %g", nper)
+	}
+	f := func(rate float64) (float64, error) {
+		if rate <= -1 {
+			return math.NaN(), nil
+		}
+		growth, payments := annuity(rate, nper, due)
+		return pv*growth + pmt*payments + fv, nil
+	}
+	// payments that add up to the amounts are a zero rate, which Newton's
+	// method only approaches
+	if zero, _ := f(0); zero == 0 {
+		return 0, nil
+	}
+	return numeric.Newton(f, nil, guess, opts)
+}
+
+// NPV is the net present value of cash flows received at the end of
+// consecutive periods, the first one included
+func NPV(rate float64, values []float64) float64 {
+	npv := 0.0
+	for i := len(values) - 1; i >= 0; i-- {
+		npv = (npv + values[i]) / (1 + rate)
+	}
+	return npv
+}
+
+func checkCashFlows(values []float64) error {
+	positive, negative := false, false
+	for _, v := range values {
+		positive = positive || v > 0
+		negative = negative || v < 0
+	}
+	if !positive || !negative {
+		return invalid("the cash flows must have a positive and a negative value")
+	}
+	return nil
+}
+
+// IRR is the internal rate of return of cash flows at regular periods
+func IRR(values []float64, guess float64, opts numeric.Options) (float64, error) {
+	if err := checkCashFlows(values); err != nil {
+		return 0, err
+	}
+	f := func(rate float64) (float64, error) {
+		return NPV(rate, values) * (1 + rate), nil
+	}
+	df := func(rate float64) (float64, error) {
+		d := 0.0
+		for i, v := range values {
+			d -= float64(i) * v / math.Pow(1+rate, float64(i+1))
+		}
+		return d, nil
+	}
+	return numeric.Newton(f, df, guess, opts)
+}
+
+// XIRR is the internal rate of return of cash flows at the given dates,
+// counting 365 days a year
+func XIRR(values []float64, dates []time.Time, guess float64, opts numeric.Options) (float64, error) {
+	if len(values) != len(dates) {
+		return 0, invalid("%d values and %d dates", len(values), len(dates))
+	}
+	if err := checkCashFlows(values); err != nil {
+		return 0, err
+	}
+	years := make([]float64, len(dates))
+	for i, d := range dates {
+		if d.Before(dates[0]) {
+			return 0, invalid("the dates must not precede the first one")
+		}
+		years[i] = d.Sub(dates[0]).Hours() / 24 / 365
+	}
+
+	f := func(rate float64) (float64, error) {
+		sum := 0.0
+		for i, v := range values {
+			sum += v / math.Pow(1+rate, years[i])
+		}
+		return sum, nil
+	}
+	df := func(rate float64) (float64, error) {
+		d := 0.0
+		for i, v := range values {
+			d -= years[i] * v / math.Pow(1+rate, years[i]+1)
+		}
+		return d, nil
+	}
+	return numeric.Newton(f, df, guess, opts)
+}
+
+// Compound is the value of principal after years at an annual rate
+// compounded n times a year
+func Compound(principal, rate, years, n float64) (float64, error) {
+	if n <= 0 {
+		return 0, invalid("the number of compounding periods must be positive, got %g", n)
+	}
+	return principal * math.Pow(1+rate/n, n*years), nil
+}
+
+// Payment is a row of an amortisation schedule
+type Payment struct {
+	Period    int
+	Payment   float64
+	Interest  float64
+	Principal float64
+	Balance   float64
+}
+
+// MaxPeriods is the longest schedule returned by Amortize
+const MaxPeriods = 1 << 20
+
+// Amortize returns the schedule of a loan of pv paid at the end of nper
+// periods. Amounts are positive
+func Amortize(rate float64, nper int, pv float64) ([]Payment, error) {
+	if nper <= 0 {
+		return nil, invalid("the number of periods must be positive, got %d", nper)
+	}
+	if nper > MaxPeriods {
+		return nil, invalid("the number of periods must be at most %d, got %d", MaxPeriods, nper)
+	}
+	pmt, err := PMT(rate, float64(nper), pv, 0, false)
+	if err != nil {
+		return nil, err
+	}
+
+	schedule := make([]Payment, nper)
+	balance := pv
+	for i := range schedule {
+		interest := balance * rate
+		principal := -pmt - interest
+		balance -= principal
+		if i == nper-1 {
+			balance = 0
+		}
+		schedule[i] = Payment{Period: i + 1, Payment: -pmt, Interest: interest, Principal: principal, Balance: balance}
+	}
+	return schedule, nil
+}
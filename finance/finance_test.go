@@ -0,0 +1,123 @@
+package finance
+
+import (
+	"errors"
+	"gocalc/numeric"
+	"gocalc/testing_utils"
+	"math"
+	"testing"
+	"time"
+)
+
+// near compares with the precision of the reference values, which are those
+// printed by Excel
+func near(a, b, precision float64) bool { return math.Abs(a-b) <= precision }
+
+func must(v float64, err error) float64 {
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+func TestTimeValueOfMoney(t *testing.T) {
+	opts := numeric.DefaultOptions
+	tests := []struct {
+		name      string
+		value     float64
+		expected  float64
+		precision float64
+	}{
+		{"FV(0.06/12, 10, -200, -500, 1)", FV(0.06/12, 10, -200, -500, true), 2581.40, 0.005},
+		{"FV(0.12/12, 12, -1000)", FV(0.01, 12, -1000, 0, false), 12682.50, 0.005},
+		{"FV(0, 12, -100, -1000)", FV(0, 12, -100, -1000, false), 2200, 0},
+		{"PV(0.08/12, 12*20, 500)", PV(0.08/12, 240, 500, 0, false), -59777.15, 0.005},
+		{"PMT(0.08/12, 10, 10000)", must(PMT(0.08/12, 10, 10000, 0, false)), -1037.03, 0.005},
+		{"PMT(0.08/12, 10, 10000, 0, 1)", must(PMT(0.08/12, 10, 10000, 0, true)), -1030.16, 0.005},
+		{"PMT(0.06/12, 18*12, 0, 50000)", must(PMT(0.005, 216, 0, 50000, false)), -129.08, 0.005},
+		{"NPER(0.12/12, -100, -1000, 10000, 1)", must(NPER(0.01, -100, -1000, 10000, true)), 59.6738657, 5e-8},
+		{"NPER(0.12/12, -100, -1000, 10000)", must(NPER(0.01, -100, -1000, 10000, false)), 60.0821229, 5e-8},
+		{"NPER(0.12/12, -100, -1000)", must(NPER(0.01, -100, -1000, 0, false)), -9.57859404, 5e-9},
+		{"RATE(4*12, -200, 8000)", must(Rate(48, -200, 8000, 0, false, 0.1, opts)), 0.007701472, 5e-10},
+		{"RATE(10, -100, 1000)", must(Rate(10, -100, 1000, 0, false, 0.1, opts)), 0, 0},
+		{"RATE(10, -100, 1000.01)", must(Rate(10, -100, 1000.01, 0, false, 0.1, opts)), -1.8181e-6, 5e-10},
+		{"NPV(0.1, -10000, 3000, 4200, 6800)", NPV(0.1, []float64{-10000, 3000, 4200, 6800}), 1188.44, 0.005},
+		{"IRR(-70000, 12000, 15000, 18000, 21000, 26000)",
+			must(IRR([]float64{-70000, 12000, 15000, 18000, 21000, 26000}, 0.1, opts)), 0.086630948, 5e-10},
+		{"IRR(-70000, 12000, 15000, 18000, 21000)",
+			must(IRR([]float64{-70000, 12000, 15000, 18000, 21000}, 0.1, opts)), -0.021244848, 5e-10},
+		{"Compound(1000, 0.05, 10, 12)", must(Compound(1000, 0.05, 10, 12)), 1647.01, 0.005},
+	}
+
+	for _, tt := range tests {
+		testingutils.Assert(t, near(tt.value, tt.expected, tt.precision), "%s: expected %v, got %v", tt.name, tt.expected, tt.value)
+	}
+
+	day := func(y int, m time.Month, d int) time.Time { return time.Date(y, m, d, 0, 0, 0, 0, time.UTC) }
+	xirr, err := XIRR([]float64{-10000, 2750, 4250, 3250, 2750},
+		[]time.Time{day(2008, 1, 1), day(2008, 3, 1), day(2008, 10, 30), day(2009, 2, 15), day(2009, 4, 1)}, 0.1, opts)
+	testingutils.Assert(t, err == nil, "XIRR: %v", err)
+	testingutils.Assert(t, near(xirr, 0.373362535, 5e-9), "XIRR: expected 0.373362535, got %v", xirr)
+
+	_, err = IRR([]float64{100, 200}, 0.1, opts)
+	testingutils.Assert(t, errors.Is(err, ErrInvalid), "IRR without a negative value: %v", err)
+	_, err = NPER(0.01, -10, 10000, 0, false)
+	testingutils.Assert(t, errors.Is(err, ErrInvalid), "NPER of an unpaid loan: %v", err)
+}
+
+func TestAmortize(t *testing.T) {
+	_, err := Amortize(0.1, MaxPeriods+1, 100)
+	testingutils.Assert(t, errors.Is(err, ErrInvalid), "too many periods: got %v", err)
+
+	schedule, err := Amortize(0.01, 12, 10000)
+	testingutils.Assert(t, err == nil, "Amortize: %v", err)
+	testingutils.Equals(t, 12, len(schedule), "periods")
+
+	first, last := schedule[0], schedule[11]
+	testingutils.Assert(t, near(first.Payment, 888.49, 0.005), "payment: got %v", first.Payment)
+	testingutils.Assert(t, near(first.Interest, 100, 1e-9), "interest: got %v", first.Interest)
+	testingutils.Assert(t, near(first.Principal, 788.49, 0.005), "principal: got %v", first.Principal)
+	testingutils.Assert(t, near(last.Interest, 8.80, 0.005), "last interest: got %v", last.Interest)
+	testingutils.Equals(t, 0.0, last.Balance, "last balance")
+
+	principal := 0.0
+	for _, p := range schedule {
+		principal += p.Principal
+	}
+	testingutils.Assert(t, near(principal, 10000, 1e-6), "the principal adds up to the loan, got %v", principal)
+}
+
+func TestDepreciation(t *testing.T) {
+	tests := []struct {
+		name      string
+		value     float64
+		expected  float64
+		precision float64
+	}{
+		{"SLN(30000, 7500, 10)", must(SLN(30000, 7500, 10)), 2250, 0},
+		{"SYD(30000, 7500, 10, 1)", must(SYD(30000, 7500, 10, 1)), 4090.91, 0.005},
+		{"SYD(30000, 7500, 10, 10)", must(SYD(30000, 7500, 10, 10)), 409.09, 0.005},
+		{"DDB(2400, 300, 10*365, 1)", must(DDB(2400, 300, 3650, 1, 2)), 1.32, 0.005},
+		{"DDB(2400, 300, 10*12, 1, 2)", must(DDB(2400, 300, 120, 1, 2)), 40, 0.005},
+		{"DDB(2400, 300, 10, 1, 2)", must(DDB(2400, 300, 10, 1, 2)), 480, 0.005},
+		{"DDB(2400, 300, 10, 2, 1.5)", must(DDB(2400, 300, 10, 2, 1.5)), 306, 0.005},
+		{"DDB(2400, 300, 10, 10)", must(DDB(2400, 300, 10, 10, 2)), 22.12, 0.005},
+		{"DDB(1000, 500, 5, 3)", must(DDB(1000, 500, 5, 3, 2)), 0, 0},
+		{"DB(1000000, 100000, 6, 1, 7)", must(DB(1000000, 100000, 6, 1, 7)), 186083.33, 0.005},
+		{"DB(1000000, 100000, 6, 2, 7)", must(DB(1000000, 100000, 6, 2, 7)), 259639.42, 0.005},
+		{"DB(1000000, 100000, 6, 3, 7)", must(DB(1000000, 100000, 6, 3, 7)), 176814.44, 0.005},
+		{"DB(1000000, 100000, 6, 4, 7)", must(DB(1000000, 100000, 6, 4, 7)), 120410.64, 0.005},
+		{"DB(1000000, 100000, 6, 5, 7)", must(DB(1000000, 100000, 6, 5, 7)), 81999.64, 0.005},
+		{"DB(1000000, 100000, 6, 6, 7)", must(DB(1000000, 100000, 6, 6, 7)), 55841.76, 0.005},
+		{"DB(1000000, 100000, 6, 7, 7)", must(DB(1000000, 100000, 6, 7, 7)), 15845.10, 0.005},
+	}
+
+	for _, tt := range tests {
+		testingutils.Assert(t, near(tt.value, tt.expected, tt.precision), "%s: expected %v, got %v", tt.name, tt.expected, tt.value)
+	}
+
+	_, err := DB(1000000, 100000, 6, 7, 12)
+	testingutils.Assert(t, errors.Is(err, ErrInvalid), "DB past the life of the asset: %v", err)
+	_, err = SYD(30000, 7500, 0, 1)
+	testingutils.Assert(t, errors.Is(err, ErrInvalid), "SYD without life: %v", err)
+}
@@ -1,50 +1,173 @@
 package lexer
 
 import (
+	"fmt"
+	"gocalc/calendar"
 	"gocalc/token"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
 )
 
+// Lexer splits its input into tokens. The input is read as UTF-8, positions
+// are byte offsets while lines and columns count characters
 type Lexer struct {
 	input        string
-	position     int
-	nextPosition int
-	ch           byte
+	position     int // offset of ch
+	nextPosition int // offset of the character after ch
+	ch           rune
+	line, column int // location of ch
+
+	// notation enables the tokens of the mathematical notation, see SetNotation
+	notation bool
+
+	// start is the location of the token being read, errors explain the
+	// illegal tokens by their location
+	start  position
+	errors map[position]string
+
+	// lastLine is the line the previous token ends on, to tell trailing comments
+	lastLine int
 }
 
+type position struct{ line, column int }
+
 func New(input string) *Lexer {
-	l := &Lexer{input: input}
+	l := &Lexer{input: input, line: 1}
 	l.readChar()
 	return l
 }
 
-func (l *Lexer) readChar() {
-	l.ch = l.peekChar()
-	l.advanceChar()
+// SetNotation enables the Unicode operators, read as the ASCII ones they stand
+// for, and the | and % tokens. Duration literals are not read, so that 2d is 2
+// times d
+func (l *Lexer) SetNotation(notation bool) { l.notation = notation }
+
+// notationSymbols are the Unicode operators of the notation and their tokens
+var notationSymbols = []struct {
+	symbol string
+	tok    token.Token
+}{
+	{"×", token.NewExt(token.ASTERISK, "*")},
+	{"÷", token.NewExt(token.SLASH, "/")},
+	{"≤", token.NewExt(token.LT_EQ, "<=")},
+	{"≥", token.NewExt(token.GT_EQ, ">=")},
+	{"≠", token.NewExt(token.NOT_EQ, "!=")},
+	{"√", token.NewExt(token.SQRT, "√")},
+	{"π", token.NewExt(token.IDENT, "pi")},
+}
+
+// readNotation reads the tokens only available in notation mode
+func (l *Lexer) readNotation() (token.Token, bool) {
+	for _, s := range notationSymbols {
+		if strings.HasPrefix(l.input[l.position:], s.symbol) {
+			for n := utf8.RuneCountInString(s.symbol); n > 0; n-- {
+				l.readChar()
+			}
+			return s.tok, true
+		}
+	}
+
+	switch {
+	case l.ch == '|' && l.peekChar() != '|':
+		l.readChar()
+		return token.New(token.BAR, '|'), true
+	case l.ch == '%':
+		l.readChar()
+		return token.New(token.PERCENT, '%'), true
+	}
+	return token.Token{}, false
 }
 
-func (l *Lexer) advanceChar() {
+func (l *Lexer) readChar() {
+	if l.ch == '\n' {
+		l.line++
+		l.column = 0
+	}
 	l.position = l.nextPosition
-	l.nextPosition++
+	l.column++
+
+	if l.position >= len(l.input) {
+		l.position, l.nextPosition = len(l.input), len(l.input)
+		l.ch = 0
+		return
+	}
+	ch, width := utf8.DecodeRuneInString(l.input[l.position:])
+	l.ch = ch
+	l.nextPosition = l.position + width
 }
 
-func (l *Lexer) peekChar() byte {
+func (l *Lexer) peekChar() rune {
 	if l.nextPosition >= len(l.input) {
 		return 0
-	} else {
-		return l.input[l.nextPosition]
 	}
+	ch, _ := utf8.DecodeRuneInString(l.input[l.nextPosition:])
+	return ch
 }
 
+// NextToken returns the next token of the input, EOF once it has been read
 func (l *Lexer) NextToken() token.Token {
+	comments := l.readComments()
 
-	l.eatWhitespaces()
+	l.start = position{l.line, l.column}
+	tok := l.readToken()
+	tok.Line, tok.Column = l.start.line, l.start.column
+	tok.Comments = comments
+	l.lastLine = l.line
+	return tok
+}
 
-	if isDigit(l.ch) {
-		res := l.readWhile(isDigit)
-		if res[0] == '.' {
-			res = "0" + res
+// readComments skips the whitespace and comments before a token, returning the
+// comments. An unterminated block comment is left for readToken
+func (l *Lexer) readComments() []token.Comment {
+	var comments []token.Comment
+	for {
+		l.eatWhitespaces()
+		rest := l.input[l.position:]
+		var end int
+		switch {
+		case l.ch == '#' || strings.HasPrefix(rest, "//"):
+			if end = strings.IndexByte(rest, '\n'); end < 0 {
+				end = len(rest)
+			}
+			end = len(strings.TrimRight(rest[:end], "\r"))
+		case strings.HasPrefix(rest, "/*"):
+			if end = strings.Index(rest[2:], "*/"); end < 0 {
+				return comments
+			}
+			end += 4
+		default:
+			return comments
+		}
+
+		comments = append(comments, token.Comment{Literal: rest[:end], Line: l.line, Column: l.column, Trailing: l.line == l.lastLine})
+		for start := l.position; l.position < start+end; {
+			l.readChar()
+		}
+	}
+}
+
+// Error returns why tok is illegal, if the lexer knows more than the token
+func (l *Lexer) Error(tok token.Token) (string, bool) {
+	msg, ok := l.errors[position{tok.Line, tok.Column}]
+	return msg, ok
+}
+
+func (l *Lexer) readToken() token.Token {
+	if l.notation && l.position < len(l.input) {
+		if tok, ok := l.readNotation(); ok {
+			return tok
 		}
-		return token.NewExt(token.FLOAT, res)
+	}
+
+	if l.ch == '.' && !isDecimal(l.peekChar()) {
+		l.readChar()
+		return token.New(token.PERIOD, '.')
+	}
+
+	if isDigit(l.ch) {
+		return l.readNumber()
 	}
 
 	if isLetter(l.ch) {
@@ -58,6 +181,10 @@ func (l *Lexer) NextToken() token.Token {
 		return t
 	}
 
+	if l.ch == '"' {
+		return l.readString()
+	}
+
 	defer l.readChar()
 
 	switch l.ch {
@@ -65,20 +192,26 @@ func (l *Lexer) NextToken() token.Token {
 		return token.New(token.LBRACK, '[')
 	case ']':
 		return token.New(token.RBRACK, ']')
+	case '{':
+		return token.New(token.LBRACE, l.ch)
+	case '}':
+		return token.New(token.RBRACE, l.ch)
 	case '=':
 		if l.peekChar() == '=' {
-			l.advanceChar()
+			l.readChar()
 			return token.NewExt(token.EQ, "==")
 		}
 		return token.New(token.ASSIGN, l.ch)
 	case '!':
 		if l.peekChar() == '=' {
-			l.advanceChar()
+			l.readChar()
 			return token.NewExt(token.NOT_EQ, "!=")
 		}
 		return token.New(token.BANG, l.ch)
 	case ';':
 		return token.New(token.SEMICOLON, l.ch)
+	case ':':
+		return token.New(token.COLON, l.ch)
 	case '(':
 		return token.New(token.LPAREN, l.ch)
 	case ')':
@@ -86,35 +219,63 @@ func (l *Lexer) NextToken() token.Token {
 	case ',':
 		return token.New(token.COMMA, l.ch)
 	case '+':
+		if l.peekChar() == '=' {
+			l.readChar()
+			return token.NewExt(token.PLUS_ASSIGN, "+=")
+		}
 		return token.New(token.PLUS, l.ch)
 	case '-':
+		if l.peekChar() == '=' {
+			l.readChar()
+			return token.NewExt(token.MINUS_ASSIGN, "-=")
+		}
 		return token.New(token.MINUS, l.ch)
 	case '*':
+		if l.peekChar() == '=' {
+			l.readChar()
+			return token.NewExt(token.ASTERISK_ASSIGN, "*=")
+		}
 		return token.New(token.ASTERISK, l.ch)
 	case '/':
+		if l.peekChar() == '*' {
+			for l.nextPosition < len(l.input) {
+				l.readChar()
+			}
+			return l.illegal("/*", fmt.Sprintf("Unterminated comment at line %d, column %d", l.start.line, l.start.column))
+		}
+		if l.peekChar() == '=' {
+			l.readChar()
+			return token.NewExt(token.SLASH_ASSIGN, "/=")
+		}
 		return token.New(token.SLASH, l.ch)
 	case '^':
+		if l.peekChar() == '=' {
+			l.readChar()
+			return token.NewExt(token.CARET_ASSIGN, "^=")
+		}
 		return token.New(token.CARET, l.ch)
+	case '\'':
+		return token.New(token.QUOTE, l.ch)
 	case '>':
 		if l.peekChar() == '=' {
-			l.advanceChar()
+			l.readChar()
 			return token.NewExt(token.GT_EQ, ">=")
 		}
 		return token.New(token.GT, l.ch)
 	case '<':
 		if l.peekChar() == '=' {
-			l.advanceChar()
+			l.readChar()
 			return token.NewExt(token.LT_EQ, "<=")
 		}
 		return token.New(token.LT, l.ch)
 	case '&':
 		if l.peekChar() == '&' {
-			l.advanceChar()
+			l.readChar()
 			return token.NewExt(token.AND, "&&")
 		}
 	case '|':
 		if l.peekChar() == '|' {
-			l.advanceChar()
+			l.readChar()
 			return token.NewExt(token.OR, "||")
 		}
 	case 0:
@@ -124,9 +285,165 @@ func (l *Lexer) NextToken() token.Token {
 	return token.New(token.ILLEGAL, l.ch)
 }
 
-type bytePredicate func(ch byte) bool
+// durationUnit returns the length of the duration unit after a number, or 0
+func (l *Lexer) durationUnit() int {
+	// 2d is 2 times d in notation mode
+	if l.notation || l.position >= len(l.input) {
+		return 0
+	}
+	n := calendar.UnitAt(l.input[l.position:])
+	if next, _ := utf8.DecodeRuneInString(l.input[l.position+n:]); n == 0 || isAlpha(next) {
+		return 0
+	}
+	return n
+}
+
+// readDuration reads the parts of a duration literal such as 3d 4h, starting at
+// the unit of the first part
+func (l *Lexer) readDuration(start, unit int) string {
+	for {
+		for i := 0; i < unit; i++ {
+			l.readChar()
+		}
+		end := l.position
+
+		saved := *l
+		l.eatWhitespaces()
+		if !isDigit(l.ch) || !l.readMantissa() {
+			*l = saved
+			return strings.ReplaceAll(l.input[start:end], "_", "")
+		}
+		if unit = l.durationUnit(); unit == 0 {
+			*l = saved
+			return strings.ReplaceAll(l.input[start:end], "_", "")
+		}
+	}
+}
+
+// readNumber reads a number such as 42, 1_000, .5, 5., 6.02e23 or 1E-3, or a
+// duration literal. Malformed numbers are illegal tokens
+func (l *Lexer) readNumber() token.Token {
+	start := l.position
+	valid := l.readMantissa()
+	if unit := l.durationUnit(); unit > 0 && valid {
+		return token.NewExt(token.DURATION, l.readDuration(start, unit))
+	}
+
+	if l.exponent() {
+		l.readChar()
+		if l.ch == '+' || l.ch == '-' {
+			l.readChar()
+		}
+		if !isDecimal(l.ch) {
+			return l.numberError(start, "missing exponent digits")
+		}
+		valid = l.readDigits() && valid
+	}
+
+	switch {
+	case l.ch == '.' || l.ch == '_' || isDecimal(l.ch):
+		l.readWhile(func(ch rune) bool { return isDigit(ch) || ch == '_' })
+		if !valid {
+			return l.numberError(start, "misplaced digit separator")
+		}
+		return l.numberError(start, "unexpected decimal point")
+	case !valid:
+		return l.numberError(start, "misplaced digit separator")
+	}
+
+	res := strings.ReplaceAll(l.input[start:l.position], "_", "")
+	if _, err := strconv.ParseFloat(res, 64); err != nil {
+		return l.numberError(start, "out of range")
+	}
+	if res[0] == '.' {
+		res = "0" + res
+	}
+	return token.NewExt(token.FLOAT, strings.TrimSuffix(res, "."))
+}
+
+// readMantissa reads the digits of a number and its decimal point, reporting
+// whether the digit separators are well placed
+func (l *Lexer) readMantissa() bool {
+	valid := l.readDigits()
+	if l.ch == '.' {
+		l.readChar()
+		valid = l.readDigits() && valid
+	}
+	return valid
+}
+
+// exponent reports whether an exponent follows the mantissa. 2e is 2 times e in
+// notation mode, where the exponent needs digits
+func (l *Lexer) exponent() bool {
+	if l.ch != 'e' && l.ch != 'E' {
+		return false
+	}
+	if !l.notation {
+		return true
+	}
+	rest := l.input[l.nextPosition:]
+	if strings.HasPrefix(rest, "+") || strings.HasPrefix(rest, "-") {
+		rest = rest[1:]
+	}
+	return rest != "" && isDecimal(rune(rest[0]))
+}
+
+// readDigits reads decimal digits, which can be grouped by single underscores
+func (l *Lexer) readDigits() bool {
+	valid := true
+	var prev rune
+	for isDecimal(l.ch) || l.ch == '_' {
+		if l.ch == '_' && (!isDecimal(prev) || !isDecimal(l.peekChar())) {
+			valid = false
+		}
+		prev = l.ch
+		l.readChar()
+	}
+	return valid
+}
+
+func (l *Lexer) numberError(start int, reason string) token.Token {
+	lit := l.input[start:l.position]
+	return l.illegal(lit, fmt.Sprintf("Malformed number %s at line %d, column %d: %s", lit, l.start.line, l.start.column, reason))
+}
+
+// illegal returns an illegal token, recording msg as the reason, see Error
+func (l *Lexer) illegal(lit, msg string) token.Token {
+	if l.errors == nil {
+		l.errors = make(map[position]string)
+	}
+	l.errors[l.start] = msg
+	return token.NewExt(token.ILLEGAL, lit)
+}
 
-func (l *Lexer) readWhile(pred bytePredicate) string {
+var unescapes = map[rune]rune{'"': '"', '\\': '\\', 'n': '\n', 't': '\t'}
+
+// readString reads a string literal, returning an illegal token if it is not terminated
+func (l *Lexer) readString() token.Token {
+	start := l.position
+	var buf strings.Builder
+	for {
+		l.readChar()
+		switch l.ch {
+		case 0:
+			return token.NewExt(token.ILLEGAL, l.input[start:l.position])
+		case '"':
+			l.readChar()
+			return token.NewExt(token.STRING, buf.String())
+		case '\\':
+			l.readChar()
+			ch, ok := unescapes[l.ch]
+			if !ok {
+				return token.NewExt(token.ILLEGAL, l.input[start:l.nextPosition])
+			}
+			buf.WriteRune(ch)
+		default:
+			buf.WriteRune(l.ch)
+		}
+	}
+}
+
+func (l *Lexer) readWhile(pred func(ch rune) bool) string {
 	start := l.position
 	for pred(l.ch) {
 		l.readChar()
@@ -135,12 +452,22 @@ func (l *Lexer) readWhile(pred bytePredicate) string {
 	return l.input[start:l.position]
 }
 
-func isDigit(ch byte) bool {
-	return '0' <= ch && ch <= '9' || ch == '.'
+// isDigit reports whether ch can start a number, a digit or a decimal point
+func isDigit(ch rune) bool {
+	return isDecimal(ch) || ch == '.'
+}
+
+func isDecimal(ch rune) bool {
+	return '0' <= ch && ch <= '9'
+}
+
+// isAlpha accepts the Unicode letters, so identifiers can be Greek letters like θ
+func isAlpha(ch rune) bool {
+	return unicode.IsLetter(ch) || ch == '_'
 }
 
-func isLetter(ch byte) bool {
-	return 'a' <= ch && ch <= 'z' || 'A' <= ch && ch <= 'Z' || ch == '_' || isDigit(ch)
+func isLetter(ch rune) bool {
+	return isAlpha(ch) || isDecimal(ch)
 }
 
 func (l *Lexer) eatWhitespaces() {
@@ -1,6 +1,7 @@
 package lexer
 
 import (
+	"gocalc/testing_utils"
 	"gocalc/token"
 	"testing"
 )
@@ -67,3 +68,250 @@ x1 = 3 + 5^3; (3 * 8 ); x1; 0.5; .5 > @;
 		}
 	}
 }
+
+func TestStringsAndDurations(t *testing.T) {
+	input := `"a \"b\"\n" + 3d 4h - 90m; 2 ms; 3days; "open`
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.STRING, "a \"b\"\n"},
+		{token.PLUS, "+"},
+		{token.DURATION, "3d 4h"},
+		{token.MINUS, "-"},
+		{token.DURATION, "90m"},
+		{token.SEMICOLON, ";"},
+		{token.FLOAT, "2"},
+		{token.IDENT, "ms"},
+		{token.SEMICOLON, ";"},
+		{token.FLOAT, "3"},
+		{token.IDENT, "days"},
+		{token.SEMICOLON, ";"},
+		{token.ILLEGAL, `"open`},
+		{token.EOF, ""},
+	}
+	l := New(input)
+	for i, tt := range tests {
+		tok := l.NextToken()
+		if tok.Type != tt.expectedType || tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - expected %s %q, got %s %q",
+				i, tt.expectedType, tt.expectedLiteral, tok.Type, tok.Literal)
+		}
+	}
+}
+
+func TestNumbers(t *testing.T) {
+	input := "6.02e23 1E-3 2.5e+2 .5 5. 1_000_000 1.2.3 1__0 1_ 2e x3 1_000ms 1e999 1e+"
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.FLOAT, "6.02e23"},
+		{token.FLOAT, "1E-3"},
+		{token.FLOAT, "2.5e+2"},
+		{token.FLOAT, "0.5"},
+		{token.FLOAT, "5"},
+		{token.FLOAT, "1000000"},
+		{token.ILLEGAL, "1.2.3"},
+		{token.ILLEGAL, "1__0"},
+		{token.ILLEGAL, "1_"},
+		{token.ILLEGAL, "2e"},
+		{token.IDENT, "x3"},
+		{token.DURATION, "1000ms"},
+		{token.ILLEGAL, "1e999"},
+		{token.ILLEGAL, "1e+"},
+		{token.EOF, ""},
+	}
+	l := New(input)
+	for i, tt := range tests {
+		tok := l.NextToken()
+		if tok.Type != tt.expectedType || tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - expected %s %q, got %s %q",
+				i, tt.expectedType, tt.expectedLiteral, tok.Type, tok.Literal)
+		}
+	}
+}
+
+func TestImportAndMembers(t *testing.T) {
+	input := `import "lib.gc"; lib.f(.5) + x1.y`
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.IMPORT, "import"},
+		{token.STRING, "lib.gc"},
+		{token.SEMICOLON, ";"},
+		{token.IDENT, "lib"},
+		{token.PERIOD, "."},
+		{token.IDENT, "f"},
+		{token.LPAREN, "("},
+		{token.FLOAT, "0.5"},
+		{token.RPAREN, ")"},
+		{token.PLUS, "+"},
+		{token.IDENT, "x1"},
+		{token.PERIOD, "."},
+		{token.IDENT, "y"},
+		{token.EOF, ""},
+	}
+	l := New(input)
+	for i, tt := range tests {
+		tok := l.NextToken()
+		if tok.Type != tt.expectedType || tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - expected %s %q, got %s %q",
+				i, tt.expectedType, tt.expectedLiteral, tok.Type, tok.Literal)
+		}
+	}
+}
+
+func TestAssignmentOperators(t *testing.T) {
+	input := `x += 1; x -= y*=2 /= z ^= 3; a,b = -1`
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.IDENT, "x"},
+		{token.PLUS_ASSIGN, "+="},
+		{token.FLOAT, "1"},
+		{token.SEMICOLON, ";"},
+		{token.IDENT, "x"},
+		{token.MINUS_ASSIGN, "-="},
+		{token.IDENT, "y"},
+		{token.ASTERISK_ASSIGN, "*="},
+		{token.FLOAT, "2"},
+		{token.SLASH_ASSIGN, "/="},
+		{token.IDENT, "z"},
+		{token.CARET_ASSIGN, "^="},
+		{token.FLOAT, "3"},
+		{token.SEMICOLON, ";"},
+		{token.IDENT, "a"},
+		{token.COMMA, ","},
+		{token.IDENT, "b"},
+		{token.ASSIGN, "="},
+		{token.MINUS, "-"},
+		{token.FLOAT, "1"},
+		{token.EOF, ""},
+	}
+	l := New(input)
+	for i, tt := range tests {
+		tok := l.NextToken()
+		if tok.Type != tt.expectedType || tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - expected %s %q, got %s %q",
+				i, tt.expectedType, tt.expectedLiteral, tok.Type, tok.Literal)
+		}
+	}
+}
+
+func TestNotationTokens(t *testing.T) {
+	input := `2π × √x ÷ |y| ≤ 5% ≠ a || b`
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.FLOAT, "2"},
+		{token.IDENT, "pi"},
+		{token.ASTERISK, "*"},
+		{token.SQRT, "√"},
+		{token.IDENT, "x"},
+		{token.SLASH, "/"},
+		{token.BAR, "|"},
+		{token.IDENT, "y"},
+		{token.BAR, "|"},
+		{token.LT_EQ, "<="},
+		{token.FLOAT, "5"},
+		{token.PERCENT, "%"},
+		{token.NOT_EQ, "!="},
+		{token.IDENT, "a"},
+		{token.OR, "||"},
+		{token.IDENT, "b"},
+		{token.EOF, ""},
+	}
+	l := New(input)
+	l.SetNotation(true)
+	for i, tt := range tests {
+		tok := l.NextToken()
+		if tok.Type != tt.expectedType || tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - expected %s %q, got %s %q",
+				i, tt.expectedType, tt.expectedLiteral, tok.Type, tok.Literal)
+		}
+	}
+
+	l = New("|x| 5%")
+	for _, tt := range []token.TokenType{token.ILLEGAL, token.IDENT, token.ILLEGAL, token.FLOAT, token.ILLEGAL} {
+		if tok := l.NextToken(); tok.Type != tt {
+			t.Fatalf("without notation - expected %s, got %s %q", tt, tok.Type, tok.Literal)
+		}
+	}
+
+	l = New("2d 3h")
+	l.SetNotation(true)
+	for _, tt := range []token.TokenType{token.FLOAT, token.IDENT, token.FLOAT, token.IDENT, token.EOF} {
+		if tok := l.NextToken(); tok.Type != tt {
+			t.Fatalf("durations in notation - expected %s, got %s %q", tt, tok.Type, tok.Literal)
+		}
+	}
+}
+
+func TestUnicode(t *testing.T) {
+	input := "θ = 30;\nα_1 + \"héllo, 世界\" @ é2 ¤"
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+		line, column    int
+	}{
+		{token.IDENT, "θ", 1, 1},
+		{token.ASSIGN, "=", 1, 3},
+		{token.FLOAT, "30", 1, 5},
+		{token.SEMICOLON, ";", 1, 7},
+		{token.IDENT, "α_1", 2, 1},
+		{token.PLUS, "+", 2, 5},
+		{token.STRING, "héllo, 世界", 2, 7},
+		{token.ILLEGAL, "@", 2, 19},
+		{token.IDENT, "é2", 2, 21},
+		{token.ILLEGAL, "¤", 2, 24},
+		{token.EOF, "", 2, 25},
+	}
+	l := New(input)
+	for i, tt := range tests {
+		tok := l.NextToken()
+		if tok.Type != tt.expectedType || tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - expected %s %q, got %s %q",
+				i, tt.expectedType, tt.expectedLiteral, tok.Type, tok.Literal)
+		}
+		if tok.Line != tt.line || tok.Column != tt.column {
+			t.Fatalf("tests[%d] - expected %q at %d:%d, got %d:%d",
+				i, tt.expectedLiteral, tt.line, tt.column, tok.Line, tok.Column)
+		}
+	}
+}
+
+func TestComments(t *testing.T) {
+	input := "# rate\nr = 0.05 // yearly\n/* first\n   line */ x /* open"
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+		comments        []token.Comment
+	}{
+		{token.IDENT, "r", []token.Comment{{Literal: "# rate", Line: 1, Column: 1}}},
+		{token.ASSIGN, "=", nil},
+		{token.FLOAT, "0.05", nil},
+		{token.IDENT, "x", []token.Comment{
+			{Literal: "// yearly", Line: 2, Column: 10, Trailing: true},
+			{Literal: "/* first\n   line */", Line: 3, Column: 1},
+		}},
+		{token.ILLEGAL, "/*", nil},
+		{token.EOF, "", nil},
+	}
+	l := New(input)
+	for i, tt := range tests {
+		tok := l.NextToken()
+		if tok.Type != tt.expectedType || tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - expected %s %q, got %s %q",
+				i, tt.expectedType, tt.expectedLiteral, tok.Type, tok.Literal)
+		}
+		testingutils.Equals(t, tt.comments, tok.Comments, tt.expectedLiteral)
+	}
+
+	c := token.Comment{Literal: "/* first\n   line */", Line: 3}
+	testingutils.Equals(t, "first\nline", c.Text(), "text")
+	testingutils.Equals(t, 4, c.EndLine(), "end line")
+}
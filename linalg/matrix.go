@@ -0,0 +1,375 @@
+// Package linalg implements dense real matrices and the linear algebra on them
+package linalg
+
+import (
+	"errors"
+	"fmt"
+	"gocalc/numeric"
+	"math"
+	"sort"
+)
+
+var (
+	ErrDimension = errors.New("dimension mismatch")
+	ErrSingular  = errors.New("matrix is singular")
+)
+
+// Matrix is a dense matrix stored by rows
+type Matrix struct {
+	Rows, Cols int
+	Data       []float64
+}
+
+func New(rows, cols int) *Matrix {
+	return &Matrix{Rows: rows, Cols: cols, Data: make([]float64, rows*cols)}
+}
+
+// FromRows builds a matrix from rows of the same length
+func FromRows(rows [][]float64) (*Matrix, error) {
+	if len(rows) == 0 || len(rows[0]) == 0 {
+		return nil, fmt.Errorf("%w: a matrix needs at least one row and one column", ErrDimension)
+	}
+	m := New(len(rows), len(rows[0]))
+	for i, row := range rows {
+		if len(row) != m.Cols {
+			return nil, fmt.Errorf("%w: row %d has %d columns, expected %d", ErrDimension, i+1, len(row), m.Cols)
+		}
+		copy(m.Data[i*m.Cols:], row)
+	}
+	return m, nil
+}
+
+func Identity(n int) *Matrix {
+	m := New(n, n)
+	for i := 0; i < n; i++ {
+		m.Set(i, i, 1)
+	}
+	return m
+}
+
+func (m *Matrix) At(i, j int) float64 { return m.Data[i*m.Cols+j] }
+
+func (m *Matrix) Set(i, j int, v float64) { m.Data[i*m.Cols+j] = v }
+
+// Row returns a copy of row i
+func (m *Matrix) Row(i int) []float64 {
+	return append([]float64{}, m.Data[i*m.Cols:(i+1)*m.Cols]...)
+}
+
+func (m *Matrix) Copy() *Matrix {
+	return &Matrix{Rows: m.Rows, Cols: m.Cols, Data: append([]float64{}, m.Data...)}
+}
+
+func (m *Matrix) IsSquare() bool { return m.Rows == m.Cols }
+
+// Dims returns the dimensions of m as rows x columns
+func (m *Matrix) Dims() string { return fmt.Sprintf("%dx%d", m.Rows, m.Cols) }
+
+func (m *Matrix) Equal(n *Matrix) bool {
+	if m.Rows != n.Rows || m.Cols != n.Cols {
+		return false
+	}
+	for i, v := range m.Data {
+		if v != n.Data[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func mismatch(m *Matrix, operator string, n *Matrix) error {
+	return fmt.Errorf("%w: %s %s %s", ErrDimension, m.Dims(), operator, n.Dims())
+}
+
+func notSquare(m *Matrix) error {
+	return fmt.Errorf("%w: expected a square matrix, got %s", ErrDimension, m.Dims())
+}
+
+// Apply combines the elements of two matrices of the same dimensions
+func (m *Matrix) Apply(operator string, n *Matrix, fn func(a, b float64) float64) (*Matrix, error) {
+	if m.Rows != n.Rows || m.Cols != n.Cols {
+		return nil, mismatch(m, operator, n)
+	}
+	res := New(m.Rows, m.Cols)
+	for i := range m.Data {
+		res.Data[i] = fn(m.Data[i], n.Data[i])
+	}
+	return res, nil
+}
+
+// Map applies fn to every element of m
+func (m *Matrix) Map(fn func(float64) float64) *Matrix {
+	res := New(m.Rows, m.Cols)
+	for i, v := range m.Data {
+		res.Data[i] = fn(v)
+	}
+	return res
+}
+
+func (m *Matrix) Mul(n *Matrix) (*Matrix, error) {
+	if m.Cols != n.Rows {
+		return nil, mismatch(m, "*", n)
+	}
+	res := New(m.Rows, n.Cols)
+	for i := 0; i < m.Rows; i++ {
+		for k := 0; k < m.Cols; k++ {
+			a := m.At(i, k)
+			for j := 0; j < n.Cols; j++ {
+				res.Data[i*n.Cols+j] += a * n.At(k, j)
+			}
+		}
+	}
+	return res, nil
+}
+
+// MulVec returns the product of m by the column vector v
+func (m *Matrix) MulVec(v []float64) ([]float64, error) {
+	if m.Cols != len(v) {
+		return nil, fmt.Errorf("%w: %s * %d", ErrDimension, m.Dims(), len(v))
+	}
+	res := make([]float64, m.Rows)
+	for i := range res {
+		for j, x := range v {
+			res[i] += m.At(i, j) * x
+		}
+	}
+	return res, nil
+}
+
+// VecMul returns the product of the row vector v by m
+func (m *Matrix) VecMul(v []float64) ([]float64, error) {
+	if m.Rows != len(v) {
+		return nil, fmt.Errorf("%w: %d * %s", ErrDimension, len(v), m.Dims())
+	}
+	return m.Transpose().MulVec(v)
+}
+
+func (m *Matrix) Transpose() *Matrix {
+	res := New(m.Cols, m.Rows)
+	for i := 0; i < m.Rows; i++ {
+		for j := 0; j < m.Cols; j++ {
+			res.Set(j, i, m.At(i, j))
+		}
+	}
+	return res
+}
+
+// Pow raises a square matrix to an integer power, inverting it for negative ones
+func (m *Matrix) Pow(n int) (*Matrix, error) {
+	if !m.IsSquare() {
+		return nil, notSquare(m)
+	}
+	base := m
+	if n < 0 {
+		inv, err := m.Inverse()
+		if err != nil {
+			return nil, err
+		}
+		base, n = inv, -n
+	}
+
+	res := Identity(m.Rows)
+	for ; n > 0; n >>= 1 {
+		if n&1 == 1 {
+			res, _ = res.Mul(base)
+		}
+		base, _ = base.Mul(base)
+	}
+	return res, nil
+}
+
+// lu is the LU decomposition with partial pivoting of a square matrix, with
+// L and U stored in the same matrix
+type lu struct {
+	m     *Matrix
+	perm  []int
+	sign  float64
+	exact bool // no pivot was negligible
+}
+
+func (m *Matrix) lu() *lu {
+	n := m.Rows
+	a := m.Copy()
+	res := &lu{m: a, perm: make([]int, n), sign: 1, exact: true}
+	for i := range res.perm {
+		res.perm[i] = i
+	}
+
+	scale := 0.0
+	for _, v := range m.Data {
+		scale = math.Max(scale, math.Abs(v))
+	}
+
+	for k := 0; k < n; k++ {
+		p := k
+		for i := k + 1; i < n; i++ {
+			if math.Abs(a.At(i, k)) > math.Abs(a.At(p, k)) {
+				p = i
+			}
+		}
+		if math.Abs(a.At(p, k)) <= 1e-12*scale || a.At(p, k) == 0 {
+			res.exact = false
+			continue
+		}
+		if p != k {
+			for j := 0; j < n; j++ {
+				x, y := a.At(k, j), a.At(p, j)
+				a.Set(k, j, y)
+				a.Set(p, j, x)
+			}
+			res.perm[k], res.perm[p] = res.perm[p], res.perm[k]
+			res.sign = -res.sign
+		}
+		for i := k + 1; i < n; i++ {
+			f := a.At(i, k) / a.At(k, k)
+			a.Set(i, k, f)
+			for j := k + 1; j < n; j++ {
+				a.Set(i, j, a.At(i, j)-f*a.At(k, j))
+			}
+		}
+	}
+	return res
+}
+
+func (d *lu) solve(b []float64) []float64 {
+	n := d.m.Rows
+	x := make([]float64, n)
+	for i, p := range d.perm {
+		x[i] = b[p]
+	}
+	for i := 0; i < n; i++ {
+		for j := 0; j < i; j++ {
+			x[i] -= d.m.At(i, j) * x[j]
+		}
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := i + 1; j < n; j++ {
+			x[i] -= d.m.At(i, j) * x[j]
+		}
+		x[i] /= d.m.At(i, i)
+	}
+	return x
+}
+
+func (m *Matrix) Det() (float64, error) {
+	if !m.IsSquare() {
+		return 0, notSquare(m)
+	}
+	d := m.lu()
+	if !d.exact {
+		return 0, nil
+	}
+	det := d.sign
+	for i := 0; i < m.Rows; i++ {
+		det *= d.m.At(i, i)
+	}
+	return det, nil
+}
+
+func (m *Matrix) Inverse() (*Matrix, error) {
+	if !m.IsSquare() {
+		return nil, notSquare(m)
+	}
+	d := m.lu()
+	if !d.exact {
+		return nil, ErrSingular
+	}
+
+	res := New(m.Rows, m.Cols)
+	unit := make([]float64, m.Rows)
+	for j := 0; j < m.Cols; j++ {
+		unit[j] = 1
+		for i, v := range d.solve(unit) {
+			res.Set(i, j, v)
+		}
+		unit[j] = 0
+	}
+	return res, nil
+}
+
+// Solve returns the x such that m x = b
+func (m *Matrix) Solve(b []float64) ([]float64, error) {
+	if !m.IsSquare() {
+		return nil, notSquare(m)
+	}
+	if len(b) != m.Rows {
+		return nil, fmt.Errorf("%w: %s x = %d", ErrDimension, m.Dims(), len(b))
+	}
+	d := m.lu()
+	if !d.exact {
+		return nil, ErrSingular
+	}
+	return d.solve(b), nil
+}
+
+// Eigenvalues returns the roots of the characteristic polynomial of a square
+// matrix, computed with the Faddeev-LeVerrier algorithm. It is meant for the
+// small matrices typed in a calculator
+func (m *Matrix) Eigenvalues(opts numeric.Options) ([]complex128, error) {
+	if !m.IsSquare() {
+		return nil, notSquare(m)
+	}
+	n := m.Rows
+	if m.isTriangular() {
+		values := make([]complex128, n)
+		for i := range values {
+			values[i] = complex(m.At(i, i), 0)
+		}
+		sort.Slice(values, func(i, j int) bool { return real(values[i]) < real(values[j]) })
+		return values, nil
+	}
+
+	coefs := make([]float64, n+1)
+	coefs[0] = 1
+
+	aux := New(n, n)
+	for k := 1; k <= n; k++ {
+		// aux = m (aux + c[k-1] I), c[k] = -trace(aux) / k
+		prev := aux.Copy()
+		for i := 0; i < n; i++ {
+			prev.Set(i, i, prev.At(i, i)+coefs[k-1])
+		}
+		aux, _ = m.Mul(prev)
+		trace := 0.0
+		for i := 0; i < n; i++ {
+			trace += aux.At(i, i)
+		}
+		coefs[k] = -trace / float64(k)
+	}
+	return numeric.PolyRoots(coefs, opts)
+}
+
+func (m *Matrix) isTriangular() bool {
+	upper, lower := true, true
+	for i := 0; i < m.Rows; i++ {
+		for j := 0; j < m.Cols; j++ {
+			if m.At(i, j) != 0 {
+				upper = upper && j >= i
+				lower = lower && j <= i
+			}
+		}
+	}
+	return upper || lower
+}
+
+func Dot(a, b []float64) (float64, error) {
+	if len(a) != len(b) {
+		return 0, fmt.Errorf("%w: dot of vectors of length %d and %d", ErrDimension, len(a), len(b))
+	}
+	res := 0.0
+	for i := range a {
+		res += a[i] * b[i]
+	}
+	return res, nil
+}
+
+func Cross(a, b []float64) ([]float64, error) {
+	if len(a) != 3 || len(b) != 3 {
+		return nil, fmt.Errorf("%w: cross product of vectors of length %d and %d, expected 3", ErrDimension, len(a), len(b))
+	}
+	return []float64{
+		a[1]*b[2] - a[2]*b[1],
+		a[2]*b[0] - a[0]*b[2],
+		a[0]*b[1] - a[1]*b[0],
+	}, nil
+}
@@ -0,0 +1,127 @@
+package linalg
+
+import (
+	"errors"
+	"gocalc/numeric"
+	"gocalc/testing_utils"
+	"math"
+	"testing"
+)
+
+func matrix(t *testing.T, rows ...[]float64) *Matrix {
+	m, err := FromRows(rows)
+	testingutils.Assert(t, err == nil, "FromRows: %v", err)
+	return m
+}
+
+func near(a, b []float64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if math.Abs(a[i]-b[i]) > 1e-9 {
+			return false
+		}
+	}
+	return true
+}
+
+func TestArithmetic(t *testing.T) {
+	a := matrix(t, []float64{1, 2}, []float64{3, 4})
+	b := matrix(t, []float64{5, 6}, []float64{7, 8})
+
+	prod, err := a.Mul(b)
+	testingutils.Assert(t, err == nil, "Mul: %v", err)
+	testingutils.Equals(t, []float64{19, 22, 43, 50}, prod.Data, "a * b")
+
+	sum, _ := a.Apply("+", b, func(x, y float64) float64 { return x + y })
+	testingutils.Equals(t, []float64{6, 8, 10, 12}, sum.Data, "a + b")
+
+	v, _ := a.MulVec([]float64{1, 1})
+	testingutils.Equals(t, []float64{3, 7}, v, "a * v")
+	v, _ = a.VecMul([]float64{1, 1})
+	testingutils.Equals(t, []float64{4, 6}, v, "v * a")
+
+	testingutils.Equals(t, []float64{1, 3, 2, 4}, a.Transpose().Data, "transpose")
+
+	p, _ := a.Pow(3)
+	testingutils.Equals(t, []float64{37, 54, 81, 118}, p.Data, "a ^ 3")
+	p, _ = a.Pow(0)
+	testingutils.Equals(t, Identity(2).Data, p.Data, "a ^ 0")
+
+	wide := matrix(t, []float64{1, 2, 3}, []float64{4, 5, 6})
+	_, err = wide.Mul(wide)
+	testingutils.Equals(t, "dimension mismatch: 2x3 * 2x3", err.Error(), "wide * wide")
+	_, err = a.Apply("+", wide, nil)
+	testingutils.Equals(t, "dimension mismatch: 2x2 + 2x3", err.Error(), "a + wide")
+	_, err = wide.MulVec([]float64{1, 2})
+	testingutils.Assert(t, errors.Is(err, ErrDimension), "wide * v: %v", err)
+	_, err = FromRows([][]float64{{1, 2}, {3}})
+	testingutils.Equals(t, "dimension mismatch: row 2 has 1 columns, expected 2", err.Error(), "ragged rows")
+}
+
+func TestDecompositions(t *testing.T) {
+	a := matrix(t, []float64{2, 1, 1}, []float64{1, 3, 2}, []float64{1, 0, 0})
+
+	det, err := a.Det()
+	testingutils.Assert(t, err == nil, "Det: %v", err)
+	testingutils.Assert(t, math.Abs(det+1) < 1e-12, "det: expected -1, got %v", det)
+
+	inv, err := a.Inverse()
+	testingutils.Assert(t, err == nil, "Inverse: %v", err)
+	id, _ := a.Mul(inv)
+	testingutils.Assert(t, near(id.Data, Identity(3).Data), "a * inv(a) = %v", id.Data)
+
+	x, err := a.Solve([]float64{4, 5, 6})
+	testingutils.Assert(t, err == nil, "Solve: %v", err)
+	testingutils.Assert(t, near(x, []float64{6, 15, -23}), "solve: got %v", x)
+
+	singular := matrix(t, []float64{1, 2}, []float64{2, 4})
+	det, _ = singular.Det()
+	testingutils.Equals(t, 0.0, det, "det of a singular matrix")
+	_, err = singular.Inverse()
+	testingutils.Equals(t, ErrSingular, err, "inverse of a singular matrix")
+	_, err = singular.Solve([]float64{1, 2})
+	testingutils.Equals(t, ErrSingular, err, "singular system")
+
+	_, err = matrix(t, []float64{1, 2}).Det()
+	testingutils.Equals(t, "dimension mismatch: expected a square matrix, got 1x2", err.Error(), "det of a row")
+}
+
+func TestEigenvalues(t *testing.T) {
+	tests := []struct {
+		m        [][]float64
+		expected []complex128
+	}{
+		{[][]float64{{2, 0}, {0, 3}}, []complex128{2, 3}},
+		{[][]float64{{4, 1}, {2, 3}}, []complex128{2, 5}},
+		{[][]float64{{0, -1}, {1, 0}}, []complex128{complex(0, -1), complex(0, 1)}},
+		{[][]float64{{2, 0, 0}, {0, 3, 4}, {0, 4, 9}}, []complex128{1, 2, 11}},
+	}
+
+	for _, tt := range tests {
+		m, _ := FromRows(tt.m)
+		values, err := m.Eigenvalues(numeric.DefaultOptions)
+		testingutils.Assert(t, err == nil, "%v: %v", tt.m, err)
+		testingutils.Equals(t, len(tt.expected), len(values), "number of eigenvalues")
+		for i, v := range values {
+			testingutils.Assert(t, math.Abs(real(v)-real(tt.expected[i])) < 1e-9 && math.Abs(imag(v)-imag(tt.expected[i])) < 1e-9,
+				"%v: expected %v, got %v", tt.m, tt.expected, values)
+		}
+	}
+}
+
+func TestVectors(t *testing.T) {
+	d, err := Dot([]float64{1, 2, 3}, []float64{4, 5, 6})
+	testingutils.Assert(t, err == nil, "Dot: %v", err)
+	testingutils.Equals(t, 32.0, d, "dot")
+
+	c, err := Cross([]float64{1, 0, 0}, []float64{0, 1, 0})
+	testingutils.Assert(t, err == nil, "Cross: %v", err)
+	testingutils.Equals(t, []float64{0, 0, 1}, c, "cross")
+
+	_, err = Dot([]float64{1}, []float64{1, 2})
+	testingutils.Equals(t, "dimension mismatch: dot of vectors of length 1 and 2", err.Error(), "dot")
+	_, err = Cross([]float64{1, 2}, []float64{1, 2})
+	testingutils.Assert(t, errors.Is(err, ErrDimension), "cross: %v", err)
+}
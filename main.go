@@ -1,12 +1,42 @@
 package main
 
 import (
+	"flag"
 	"fmt"
+	"gocalc/evaluator"
+	"gocalc/object"
 	"gocalc/repl"
 	"os"
+	"path/filepath"
 )
 
 func main() {
+	engine := flag.String("engine", evaluator.TreeWalker.String(), "evaluation engine (tree or vm)")
+	path := flag.String("path", os.Getenv("GOCALC_PATH"), "directories searched for imported files, separated by "+string(os.PathListSeparator))
+	flag.Parse()
+
+	e, ok := evaluator.ParseEngine(*engine)
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown engine %q\n", *engine)
+		os.Exit(2)
+	}
+	evaluator.DefaultEngine = e
+	if *path != "" {
+		evaluator.DefaultSearchPath = filepath.SplitList(*path)
+	}
+
+	if flag.NArg() > 0 {
+		res := evaluator.New().EvalFile(flag.Arg(0))
+		if err, ok := res.(*object.Error); ok {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		if res != nil {
+			fmt.Println(res)
+		}
+		return
+	}
+
 	fmt.Printf("GoCalc. A command line calculator written in Go\n")
 	repl.Start(os.Stdin, os.Stdout)
 }
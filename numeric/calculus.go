@@ -0,0 +1,253 @@
+package numeric
+
+import (
+	"container/heap"
+	"fmt"
+	"math"
+)
+
+// Nodes and weights of the 15 point Kronrod rule, with the embedded 7 point Gauss rule
+// using the odd nodes. Only the non negative half of the symmetric nodes is listed
+var (
+	kronrodNodes = []float64{
+		0.991455371120812639206854697526329, 0.949107912342758524526189684047851,
+		0.864864423359769072789712788640926, 0.741531185599394439863864773280788,
+		0.586087235467691130294144845693013, 0.405845151377397166906606412076961,
+		0.207784955007898467600689403773245, 0,
+	}
+	kronrodWeights = []float64{
+		0.022935322010529224963732008058970, 0.063092092629978553290700663189204,
+		0.104790010322250183839876322541518, 0.140653259715525918745189590510238,
+		0.169004726639267902826583426598550, 0.190350578064785409913256402421014,
+		0.204432940075298892414161999234649, 0.209482141084727828012999174891714,
+	}
+	gaussWeights = []float64{
+		0.129484966168869693270611432679082, 0.279705391489276667901467771423780,
+		0.381830050505118944950369775488975, 0.417959183673469387755102040816327,
+	}
+)
+
+type segment struct {
+	a, b, value, err float64
+}
+
+// segments is a max heap of segments by estimated error
+type segments []segment
+
+func (s segments) Len() int            { return len(s) }
+func (s segments) Less(i, j int) bool  { return s[i].err > s[j].err }
+func (s segments) Swap(i, j int)       { s[i], s[j] = s[j], s[i] }
+func (s *segments) Push(x interface{}) { *s = append(*s, x.(segment)) }
+
+func (s *segments) Pop() interface{} {
+	last := (*s)[len(*s)-1]
+	*s = (*s)[:len(*s)-1]
+	return last
+}
+
+func kronrod(f Func, a, b float64) (segment, error) {
+	center, half := (a+b)/2, (b-a)/2
+	var k, g float64
+	for i, x := range kronrodNodes {
+		fx, err := f(center + half*x)
+		if err != nil {
+			return segment{}, err
+		}
+		if x != 0 {
+			fy, err := f(center - half*x)
+			if err != nil {
+				return segment{}, err
+			}
+			fx += fy
+		}
+		k += kronrodWeights[i] * fx
+		if i%2 == 1 {
+			g += gaussWeights[i/2] * fx
+		}
+	}
+	return segment{a: a, b: b, value: k * half, err: math.Abs((k - g) * half)}, nil
+}
+
+// GaussKronrod integrates f over [a, b] with the 15 point Gauss-Kronrod rule,
+// repeatedly splitting the segment with the largest error estimate until the
+// total error is below the tolerance, relative to the result when it exceeds one
+func GaussKronrod(f Func, a, b float64, opts Options) (float64, error) {
+	if a == b {
+		return 0, nil
+	}
+
+	first, err := kronrod(f, a, b)
+	if err != nil {
+		return 0, err
+	}
+	queue := &segments{first}
+	value, estimate := first.value, first.err
+
+	for i := 0; ; i++ {
+		if estimate <= opts.Tol*math.Max(1, math.Abs(value)) {
+			return value, nil
+		}
+		if i == opts.MaxIter {
+			return 0, noConvergence("integrate", opts)
+		}
+
+		worst := heap.Pop(queue).(segment)
+		mid := (worst.a + worst.b) / 2
+		left, err := kronrod(f, worst.a, mid)
+		if err != nil {
+			return 0, err
+		}
+		right, err := kronrod(f, mid, worst.b)
+		if err != nil {
+			return 0, err
+		}
+		heap.Push(queue, left)
+		heap.Push(queue, right)
+
+		value += left.value + right.value - worst.value
+		estimate += left.err + right.err - worst.err
+	}
+}
+
+// Simpson integrates f over [a, b] with the adaptive Simpson's rule
+func Simpson(f Func, a, b float64, opts Options) (float64, error) {
+	fa, err := f(a)
+	if err != nil {
+		return 0, err
+	}
+	fb, err := f(b)
+	if err != nil {
+		return 0, err
+	}
+	m := (a + b) / 2
+	fm, err := f(m)
+	if err != nil {
+		return 0, err
+	}
+
+	s := &simpson{f: f, splits: opts.MaxIter}
+	whole := (b - a) / 6 * (fa + 4*fm + fb)
+	res, err := s.integrate(a, b, fa, fm, fb, whole, opts.Tol*math.Max(1, math.Abs(whole)))
+	if err != nil {
+		return 0, err
+	}
+	if s.splits < 0 {
+		return 0, noConvergence("simpson", opts)
+	}
+	return res, nil
+}
+
+type simpson struct {
+	f      Func
+	splits int
+}
+
+func (s *simpson) integrate(a, b, fa, fm, fb, whole, tol float64) (float64, error) {
+	m := (a + b) / 2
+	lm, rm := (a+m)/2, (m+b)/2
+	flm, err := s.f(lm)
+	if err != nil {
+		return 0, err
+	}
+	frm, err := s.f(rm)
+	if err != nil {
+		return 0, err
+	}
+
+	left := (m - a) / 6 * (fa + 4*flm + fm)
+	right := (b - m) / 6 * (fm + 4*frm + fb)
+	delta := left + right - whole
+	if math.Abs(delta) <= 15*tol || s.splits <= 0 {
+		if math.Abs(delta) > 15*tol {
+			s.splits--
+		}
+		return left + right + delta/15, nil
+	}
+
+	s.splits--
+	l, err := s.integrate(a, m, fa, flm, fm, left, tol/2)
+	if err != nil {
+		return 0, err
+	}
+	r, err := s.integrate(m, b, fm, frm, fb, right, tol/2)
+	if err != nil {
+		return 0, err
+	}
+	return l + r, nil
+}
+
+// Derivative approximates f'(x) with central differences of decreasing steps
+// combined by Richardson extrapolation (Ridders' method)
+func Derivative(f Func, x float64, opts Options) (float64, error) {
+	const (
+		shrink = 1.4
+		safe   = 2
+		steps  = 10
+	)
+
+	h := 0.1 * math.Max(1, math.Abs(x))
+	diff := func(h float64) (float64, error) {
+		fp, err := f(x + h)
+		if err != nil {
+			return 0, err
+		}
+		fm, err := f(x - h)
+		if err != nil {
+			return 0, err
+		}
+		return (fp - fm) / (2 * h), nil
+	}
+
+	var table [steps][steps]float64
+	d, err := diff(h)
+	if err != nil {
+		return 0, err
+	}
+	table[0][0] = d
+	best, estimate := d, math.Inf(1)
+
+	for i := 1; i < steps; i++ {
+		h /= shrink
+		if table[0][i], err = diff(h); err != nil {
+			return 0, err
+		}
+		factor := shrink * shrink
+		for j := 1; j <= i; j++ {
+			table[j][i] = (table[j-1][i]*factor - table[j-1][i-1]) / (factor - 1)
+			factor *= shrink * shrink
+			e := math.Max(math.Abs(table[j][i]-table[j-1][i]), math.Abs(table[j][i]-table[j-1][i-1]))
+			if e <= estimate {
+				best, estimate = table[j][i], e
+			}
+		}
+		// higher orders stopped improving
+		if math.Abs(table[i][i]-table[i-1][i-1]) >= safe*estimate {
+			break
+		}
+	}
+
+	if math.IsNaN(best) || estimate > math.Sqrt(opts.Tol)*math.Max(1, math.Abs(best)) {
+		return 0, fmt.Errorf("deriv: %w, error estimate %g at %g", ErrNoConvergence, estimate, x)
+	}
+	return best, nil
+}
+
+// Sum adds f(k) for the integers k from a to b, compensating rounding errors
+func Sum(f Func, a, b int) (float64, error) {
+	var sum, compensation float64
+	for k := a; k <= b; k++ {
+		v, err := f(float64(k))
+		if err != nil {
+			return 0, err
+		}
+		// Neumaier's variant of Kahan summation
+		t := sum + v
+		if math.Abs(sum) >= math.Abs(v) {
+			compensation += (sum - t) + v
+		} else {
+			compensation += (v - t) + sum
+		}
+		sum = t
+	}
+	return sum + compensation, nil
+}
@@ -0,0 +1,77 @@
+package numeric
+
+import (
+	"errors"
+	"gocalc/testing_utils"
+	"math"
+	"testing"
+)
+
+func TestIntegrate(t *testing.T) {
+	tests := []struct {
+		name     string
+		f        Func
+		a, b     float64
+		expected float64
+	}{
+		{"polynomial", fn(func(x float64) float64 { return x * x }), 0, 3, 9},
+		{"sine", fn(math.Sin), 0, math.Pi, 2},
+		{"reversed bounds", fn(math.Sin), math.Pi, 0, -2},
+		{"exponential", fn(math.Exp), 0, 1, math.E - 1},
+		{"square root", fn(math.Sqrt), 0, 1, 2.0 / 3},
+		{"peak", fn(func(x float64) float64 { return 1 / (1 + 100*x*x) }), -1, 1, 0.2 * math.Atan(10)},
+		{"empty", fn(math.Sin), 1, 1, 0},
+	}
+
+	for _, tt := range tests {
+		res, err := GaussKronrod(tt.f, tt.a, tt.b, DefaultOptions)
+		testingutils.Assert(t, err == nil, "gauss-kronrod %s: %v", tt.name, err)
+		testingutils.Assert(t, near(res, tt.expected), "gauss-kronrod %s: expected %v, got %v", tt.name, tt.expected, res)
+
+		opts := Options{Tol: 1e-10, MaxIter: 10000}
+		res, err = Simpson(tt.f, tt.a, tt.b, opts)
+		testingutils.Assert(t, err == nil, "simpson %s: %v", tt.name, err)
+		testingutils.Assert(t, math.Abs(res-tt.expected) < 1e-8, "simpson %s: expected %v, got %v", tt.name, tt.expected, res)
+	}
+
+	_, err := GaussKronrod(fn(func(x float64) float64 { return 1 / x }), 0, 1, Options{Tol: 1e-12, MaxIter: 20})
+	testingutils.Assert(t, errors.Is(err, ErrNoConvergence), "divergent integral: %v", err)
+}
+
+func TestDerivative(t *testing.T) {
+	tests := []struct {
+		name     string
+		f        Func
+		x        float64
+		expected float64
+	}{
+		{"square", fn(func(x float64) float64 { return x * x }), 3, 6},
+		{"sine", fn(math.Sin), 0, 1},
+		{"exponential", fn(math.Exp), 2, math.Exp(2)},
+		{"large argument", fn(math.Log), 1000, 0.001},
+	}
+
+	for _, tt := range tests {
+		res, err := Derivative(tt.f, tt.x, DefaultOptions)
+		testingutils.Assert(t, err == nil, "%s: %v", tt.name, err)
+		testingutils.Assert(t, near(res, tt.expected), "%s: expected %v, got %v", tt.name, tt.expected, res)
+	}
+
+	_, err := Derivative(fn(math.Abs), 0, DefaultOptions)
+	testingutils.Equals(t, nil, err, "the symmetric difference of |x| at 0 is 0")
+
+	_, err = Derivative(fn(func(x float64) float64 { return math.Sqrt(x) }), 0, DefaultOptions)
+	testingutils.Assert(t, errors.Is(err, ErrNoConvergence), "undefined derivative: %v", err)
+}
+
+func TestSum(t *testing.T) {
+	res, err := Sum(fn(func(k float64) float64 { return k }), 1, 100)
+	testingutils.Assert(t, err == nil, "sum: %v", err)
+	testingutils.Equals(t, 5050.0, res, "sum of the first 100 integers")
+
+	res, _ = Sum(fn(func(k float64) float64 { return 0.1 }), 1, 10)
+	testingutils.Equals(t, 1.0, res, "compensated sum")
+
+	res, _ = Sum(fn(func(k float64) float64 { return k }), 3, 1)
+	testingutils.Equals(t, 0.0, res, "empty range")
+}
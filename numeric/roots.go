@@ -0,0 +1,352 @@
+// Package numeric implements numerical methods over real functions.
+//
+// Functions may fail, for instance when the evaluation of a user expression is
+// cancelled. Their errors are returned unchanged by the methods using them
+package numeric
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/cmplx"
+	"sort"
+)
+
+// Func is a real function of a real variable
+type Func func(x float64) (float64, error)
+
+// Options controls when iterative methods stop
+type Options struct {
+	Tol     float64 // absolute tolerance of the result
+	MaxIter int     // iterations before giving up
+}
+
+var DefaultOptions = Options{Tol: 1e-12, MaxIter: 100}
+
+var (
+	ErrNoConvergence = errors.New("no convergence")
+	ErrNotBracketed  = errors.New("root not bracketed")
+)
+
+func noConvergence(method string, opts Options) error {
+	return fmt.Errorf("%s: %w after %d iterations", method, ErrNoConvergence, opts.MaxIter)
+}
+
+func notBracketed(method string, a, b float64) error {
+	return fmt.Errorf("%s: %w, f(%g) and f(%g) have the same sign", method, ErrNotBracketed, a, b)
+}
+
+// Newton finds a root of f starting from x0. The derivative is approximated
+// with central differences when df is nil
+func Newton(f, df Func, x0 float64, opts Options) (float64, error) {
+	if df == nil {
+		df = func(x float64) (float64, error) { return centralDifference(f, x) }
+	}
+
+	x := x0
+	for i := 0; i < opts.MaxIter; i++ {
+		fx, err := f(x)
+		if err != nil {
+			return 0, err
+		}
+		if fx == 0 {
+			return x, nil
+		}
+
+		d, err := df(x)
+		if err != nil {
+			return 0, err
+		}
+		if d == 0 || math.IsNaN(d) {
+			return 0, fmt.Errorf("newton: %w, zero derivative at %g", ErrNoConvergence, x)
+		}
+
+		step := fx / d
+		x -= step
+		if math.IsNaN(x) || math.IsInf(x, 0) {
+			return 0, fmt.Errorf("newton: %w, diverged from %g", ErrNoConvergence, x0)
+		}
+		if math.Abs(step) <= opts.Tol*math.Max(1, math.Abs(x)) {
+			return x, nil
+		}
+	}
+	return 0, noConvergence("newton", opts)
+}
+
+func centralDifference(f Func, x float64) (float64, error) {
+	h := 1e-6 * math.Max(1, math.Abs(x))
+	fp, err := f(x + h)
+	if err != nil {
+		return 0, err
+	}
+	fm, err := f(x - h)
+	if err != nil {
+		return 0, err
+	}
+	return (fp - fm) / (2 * h), nil
+}
+
+// Bisection finds a root of f in [a, b], where f(a) and f(b) have different signs
+func Bisection(f Func, a, b float64, opts Options) (float64, error) {
+	fa, err := f(a)
+	if err != nil {
+		return 0, err
+	}
+	fb, err := f(b)
+	if err != nil {
+		return 0, err
+	}
+	switch {
+	case fa == 0:
+		return a, nil
+	case fb == 0:
+		return b, nil
+	case math.Signbit(fa) == math.Signbit(fb):
+		return 0, notBracketed("bisection", a, b)
+	}
+
+	for i := 0; i < opts.MaxIter; i++ {
+		m := a + (b-a)/2
+		if math.Abs(b-a)/2 <= opts.Tol {
+			return m, nil
+		}
+		fm, err := f(m)
+		if err != nil {
+			return 0, err
+		}
+		if fm == 0 {
+			return m, nil
+		}
+		if math.Signbit(fm) == math.Signbit(fa) {
+			a, fa = m, fm
+		} else {
+			b = m
+		}
+	}
+	return 0, noConvergence("bisection", opts)
+}
+
+// Brent finds a root of f in [a, b], where f(a) and f(b) have different signs,
+// combining bisection with secant steps and inverse quadratic interpolation
+func Brent(f Func, a, b float64, opts Options) (float64, error) {
+	fa, err := f(a)
+	if err != nil {
+		return 0, err
+	}
+	fb, err := f(b)
+	if err != nil {
+		return 0, err
+	}
+	switch {
+	case fa == 0:
+		return a, nil
+	case fb == 0:
+		return b, nil
+	case math.Signbit(fa) == math.Signbit(fb):
+		return 0, notBracketed("brent", a, b)
+	}
+
+	c, fc := b, fb
+	var d, e float64
+	for i := 0; i < opts.MaxIter; i++ {
+		if math.Signbit(fb) == math.Signbit(fc) {
+			c, fc = a, fa
+			d = b - a
+			e = d
+		}
+		if math.Abs(fc) < math.Abs(fb) {
+			a, b, c = b, c, b
+			fa, fb, fc = fb, fc, fb
+		}
+
+		tol := 2*math.SmallestNonzeroFloat64 + opts.Tol/2
+		m := (c - b) / 2
+		if math.Abs(m) <= tol || fb == 0 {
+			return b, nil
+		}
+
+		if math.Abs(e) >= tol && math.Abs(fa) > math.Abs(fb) {
+			var p, q float64
+			s := fb / fa
+			if a == c {
+				// secant step
+				p = 2 * m * s
+				q = 1 - s
+			} else {
+				// inverse quadratic interpolation
+				q = fa / fc
+				r := fb / fc
+				p = s * (2*m*q*(q-r) - (b-a)*(r-1))
+				q = (q - 1) * (r - 1) * (s - 1)
+			}
+			if p > 0 {
+				q = -q
+			} else {
+				p = -p
+			}
+			if 2*p < math.Min(3*m*q-math.Abs(tol*q), math.Abs(e*q)) {
+				e = d
+				d = p / q
+			} else {
+				d = m
+				e = d
+			}
+		} else {
+			d = m
+			e = d
+		}
+
+		a, fa = b, fb
+		if math.Abs(d) > tol {
+			b += d
+		} else {
+			b += math.Copysign(tol, m)
+		}
+		if fb, err = f(b); err != nil {
+			return 0, err
+		}
+	}
+	return 0, noConvergence("brent", opts)
+}
+
+// PolyRoots returns every complex root of the polynomial with the given
+// coefficients, from the highest degree down, using the Durand-Kerner method.
+// Roots with a negligible imaginary part are returned as real numbers, sorted
+// before the complex ones
+func PolyRoots(coefs []float64, opts Options) ([]complex128, error) {
+	for len(coefs) > 0 && coefs[0] == 0 {
+		coefs = coefs[1:]
+	}
+	if len(coefs) == 0 {
+		return nil, errors.New("roots: the zero polynomial has infinitely many roots")
+	}
+
+	n := len(coefs) - 1
+	if n <= 2 {
+		return sortRoots(lowDegreeRoots(coefs), opts), nil
+	}
+
+	monic := make([]complex128, len(coefs))
+	for i, c := range coefs {
+		monic[i] = complex(c/coefs[0], 0)
+	}
+	eval := func(z complex128) complex128 {
+		res := complex128(0)
+		for _, c := range monic {
+			res = res*z + c
+		}
+		return res
+	}
+
+	roots := make([]complex128, n)
+	seed := complex(0.4, 0.9)
+	for i := range roots {
+		roots[i] = cmplx.Pow(seed, complex(float64(i), 0))
+	}
+
+	converged := n == 0
+	for iter := 0; iter < opts.MaxIter*10 && !converged; iter++ {
+		converged = true
+		for i := range roots {
+			value := eval(roots[i])
+			if value == 0 {
+				continue
+			}
+			den := complex128(1)
+			for j := range roots {
+				if i != j {
+					den *= roots[i] - roots[j]
+				}
+			}
+			if den == 0 {
+				// coincident estimates of a multiple root
+				den = complex(opts.Tol, 0)
+			}
+			step := value / den
+			roots[i] -= step
+			if cmplx.Abs(step) > opts.Tol*math.Max(1, cmplx.Abs(roots[i])) {
+				converged = false
+			}
+		}
+	}
+	if !converged {
+		return nil, noConvergence("roots", opts)
+	}
+
+	// polish the roots with Newton steps on the polynomial
+	for i, z := range roots {
+		for step := 0; step < 3; step++ {
+			p, dp := complex128(0), complex128(0)
+			for _, c := range monic {
+				dp = dp*z + p
+				p = p*z + c
+			}
+			if dp == 0 || p == 0 {
+				break
+			}
+			z -= p / dp
+		}
+		if cmplx.IsNaN(z) || cmplx.IsInf(z) {
+			continue
+		}
+		// rounding errors keep Newton's method off exact integer roots
+		if whole := complex(math.Round(real(z)), math.Round(imag(z))); cmplx.Abs(eval(whole)) <= cmplx.Abs(eval(z)) {
+			z = whole
+		}
+		roots[i] = z
+	}
+
+	return sortRoots(roots, opts), nil
+}
+
+// lowDegreeRoots solves polynomials up to the second degree in closed form
+func lowDegreeRoots(coefs []float64) []complex128 {
+	switch len(coefs) {
+	case 1:
+		return []complex128{}
+	case 2:
+		return []complex128{complex(-coefs[1]/coefs[0], 0)}
+	}
+
+	a, b, c := coefs[0], coefs[1], coefs[2]
+	disc := b*b - 4*a*c
+	if disc < 0 {
+		re, im := -b/(2*a), math.Sqrt(-disc)/(2*a)
+		return []complex128{complex(re, -im), complex(re, im)}
+	}
+	// avoid the cancellation of -b + sqrt(disc) when b and sqrt(disc) are close
+	q := -(b + math.Copysign(math.Sqrt(disc), b)) / 2
+	if q == 0 {
+		return []complex128{0, 0}
+	}
+	return []complex128{complex(q/a, 0), complex(c/q, 0)}
+}
+
+// sortRoots cleans negligible real and imaginary parts and sorts real roots
+// before complex ones
+func sortRoots(roots []complex128, opts Options) []complex128 {
+	// multiple roots are only found to about the square root of the tolerance
+	eps := math.Sqrt(opts.Tol)
+	for i, r := range roots {
+		scale := math.Max(1, cmplx.Abs(r))
+		re, im := real(r), imag(r)
+		if math.Abs(im) <= eps*scale {
+			im = 0
+		}
+		if math.Abs(re) <= opts.Tol*scale {
+			re = 0
+		}
+		roots[i] = complex(re, im)
+	}
+	sort.Slice(roots, func(i, j int) bool {
+		ri, rj := roots[i], roots[j]
+		if (imag(ri) == 0) != (imag(rj) == 0) {
+			return imag(ri) == 0
+		}
+		if real(ri) != real(rj) {
+			return real(ri) < real(rj)
+		}
+		return imag(ri) < imag(rj)
+	})
+	return roots
+}
@@ -0,0 +1,95 @@
+package numeric
+
+import (
+	"errors"
+	"gocalc/testing_utils"
+	"math"
+	"testing"
+)
+
+func fn(f func(float64) float64) Func {
+	return func(x float64) (float64, error) { return f(x), nil }
+}
+
+func near(a, b float64) bool { return math.Abs(a-b) <= 1e-9*math.Max(1, math.Abs(b)) }
+
+func TestRootFinders(t *testing.T) {
+	square := fn(func(x float64) float64 { return x*x - 2 })
+	cosine := fn(func(x float64) float64 { return math.Cos(x) - x })
+
+	tests := []struct {
+		name     string
+		solve    func() (float64, error)
+		expected float64
+	}{
+		{"newton", func() (float64, error) { return Newton(square, nil, 1, DefaultOptions) }, math.Sqrt2},
+		{"newton with derivative", func() (float64, error) {
+			return Newton(square, fn(func(x float64) float64 { return 2 * x }), 1, DefaultOptions)
+		}, math.Sqrt2},
+		{"bisection", func() (float64, error) { return Bisection(square, 0, 2, DefaultOptions) }, math.Sqrt2},
+		{"brent", func() (float64, error) { return Brent(square, 0, 2, DefaultOptions) }, math.Sqrt2},
+		{"brent cosine", func() (float64, error) { return Brent(cosine, 0, 1, DefaultOptions) }, 0.7390851332151607},
+		{"brent endpoint", func() (float64, error) { return Brent(fn(math.Sin), 0, 1, DefaultOptions) }, 0},
+	}
+
+	for _, tt := range tests {
+		root, err := tt.solve()
+		testingutils.Assert(t, err == nil, "%s: %v", tt.name, err)
+		testingutils.Assert(t, near(root, tt.expected), "%s: expected %v, got %v", tt.name, tt.expected, root)
+	}
+}
+
+func TestRootFinderErrors(t *testing.T) {
+	square := fn(func(x float64) float64 { return x*x + 1 })
+
+	_, err := Newton(square, nil, 0.5, Options{Tol: 1e-12, MaxIter: 20})
+	testingutils.Assert(t, errors.Is(err, ErrNoConvergence), "newton: %v", err)
+
+	_, err = Newton(square, nil, 0, DefaultOptions)
+	testingutils.Equals(t, "newton: no convergence, zero derivative at 0", err.Error(), "zero derivative")
+
+	_, err = Bisection(square, -1, 1, DefaultOptions)
+	testingutils.Equals(t, "bisection: root not bracketed, f(-1) and f(1) have the same sign", err.Error(), "bisection")
+
+	_, err = Brent(square, -1, 1, DefaultOptions)
+	testingutils.Assert(t, errors.Is(err, ErrNotBracketed), "brent: %v", err)
+
+	_, err = Bisection(fn(math.Sin), 3, 4, Options{Tol: 1e-12, MaxIter: 5})
+	testingutils.Equals(t, "bisection: no convergence after 5 iterations", err.Error(), "iterations")
+
+	failure := errors.New("failure")
+	_, err = Brent(func(float64) (float64, error) { return 0, failure }, 0, 1, DefaultOptions)
+	testingutils.Equals(t, failure, err, "function errors are returned unchanged")
+}
+
+func TestPolyRoots(t *testing.T) {
+	tests := []struct {
+		coefs    []float64
+		expected []complex128
+	}{
+		{[]float64{1, 0, -1}, []complex128{-1, 1}},
+		{[]float64{0, 2, -4}, []complex128{2}},
+		{[]float64{1, -6, 11, -6}, []complex128{1, 2, 3}},
+		{[]float64{1, 0, 1}, []complex128{complex(0, -1), complex(0, 1)}},
+		{[]float64{1, 0, 0, -1}, []complex128{1, complex(-0.5, -math.Sqrt(3)/2), complex(-0.5, math.Sqrt(3)/2)}},
+		{[]float64{1, -2, 1}, []complex128{1, 1}},
+		{[]float64{5}, []complex128{}},
+	}
+
+	roots, err := PolyRoots([]float64{1, -6, 11, -6}, DefaultOptions)
+	testingutils.Assert(t, err == nil, "%v", err)
+	testingutils.Equals(t, []complex128{1, 2, 3}, roots, "integer roots are exact")
+
+	for _, tt := range tests {
+		roots, err := PolyRoots(tt.coefs, DefaultOptions)
+		testingutils.Assert(t, err == nil, "%v: %v", tt.coefs, err)
+		testingutils.Equals(t, len(tt.expected), len(roots), "number of roots")
+		for i, r := range roots {
+			ok := math.Abs(real(r)-real(tt.expected[i])) < 1e-6 && math.Abs(imag(r)-imag(tt.expected[i])) < 1e-6
+			testingutils.Assert(t, ok, "%v: expected %v, got %v", tt.coefs, tt.expected, roots)
+		}
+	}
+
+	_, err = PolyRoots([]float64{0, 0}, DefaultOptions)
+	testingutils.Assert(t, err != nil, "the zero polynomial has no finite set of roots")
+}
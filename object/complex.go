@@ -0,0 +1,23 @@
+package object
+
+import (
+	"fmt"
+	"math"
+)
+
+type Complex struct {
+	Value complex128
+}
+
+func (c *Complex) String() string {
+	re, im := real(c.Value), imag(c.Value)
+	sign := "+"
+	if math.Signbit(im) {
+		sign, im = "-", -im
+	}
+	return fmt.Sprintf("%v%s%vi", re, sign, im)
+}
+
+func (c *Complex) Type() ObjectType { return COMPLEX }
+
+func (c *Complex) TypeS() string { return c.Type().Stringf(c.String()) }
@@ -0,0 +1,166 @@
+package object
+
+import (
+	"fmt"
+	"gocalc/linalg"
+	"time"
+)
+
+// FromGo converts a Go value into its object representation.
+// Supported values are numbers, bools, strings, times, durations, nil, slices and string keyed maps
+func FromGo(v interface{}) (Object, error) {
+	switch v := v.(type) {
+	case nil:
+		return &Null{}, nil
+	case Object:
+		return v, nil
+	case float64:
+		return &Float{Value: v}, nil
+	case float32:
+		return &Float{Value: float64(v)}, nil
+	case int:
+		return &Float{Value: float64(v)}, nil
+	case int32:
+		return &Float{Value: float64(v)}, nil
+	case int64:
+		return &Float{Value: float64(v)}, nil
+	case uint:
+		return &Float{Value: float64(v)}, nil
+	case uint32:
+		return &Float{Value: float64(v)}, nil
+	case uint64:
+		return &Float{Value: float64(v)}, nil
+	case complex128:
+		return &Complex{Value: v}, nil
+	case time.Time:
+		return &Time{Value: v}, nil
+	case time.Duration:
+		return &Duration{Value: v}, nil
+	case bool:
+		return &Boolean{Value: v}, nil
+	case string:
+		return &String{Value: v}, nil
+	case []float64:
+		values := make([]Object, len(v))
+		for i, f := range v {
+			values[i] = &Float{Value: f}
+		}
+		return &List{Values: values}, nil
+	case [][]float64:
+		m, err := linalg.FromRows(v)
+		if err != nil {
+			return nil, err
+		}
+		return &Matrix{Value: m}, nil
+	case []interface{}:
+		values := make([]Object, len(v))
+		for i, elem := range v {
+			obj, err := FromGo(elem)
+			if err != nil {
+				return nil, err
+			}
+			values[i] = obj
+		}
+		return &List{Values: values}, nil
+	case map[string]float64:
+		pairs := make(map[string]Object, len(v))
+		for key, f := range v {
+			pairs[key] = &Float{Value: f}
+		}
+		return &Map{Pairs: pairs}, nil
+	case map[string]interface{}:
+		pairs := make(map[string]Object, len(v))
+		for key, elem := range v {
+			obj, err := FromGo(elem)
+			if err != nil {
+				return nil, err
+			}
+			pairs[key] = obj
+		}
+		return &Map{Pairs: pairs}, nil
+	}
+
+	return nil, fmt.Errorf("cannot convert value of type %T", v)
+}
+
+// ToGo converts an object into a plain Go value.
+// Lists made only of floats are returned as []float64
+func ToGo(obj Object) (interface{}, error) {
+	switch obj := obj.(type) {
+	case nil, *Null:
+		return nil, nil
+	case *Error:
+		return nil, obj
+	case *Float:
+		return obj.Value, nil
+	case *Integer:
+		return obj.Value, nil
+	case *Complex:
+		return obj.Value, nil
+	case *Vector:
+		return append([]float64{}, obj.Values...), nil
+	case *Matrix:
+		rows := make([][]float64, obj.Value.Rows)
+		for i := range rows {
+			rows[i] = obj.Value.Row(i)
+		}
+		return rows, nil
+	case *Time:
+		return obj.Value, nil
+	case *Duration:
+		return obj.Value, nil
+	case *Boolean:
+		return obj.Value, nil
+	case *String:
+		return obj.Value, nil
+	case *Type:
+		return obj.String(), nil
+	case *List:
+		if floats, ok := listToFloats(obj); ok {
+			return floats, nil
+		}
+		values := make([]interface{}, len(obj.Values))
+		for i, elem := range obj.Values {
+			v, err := ToGo(elem)
+			if err != nil {
+				return nil, err
+			}
+			values[i] = v
+		}
+		return values, nil
+	case *Record:
+		fields := make(map[string]interface{}, len(obj.Values))
+		for i, elem := range obj.Values {
+			v, err := ToGo(elem)
+			if err != nil {
+				return nil, err
+			}
+			fields[obj.Of.Fields[i]] = v
+		}
+		return fields, nil
+	case *Map:
+		pairs := make(map[string]interface{}, len(obj.Pairs))
+		for key, elem := range obj.Pairs {
+			v, err := ToGo(elem)
+			if err != nil {
+				return nil, err
+			}
+			pairs[key] = v
+		}
+		return pairs, nil
+	}
+
+	return nil, fmt.Errorf("cannot convert value of type %s", obj.Type())
+}
+
+func listToFloats(list *List) ([]float64, bool) {
+	floats := make([]float64, len(list.Values))
+	for i, elem := range list.Values {
+		f, ok := elem.(*Float)
+		if !ok {
+			return nil, false
+		}
+		floats[i] = f.Value
+	}
+	return floats, true
+}
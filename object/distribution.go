@@ -0,0 +1,11 @@
+package object
+
+import "gocalc/stats"
+
+type Distribution struct {
+	Value stats.Distribution
+}
+
+func (d *Distribution) Type() ObjectType { return DISTRIBUTION }
+func (d *Distribution) TypeS() string    { return d.Type().Stringf(d.String()) }
+func (d *Distribution) String() string   { return d.Value.String() }
@@ -5,12 +5,70 @@ const (
 	UNKNOWN_PREFIX_OPERATOR_ERROR = "Unknown operator %s%s"
 	IDENTIFIER_NOT_FOUND_ERROR    = "Identifier not found %s"
 	SYNTAX_ERROR                  = "Syntax error: \n\t\t%s"
+	WRONG_ARGUMENTS_ERROR         = "%s expects arguments %s, got %d"
+	ARGUMENT_TYPE_ERROR           = "Argument %d of %s must be of type %s, got %s"
+	CANCELLED_ERROR               = "Evaluation cancelled: %s"
+	DEPTH_LIMIT_ERROR             = "Maximum recursion depth exceeded (%d)"
+	LIST_LIMIT_ERROR              = "Maximum list length exceeded (%d > %d)"
+	STRING_LIMIT_ERROR            = "Maximum string length exceeded (%d > %d)"
+	ITERATION_LIMIT_ERROR         = "Maximum number of iterations exceeded (%d)"
+	ALLOCATION_LIMIT_ERROR        = "Maximum number of allocated objects exceeded (%d)"
+	BROADCAST_ERROR               = "Cannot broadcast lists of length %d and %d"
+	UNKNOWN_MODULE_ERROR          = "Unknown module %q"
+	MODULE_NOT_FOUND_ERROR        = "Module %q not found"
+	MODULE_NAME_ERROR             = "Module %q is not a valid identifier"
+	IMPORT_CYCLE_ERROR            = "Import cycle: %s"
+	MODULE_ERROR                  = "In module %s: %s"
+	MEMBER_NOT_FOUND_ERROR        = "%s has no member %s"
+	NO_MEMBERS_ERROR              = "Values of type %s have no members"
+	TYPE_ERROR                    = "Type error: \n\t\t%s"
+	UNKNOWN_TYPE_ERROR            = "Unknown type %s"
+	ANNOTATION_ERROR              = "Cannot assign %s to %s of type %s"
+	NOT_CALLABLE_ERROR            = "Cannot call value of type %s"
+	CONVERSION_ERROR              = "Cannot convert %s to %s"
+	CONST_ASSIGNMENT_ERROR        = "Cannot assign to constant %s"
+	NOT_BUILTIN_ERROR             = "%s is not a builtin"
+	UNPACK_TYPE_ERROR             = "Cannot unpack value of type %s"
+	UNPACK_LENGTH_ERROR           = "Cannot unpack %d values into %d variables"
+	ALLOCATION_SIZE_ERROR         = "Cannot allocate %g elements (> %d)"
 )
 
+type ErrorKind byte
+
+const (
+	ERR_RUNTIME ErrorKind = iota
+	ERR_SYNTAX
+	ERR_CANCELLED
+	ERR_DEPTH_LIMIT
+	ERR_LIST_LIMIT
+	ERR_STRING_LIMIT
+	ERR_ITERATION_LIMIT
+	ERR_ALLOCATION_LIMIT
+	ERR_NO_CONVERGENCE
+	ERR_TYPE
+)
+
+var errorKindNames = []string{
+	ERR_RUNTIME:          "RuntimeError",
+	ERR_SYNTAX:           "SyntaxError",
+	ERR_CANCELLED:        "Cancelled",
+	ERR_DEPTH_LIMIT:      "DepthLimit",
+	ERR_LIST_LIMIT:       "ListLimit",
+	ERR_STRING_LIMIT:     "StringLimit",
+	ERR_ITERATION_LIMIT:  "IterationLimit",
+	ERR_ALLOCATION_LIMIT: "AllocationLimit",
+	ERR_NO_CONVERGENCE:   "NoConvergence",
+	ERR_TYPE:             "TypeError",
+}
+
+func (k ErrorKind) String() string { return errorKindNames[k] }
+
 type Error struct {
+	Kind    ErrorKind
 	Message string
 }
 
 func (e *Error) Type() ObjectType { return ERROR }
 func (e *Error) TypeS() string    { return e.Type().Stringf(e.Message) }
 func (e *Error) String() string   { return e.Message }
+func (e *Error) Error() string    { return e.Message }
@@ -0,0 +1,37 @@
+package object
+
+import (
+	"bytes"
+	"sort"
+)
+
+type Map struct {
+	Pairs map[string]Object
+}
+
+func (m *Map) Type() ObjectType { return MAP }
+func (m *Map) TypeS() string    { return MAP.Stringf(m.String()) }
+func (m *Map) String() string {
+	var buf bytes.Buffer
+	buf.WriteString("{")
+	for i, key := range m.Keys() {
+		if i != 0 {
+			buf.WriteString(", ")
+		}
+		buf.WriteString(key)
+		buf.WriteString(": ")
+		buf.WriteString(m.Pairs[key].String())
+	}
+	buf.WriteString("}")
+	return buf.String()
+}
+
+// Keys returns the keys of the map in a stable order
+func (m *Map) Keys() []string {
+	keys := make([]string, 0, len(m.Pairs))
+	for key := range m.Pairs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,47 @@
+package object
+
+import (
+	"bytes"
+	"fmt"
+	"gocalc/linalg"
+)
+
+type Vector struct {
+	Values []float64
+}
+
+func (v *Vector) Type() ObjectType { return VECTOR }
+func (v *Vector) TypeS() string    { return v.Type().Stringf(v.String()) }
+func (v *Vector) String() string   { return formatFloats(v.Values) }
+
+type Matrix struct {
+	Value *linalg.Matrix
+}
+
+func (m *Matrix) Type() ObjectType { return MATRIX }
+func (m *Matrix) TypeS() string    { return m.Type().Stringf(m.Value.Dims(), m.String()) }
+func (m *Matrix) String() string {
+	var buf bytes.Buffer
+	buf.WriteString("[")
+	for i := 0; i < m.Value.Rows; i++ {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		buf.WriteString(formatFloats(m.Value.Row(i)))
+	}
+	buf.WriteString("]")
+	return buf.String()
+}
+
+func formatFloats(values []float64) string {
+	var buf bytes.Buffer
+	buf.WriteString("[")
+	for i, v := range values {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		buf.WriteString(fmt.Sprint(v))
+	}
+	buf.WriteString("]")
+	return buf.String()
+}
@@ -0,0 +1,12 @@
+package object
+
+// Module is a namespace of values loaded with import
+type Module struct {
+	Name    string
+	Path    string // file the module was loaded from, empty for builtin modules
+	Members map[string]Object
+}
+
+func (m *Module) Type() ObjectType { return MODULE }
+func (m *Module) TypeS() string    { return m.Type().Stringf(m.Name) }
+func (m *Module) String() string   { return "module " + m.Name }
@@ -23,6 +23,19 @@ const (
 	STRING
 	TYPE
 	LIST
+	MAP
+	EXPRESSION
+	COMPLEX
+	VECTOR
+	MATRIX
+	TIME
+	DURATION
+	DISTRIBUTION
+	MODULE
+	RECORD
+
+	// ANY is not the type of any value, it matches every type in a signature
+	ANY
 )
 
 var typeNames = []string{
@@ -35,9 +48,30 @@ var typeNames = []string{
 	TYPE:            "Type",
 	NATIVE_FUNCTION: "NativeFn",
 	LIST:            "List",
+	MAP:             "Map",
+	EXPRESSION:      "Expr",
+	COMPLEX:         "Complex",
+	VECTOR:          "Vector",
+	MATRIX:          "Matrix",
+	TIME:            "Time",
+	DURATION:        "Duration",
+	DISTRIBUTION:    "Dist",
+	MODULE:          "Module",
+	RECORD:          "Record",
+	ANY:             "Any",
 }
 
 func (o ObjectType) String() string { return typeNames[o] }
+
+// ParseType returns the type with the given name
+func ParseType(name string) (ObjectType, bool) {
+	for t, n := range typeNames {
+		if n == name {
+			return ObjectType(t), true
+		}
+	}
+	return NULL, false
+}
 func (o ObjectType) Stringf(params ...string) string {
 	if len(params) == 0 {
 		return fmt.Sprintf("<%s>", o)
@@ -0,0 +1,41 @@
+package object
+
+import "strings"
+
+// RecordType is a type declared with a type statement
+type RecordType struct {
+	Name   string
+	Fields []string
+}
+
+// NewRecordType returns the type of the records with the given fields
+func NewRecordType(name string, fields ...string) *Type {
+	return &Type{Value: RECORD, Record: &RecordType{Name: name, Fields: fields}}
+}
+
+// Field returns the index of the named field, or -1
+func (rt *RecordType) Field(name string) int {
+	for i, f := range rt.Fields {
+		if f == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// Record is a value of a record type, holding one value per field
+type Record struct {
+	Of     *RecordType
+	Values []Object
+}
+
+func (r *Record) Type() ObjectType { return RECORD }
+func (r *Record) TypeS() string    { return r.Type().Stringf(r.Of.Name) }
+
+func (r *Record) String() string {
+	fields := make([]string, len(r.Values))
+	for i, v := range r.Values {
+		fields[i] = r.Of.Fields[i] + ": " + v.String()
+	}
+	return r.Of.Name + "{" + strings.Join(fields, ", ") + "}"
+}
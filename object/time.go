@@ -0,0 +1,22 @@
+package object
+
+import (
+	"gocalc/calendar"
+	"time"
+)
+
+type Time struct {
+	Value time.Time
+}
+
+func (t *Time) Type() ObjectType { return TIME }
+func (t *Time) TypeS() string    { return t.Type().Stringf(t.String()) }
+func (t *Time) String() string   { return calendar.FormatTime(t.Value) }
+
+type Duration struct {
+	Value time.Duration
+}
+
+func (d *Duration) Type() ObjectType { return DURATION }
+func (d *Duration) TypeS() string    { return d.Type().Stringf(d.String()) }
+func (d *Duration) String() string   { return calendar.FormatDuration(d.Value) }
@@ -2,8 +2,25 @@ package object
 
 type Type struct {
 	Value ObjectType
+	// Record is the declared type of records, nil for the builtin types
+	Record *RecordType
 }
 
 func (t *Type) Type() ObjectType { return TYPE }
-func (t *Type) String() string   { return t.Value.String() }
-func (t *Type) TypeS() string    { return t.Type().Stringf(t.Value.String()) }
+func (t *Type) TypeS() string    { return t.Type().Stringf(t.String()) }
+
+// Matches reports whether obj is a value of type t
+func (t *Type) Matches(obj Object) bool {
+	if t.Record != nil {
+		rec, ok := obj.(*Record)
+		return ok && rec.Of == t.Record
+	}
+	return t.Value == ANY || obj.Type() == t.Value
+}
+
+func (t *Type) String() string {
+	if t.Record != nil {
+		return t.Record.Name
+	}
+	return t.Value.String()
+}
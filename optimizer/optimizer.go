@@ -0,0 +1,241 @@
+// Package optimizer rewrites programs into equivalent ones that are cheaper to evaluate.
+//
+// Constant subexpressions are folded and known constants are inlined. Identities
+// such as x * 1 or --x are only simplified when the operands are known to be
+// numeric, see AssumeNumeric, as the operators fail on other types, like !!1 or
+// "a" + 0. Operations that fail, like a division by zero, are left for the
+// evaluator to report
+package optimizer
+
+import (
+	"gocalc/ast"
+	"math"
+)
+
+type Optimizer struct {
+	constants map[string]float64
+	assigned  map[string]bool
+	kept      map[string]bool
+	numeric   bool
+}
+
+// New returns an optimizer that inlines the given constants
+func New(constants map[string]float64) *Optimizer {
+	if constants == nil {
+		constants = map[string]float64{}
+	}
+	return &Optimizer{constants: constants, assigned: map[string]bool{}, kept: map[string]bool{}}
+}
+
+// Keep leaves the arguments of calls to the named functions as written,
+// for functions that receive their arguments unevaluated
+func (o *Optimizer) Keep(functions ...string) {
+	for _, name := range functions {
+		o.kept[name] = true
+	}
+}
+
+// AssumeNumeric simplifies identities such as x * 1, x + 0 or --x for programs
+// whose operands are all numbers, like symbolic expressions
+func (o *Optimizer) AssumeNumeric() { o.numeric = true }
+
+// Optimize returns an optimised copy of program
+func Optimize(program *ast.Program, constants map[string]float64) *ast.Program {
+	return New(constants).Program(program)
+}
+
+// Program returns an optimised copy of program. Constants assigned
+// anywhere in the program are not inlined
+func (o *Optimizer) Program(program *ast.Program) *ast.Program {
+	for _, s := range program.Statements {
+		switch s := s.(type) {
+		case *ast.AssignmentStatement:
+			for _, name := range s.Names() {
+				o.assigned[name.Value] = true
+			}
+		case *ast.CompoundAssignmentStatement:
+			o.assigned[s.Name.Value] = true
+		case *ast.DestructuringStatement:
+			for _, name := range s.Names {
+				o.assigned[name.Value] = true
+			}
+		case *ast.ImportStatement:
+			o.assigned[s.Name()] = true
+		case *ast.TypeStatement:
+			o.assigned[s.Name.Value] = true
+		}
+	}
+
+	res := &ast.Program{Statements: make([]ast.Statement, len(program.Statements))}
+	for i, s := range program.Statements {
+		res.Statements[i] = o.Statement(s)
+	}
+	return res
+}
+
+func (o *Optimizer) Statement(s ast.Statement) ast.Statement {
+	switch s := s.(type) {
+	case *ast.AssignmentStatement:
+		return &ast.AssignmentStatement{Token: s.Token, Name: s.Name, Annotation: s.Annotation, Const: s.Const, Chain: s.Chain, Doc: s.Doc, Value: o.Expression(s.Value)}
+	case *ast.CompoundAssignmentStatement:
+		return &ast.CompoundAssignmentStatement{Token: s.Token, Name: s.Name, Operator: s.Operator, Value: o.Expression(s.Value)}
+	case *ast.DestructuringStatement:
+		values := make([]ast.Expression, len(s.Values))
+		for i, v := range s.Values {
+			values[i] = o.Expression(v)
+		}
+		return &ast.DestructuringStatement{Token: s.Token, Names: s.Names, Values: values, List: s.List}
+	case *ast.ExpressionStatement:
+		return &ast.ExpressionStatement{Token: s.Token, Expression: o.Expression(s.Expression)}
+	}
+	return s
+}
+
+// Inlined reports whether name is one of the constants inlined by o
+func (o *Optimizer) Inlined(name string) bool {
+	_, ok := o.constants[name]
+	return ok && !o.assigned[name]
+}
+
+// Expression returns an optimised copy of e
+func (o *Optimizer) Expression(e ast.Expression) ast.Expression {
+	switch e := e.(type) {
+	case *ast.Identifier:
+		if o.Inlined(e.Value) {
+			return ast.NewFloatLiteral(o.constants[e.Value])
+		}
+	case *ast.PrefixExpression:
+		return o.prefix(e.Operator, o.Expression(e.Right), e)
+	case *ast.InfixExpression:
+		return o.infix(e.Operator, o.Expression(e.Left), o.Expression(e.Right), e)
+	case *ast.ListLiteral:
+		values := make([]ast.Expression, len(e.Values))
+		for i, v := range e.Values {
+			values[i] = o.Expression(v)
+		}
+		return &ast.ListLiteral{Token: e.Token, Values: values}
+	case *ast.MemberExpression:
+		return &ast.MemberExpression{Token: e.Token, Object: o.Expression(e.Object), Member: e.Member}
+	case *ast.CallExpression:
+		if o.kept[e.Function.TokenLiteral()] {
+			return e
+		}
+		args := make([]ast.Expression, len(e.Arguments))
+		for i, a := range e.Arguments {
+			args[i] = o.Expression(a)
+		}
+		fn := e.Function
+		if _, ok := fn.(*ast.Identifier); !ok {
+			fn = o.Expression(fn)
+		}
+		return &ast.CallExpression{Token: e.Token, Function: fn, Arguments: args}
+	}
+	return e
+}
+
+func (o *Optimizer) prefix(operator string, right ast.Expression, orig *ast.PrefixExpression) ast.Expression {
+	switch r := right.(type) {
+	case *ast.FloatLiteral:
+		if operator == "-" {
+			return ast.NewFloatLiteral(-r.Value)
+		}
+	case *ast.BooleanLiteral:
+		if operator == "!" {
+			return ast.NewBooleanLiteral(!r.Value)
+		}
+	case *ast.PrefixExpression:
+		// --x, !!x is left as ! fails on numbers
+		if o.numeric && operator == "-" && r.Operator == "-" {
+			return r.Right
+		}
+	}
+
+	return &ast.PrefixExpression{Token: orig.Token, Operator: operator, Right: right}
+}
+
+func (o *Optimizer) infix(operator string, left, right ast.Expression, orig *ast.InfixExpression) ast.Expression {
+	lf, lok := left.(*ast.FloatLiteral)
+	rf, rok := right.(*ast.FloatLiteral)
+	if lok && rok {
+		if folded := foldFloat(operator, lf.Value, rf.Value); folded != nil {
+			return folded
+		}
+	}
+
+	lb, lok := left.(*ast.BooleanLiteral)
+	rb, rok := right.(*ast.BooleanLiteral)
+	if lok && rok {
+		if folded := foldBoolean(operator, lb.Value, rb.Value); folded != nil {
+			return folded
+		}
+	}
+
+	if o.numeric {
+		switch {
+		case isFloat(right, 0) && (operator == "+" || operator == "-"):
+			return left
+		case isFloat(left, 0) && operator == "+":
+			return right
+		case isFloat(left, 0) && operator == "-":
+			return o.prefix("-", right, ast.NewPrefixExpression("-", right))
+		case isFloat(right, 1) && (operator == "*" || operator == "/" || operator == "^"):
+			return left
+		case isFloat(left, 1) && operator == "*":
+			return right
+		}
+	}
+
+	return &ast.InfixExpression{Token: orig.Token, Operator: operator, Left: left, Right: right}
+}
+
+func isFloat(e ast.Expression, value float64) bool {
+	f, ok := e.(*ast.FloatLiteral)
+	return ok && f.Value == value
+}
+
+// foldFloat mirrors the float operators of the evaluator
+func foldFloat(operator string, x1, x2 float64) ast.Expression {
+	switch operator {
+	case "+":
+		return ast.NewFloatLiteral(x1 + x2)
+	case "-":
+		return ast.NewFloatLiteral(x1 - x2)
+	case "*":
+		return ast.NewFloatLiteral(x1 * x2)
+	case "^":
+		return ast.NewFloatLiteral(math.Pow(x1, x2))
+	case "/":
+		if x2 == 0 {
+			return nil
+		}
+		return ast.NewFloatLiteral(x1 / x2)
+	case ">=":
+		return ast.NewBooleanLiteral(x1 >= x2)
+	case ">":
+		return ast.NewBooleanLiteral(x1 > x2)
+	case "<":
+		return ast.NewBooleanLiteral(x1 < x2)
+	case "<=":
+		return ast.NewBooleanLiteral(x1 <= x2)
+	case "==":
+		return ast.NewBooleanLiteral(x1 == x2)
+	case "!=":
+		return ast.NewBooleanLiteral(x1 != x2)
+	}
+	return nil
+}
+
+// foldBoolean mirrors the boolean operators of the evaluator
+func foldBoolean(operator string, x1, x2 bool) ast.Expression {
+	switch operator {
+	case "==":
+		return ast.NewBooleanLiteral(x1 == x2)
+	case "!=":
+		return ast.NewBooleanLiteral(x1 != x2)
+	case "&&":
+		return ast.NewBooleanLiteral(x1 && x2)
+	case "||":
+		return ast.NewBooleanLiteral(x1 || x2)
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package optimizer
+
+import (
+	"gocalc/lexer"
+	"gocalc/parser"
+	"gocalc/testing_utils"
+	"math"
+	"testing"
+)
+
+func TestOptimize(t *testing.T) {
+	constants := map[string]float64{"pi": math.Pi, "two": 2}
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"1 + 2 * 3", "7"},
+		{"2 * pi * r", "(6.283185307179586 * r)"},
+		{"x * 1", "(x * 1)"},
+		{"1 * x", "(1 * x)"},
+		{"x + 0", "(x + 0)"},
+		{"0 - x", "(0 - x)"},
+		{"x ^ 1", "(x ^ 1)"},
+		{"--x", "(-(-x))"},
+		{"!!b", "(!(!b))"},
+		{"- -2", "2"},
+		{"!!true", "true"},
+		{"(x * (3 - 2)) + (4 - 4)", "((x * 1) + 0)"},
+		{"1 / 0", "(1 / 0)"},
+		{"x / (2 - 2)", "(x / 0)"},
+		{"2 > 1 && true", "true"},
+		{"!(1 == 2)", "true"},
+		{"sin(pi / two) + [1 + 1, y * 1]", "(sin(1.5707963267948966) + [2, (y * 1)])"},
+		{"two = 3; two * 2", "two = 3;(two * 2)"},
+	}
+
+	for _, tt := range tests {
+		p := parser.New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		testingutils.Assert(t, !p.HasErrors(), "parser errors: %v", p.Errors())
+
+		before := program.String()
+		actual := Optimize(program, constants).String()
+		testingutils.Equals(t, tt.expected, actual, tt.input)
+		testingutils.Equals(t, before, program.String(), "the input program must not be modified")
+	}
+}
+
+func TestAssumeNumeric(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"x * 1", "x"},
+		{"1 * x", "x"},
+		{"x + 0", "x"},
+		{"0 + x", "x"},
+		{"x - 0", "x"},
+		{"0 - x", "(-x)"},
+		{"x / 1", "x"},
+		{"x ^ 1", "x"},
+		{"--x", "x"},
+		{"-(-(x + 0))", "x"},
+		{"0 - -x", "x"},
+		{"(x * (3 - 2)) + (4 - 4)", "x"},
+	}
+
+	for _, tt := range tests {
+		p := parser.New(lexer.New(tt.input))
+		o := New(nil)
+		o.AssumeNumeric()
+		testingutils.Equals(t, tt.expected, o.Program(p.ParseProgram()).String(), tt.input)
+	}
+}
+
+func TestKeep(t *testing.T) {
+	p := parser.New(lexer.New("diff(x * (3 - 2), x) + sin(x * (3 - 2)) + '(x * (3 - 2))"))
+	o := New(nil)
+	o.Keep("diff")
+	actual := o.Program(p.ParseProgram()).String()
+	testingutils.Equals(t, "((diff((x * (3 - 2)), x) + sin((x * 1))) + '(x * (3 - 2)))", actual, "kept arguments")
+}
@@ -3,9 +3,11 @@ package parser
 import (
 	"fmt"
 	"gocalc/ast"
+	"gocalc/calendar"
 	"gocalc/lexer"
 	"gocalc/token"
 	"strconv"
+	"strings"
 )
 
 const (
@@ -13,11 +15,22 @@ const (
 	BOOLEAN      // ==, !=, >=, >, <=, <
 	SUM          // +, -
 	PRODUCT      // *, /
+	IMPLICIT     // 2x, 3(4 + 5)
 	EXPONENT     // ^
 	PREFIX       // -15, !true
+	POSTFIX      // 5!, 50%
 	CALL         // exit()
 )
 
+// Mode is a set of flags enabling optional syntax
+type Mode uint
+
+const (
+	// Notation enables the mathematical notation: implicit multiplication,
+	// postfix ! and %, |x| and the Unicode operators ×, ÷, √, π, ≤, ≥ and ≠
+	Notation Mode = 1 << iota
+)
+
 var precedences = map[token.TokenType]int{
 	token.PLUS:     SUM,
 	token.MINUS:    SUM,
@@ -32,9 +45,11 @@ var precedences = map[token.TokenType]int{
 	token.NOT_EQ:   BOOLEAN,
 	token.OR:       BOOLEAN,
 	token.AND:      BOOLEAN,
-	token.BANG:     PREFIX,
+	token.BANG:     POSTFIX,
+	token.PERCENT:  POSTFIX,
 	token.LPAREN:   CALL,
 	token.LBRACK:   CALL,
+	token.PERIOD:   CALL,
 }
 
 func (p *Parser) peekPrecedence() int {
@@ -65,13 +80,26 @@ type Parser struct {
 	errors         []string
 	prefixParseFns map[token.TokenType]prefixParseFn
 	infixParseFns  map[token.TokenType]infixParseFn
+
+	mode Mode
+	bars int // number of open |x| bars
+	// pending holds the tokens to read before the next one of the lexer,
+	// the second bar of a split ||
+	pending []token.Token
 }
 
 func New(l *lexer.Lexer) *Parser {
+	return NewWithMode(l, 0)
+}
+
+// NewWithMode creates a parser accepting the optional syntax of mode
+func NewWithMode(l *lexer.Lexer, mode Mode) *Parser {
 	p := &Parser{
 		l:      l,
 		errors: []string{},
+		mode:   mode,
 	}
+	l.SetNotation(mode&Notation != 0)
 	p.prefixParseFns = make(map[token.TokenType]prefixParseFn)
 	p.registerPrefix(token.IDENT, p.parseIdentifier)
 	p.registerPrefix(token.FLOAT, p.parseFloatLiteral)
@@ -81,6 +109,9 @@ func New(l *lexer.Lexer) *Parser {
 	p.registerPrefix(token.LBRACK, p.parseListExpression)
 	p.registerPrefix(token.TRUE, p.parseBooleanLiteral)
 	p.registerPrefix(token.FALSE, p.parseBooleanLiteral)
+	p.registerPrefix(token.QUOTE, p.parseQuoteExpression)
+	p.registerPrefix(token.STRING, p.parseStringLiteral)
+	p.registerPrefix(token.DURATION, p.parseDurationLiteral)
 
 	p.infixParseFns = make(map[token.TokenType]infixParseFn)
 	p.registerInfix(token.PLUS, p.parseInfixExpression)
@@ -97,6 +128,15 @@ func New(l *lexer.Lexer) *Parser {
 	p.registerInfix(token.AND, p.parseInfixExpression)
 	p.registerInfix(token.OR, p.parseInfixExpression)
 	p.registerInfix(token.LPAREN, p.parseCallExpression)
+	p.registerInfix(token.PERIOD, p.parseMemberExpression)
+
+	if mode&Notation != 0 {
+		p.registerPrefix(token.SQRT, p.parseSqrt)
+		p.registerPrefix(token.BAR, p.parseAbsoluteValue)
+		p.registerPrefix(token.OR, p.parseDoubleBar)
+		p.registerInfix(token.BANG, p.parseFactorial)
+		p.registerInfix(token.PERCENT, p.parsePercent)
+	}
 
 	p.nextToken()
 	p.nextToken()
@@ -118,6 +158,15 @@ func (p *Parser) parseCallExpression(left ast.Expression) ast.Expression {
 	return call
 }
 
+func (p *Parser) parseMemberExpression(left ast.Expression) ast.Expression {
+	member := &ast.MemberExpression{Token: p.currToken, Object: left}
+	if !p.expectPeek(token.IDENT) {
+		return nil
+	}
+	member.Member = &ast.Identifier{Token: p.currToken, Value: p.currToken.Literal}
+	return member
+}
+
 func (p *Parser) parseExpressionList(end token.TokenType) []ast.Expression {
 	args := []ast.Expression{}
 
@@ -167,6 +216,16 @@ func (p *Parser) parsePrefixExpression() ast.Expression {
 	return expression
 }
 
+func (p *Parser) parseQuoteExpression() ast.Expression {
+	expression := &ast.QuoteExpression{Token: p.currToken}
+	p.nextToken()
+	expression.Expression = p.parseExpression(PREFIX)
+	if expression.Expression == nil {
+		return nil
+	}
+	return expression
+}
+
 func (p *Parser) parseIdentifier() ast.Expression {
 	return &ast.Identifier{Token: p.currToken, Value: p.currToken.Literal}
 }
@@ -185,6 +244,20 @@ func (p *Parser) Errors() []string {
 	return p.errors
 }
 
+func (p *Parser) parseStringLiteral() ast.Expression {
+	return &ast.StringLiteral{Token: p.currToken, Value: p.currToken.Literal}
+}
+
+func (p *Parser) parseDurationLiteral() ast.Expression {
+	lit := p.currToken.Literal
+	if val, err := calendar.ParseDuration(lit); err == nil {
+		return &ast.DurationLiteral{Token: p.currToken, Value: val}
+	}
+
+	p.addError(fmt.Sprintf("Could not parse value %q as duration", lit))
+	return nil
+}
+
 func (p *Parser) registerPrefix(tokenType token.TokenType, fn prefixParseFn) {
 	p.prefixParseFns[tokenType] = fn
 }
@@ -194,6 +267,10 @@ func (p *Parser) registerInfix(tokenType token.TokenType, fn infixParseFn) {
 
 func (p *Parser) nextToken() {
 	p.currToken = p.peekToken
+	if len(p.pending) > 0 {
+		p.peekToken, p.pending = p.pending[0], p.pending[1:]
+		return
+	}
 	p.peekToken = p.l.NextToken()
 }
 
@@ -216,21 +293,218 @@ func (p *Parser) parseStatement() ast.Statement {
 		return nil
 	}
 
-	if p.currTokenIs(token.IDENT) && p.peekTokenIs(token.ASSIGN) {
-		return p.parseAssignmentStatement()
+	if p.currTokenIs(token.IDENT) {
+		switch {
+		case p.peekTokenIs(token.ASSIGN) || p.peekTokenIs(token.COLON):
+			return p.parseAssignmentStatement()
+		case p.peekTokenIs(token.COMMA):
+			return p.parseDestructuringStatement()
+		case compoundOperators[p.peekToken.Type] != "":
+			return p.parseCompoundAssignmentStatement()
+		}
+	}
+	if p.currTokenIs(token.LBRACK) {
+		stmt := p.parseExpressionStatement()
+		if p.peekTokenIs(token.ASSIGN) {
+			return p.parseListDestructuring(stmt)
+		}
+		return stmt
+	}
+	if p.currTokenIs(token.CONST) {
+		return p.parseConstStatement()
+	}
+	if p.currTokenIs(token.IMPORT) {
+		return p.parseImportStatement()
+	}
+	if p.currTokenIs(token.TYPE) {
+		return p.parseTypeStatement()
 	}
 	return p.parseExpressionStatement()
 }
 
-func (p *Parser) parseAssignmentStatement() *ast.AssignmentStatement {
-	stmt := &ast.AssignmentStatement{Token: p.currToken}
+func (p *Parser) parseAssignmentStatement() ast.Statement {
+	stmt := &ast.AssignmentStatement{Token: p.currToken, Doc: docComment(p.currToken)}
 	stmt.Name = &ast.Identifier{Token: p.currToken, Value: p.currToken.Literal}
 
+	if p.peekTokenIs(token.COLON) {
+		p.nextToken()
+		if !p.expectPeek(token.IDENT) {
+			return nil
+		}
+		stmt.Annotation = &ast.Identifier{Token: p.currToken, Value: p.currToken.Literal}
+		if !p.expectPeek(token.ASSIGN) {
+			return nil
+		}
+	} else if !p.expectPeek(token.ASSIGN) {
+		return nil
+	}
+	p.nextToken()
+
+	for p.currTokenIs(token.IDENT) && p.peekTokenIs(token.ASSIGN) {
+		stmt.Chain = append(stmt.Chain, &ast.Identifier{Token: p.currToken, Value: p.currToken.Literal})
+		p.nextToken()
+		p.nextToken()
+	}
+	stmt.Value = p.parseExpression(LOWEST)
+
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+
+	return stmt
+}
+
+// compoundOperators are the infix operators of the compound assignments
+var compoundOperators = map[token.TokenType]string{
+	token.PLUS_ASSIGN:     "+",
+	token.MINUS_ASSIGN:    "-",
+	token.ASTERISK_ASSIGN: "*",
+	token.SLASH_ASSIGN:    "/",
+	token.CARET_ASSIGN:    "^",
+}
+
+func (p *Parser) parseCompoundAssignmentStatement() ast.Statement {
+	name := &ast.Identifier{Token: p.currToken, Value: p.currToken.Literal}
 	p.nextToken()
+	stmt := &ast.CompoundAssignmentStatement{Token: p.currToken, Name: name, Operator: compoundOperators[p.currToken.Type]}
 	p.nextToken()
 
 	stmt.Value = p.parseExpression(LOWEST)
 
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+	return stmt
+}
+
+func (p *Parser) parseDestructuringStatement() ast.Statement {
+	stmt := &ast.DestructuringStatement{Token: p.currToken}
+	stmt.Names = []*ast.Identifier{{Token: p.currToken, Value: p.currToken.Literal}}
+	for p.peekTokenIs(token.COMMA) {
+		p.nextToken()
+		if !p.expectPeek(token.IDENT) {
+			return nil
+		}
+		stmt.Names = append(stmt.Names, &ast.Identifier{Token: p.currToken, Value: p.currToken.Literal})
+	}
+	if !p.expectPeek(token.ASSIGN) {
+		return nil
+	}
+	return p.parseDestructuringValues(stmt)
+}
+
+// parseListDestructuring parses [x, y] = l, once the list has been parsed as an expression
+func (p *Parser) parseListDestructuring(es *ast.ExpressionStatement) ast.Statement {
+	ll, ok := es.Expression.(*ast.ListLiteral)
+	if !ok {
+		p.addError(fmt.Sprintf("Cannot assign to %s", es.Expression))
+		return nil
+	}
+
+	stmt := &ast.DestructuringStatement{Token: es.Token, List: true}
+	for _, v := range ll.Values {
+		id, ok := v.(*ast.Identifier)
+		if !ok {
+			p.addError(fmt.Sprintf("Cannot assign to %s", v))
+			return nil
+		}
+		stmt.Names = append(stmt.Names, id)
+	}
+	p.nextToken()
+	return p.parseDestructuringValues(stmt)
+}
+
+// parseDestructuringValues parses the values after the = of a destructuring
+func (p *Parser) parseDestructuringValues(stmt *ast.DestructuringStatement) ast.Statement {
+	p.nextToken()
+	stmt.Values = []ast.Expression{p.parseExpression(LOWEST)}
+	for p.peekTokenIs(token.COMMA) {
+		p.nextToken()
+		p.nextToken()
+		stmt.Values = append(stmt.Values, p.parseExpression(LOWEST))
+	}
+	if len(stmt.Values) != 1 && len(stmt.Values) != len(stmt.Names) {
+		p.addError(fmt.Sprintf("Cannot assign %d values to %d variables", len(stmt.Values), len(stmt.Names)))
+		return nil
+	}
+
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+	return stmt
+}
+
+func (p *Parser) parseConstStatement() ast.Statement {
+	doc := docComment(p.currToken)
+	if !p.expectPeek(token.IDENT) {
+		return nil
+	}
+	stmt := p.parseAssignmentStatement()
+	if as, ok := stmt.(*ast.AssignmentStatement); ok {
+		as.Const, as.Doc = true, doc
+	}
+	return stmt
+}
+
+// docComment returns the text of the comments right above tok, on the lines
+// before it with no blank line in between. Trailing comments belong to the
+// previous line
+func docComment(tok token.Token) string {
+	var docs []string
+	line := tok.Line
+	for i := len(tok.Comments) - 1; i >= 0; i-- {
+		c := tok.Comments[i]
+		if c.Trailing || line-c.EndLine() > 1 {
+			break
+		}
+		docs = append([]string{c.Text()}, docs...)
+		line = c.Line
+	}
+	return strings.Join(docs, "\n")
+}
+
+func (p *Parser) parseImportStatement() ast.Statement {
+	stmt := &ast.ImportStatement{Token: p.currToken}
+	if !p.expectPeek(token.STRING) {
+		return nil
+	}
+	stmt.Path = p.currToken.Literal
+
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+
+	return stmt
+}
+
+func (p *Parser) parseTypeStatement() ast.Statement {
+	stmt := &ast.TypeStatement{Token: p.currToken}
+	if !p.expectPeek(token.IDENT) {
+		return nil
+	}
+	stmt.Name = &ast.Identifier{Token: p.currToken, Value: p.currToken.Literal}
+	if !p.expectPeek(token.ASSIGN) || !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+
+	seen := map[string]bool{}
+	for !p.peekTokenIs(token.RBRACE) {
+		if len(stmt.Fields) > 0 && !p.expectPeek(token.COMMA) {
+			return nil
+		}
+		if !p.expectPeek(token.IDENT) {
+			return nil
+		}
+		field := &ast.Identifier{Token: p.currToken, Value: p.currToken.Literal}
+		if seen[field.Value] {
+			p.addError(fmt.Sprintf("Duplicate field %s in type %s", field.Value, stmt.Name.Value))
+			return nil
+		}
+		seen[field.Value] = true
+		stmt.Fields = append(stmt.Fields, field)
+	}
+	p.nextToken()
+
 	if p.peekTokenIs(token.SEMICOLON) {
 		p.nextToken()
 	}
@@ -258,7 +532,21 @@ func (p *Parser) parseExpression(precedence int) ast.Expression {
 
 	leftExp := prefix()
 
-	for !p.peekTokenIs(token.SEMICOLON) && precedence < p.peekPrecedence() {
+	for !p.peekTokenIs(token.SEMICOLON) {
+		// the || of |1 - |-3|| closes both
+		if p.bars > 0 && p.peekTokenIs(token.OR) {
+			first, second := splitBars(p.peekToken)
+			p.peekToken, p.pending = first, append([]token.Token{second}, p.pending...)
+		}
+		if precedence < IMPLICIT && p.implicitOperand(leftExp) {
+			p.nextToken()
+			leftExp = ast.NewInfixExpression("*", leftExp, p.parseExpression(IMPLICIT))
+			continue
+		}
+		if precedence >= p.peekPrecedence() {
+			break
+		}
+
 		infix := p.infixParseFns[p.peekToken.Type]
 
 		if infix == nil {
@@ -311,7 +599,11 @@ func (p *Parser) peekError(t token.TokenType) {
 }
 
 func (p *Parser) illegalTokenError() {
-	msg := fmt.Sprintf("Token %s not recognized", p.currToken.Literal)
+	if msg, ok := p.l.Error(p.currToken); ok {
+		p.addError(msg)
+		return
+	}
+	msg := fmt.Sprintf("Token %s not recognized at line %d, column %d", p.currToken.Literal, p.currToken.Line, p.currToken.Column)
 	p.addError(msg)
 }
 
@@ -321,6 +613,75 @@ func (p *Parser) floatParseError(val string) {
 }
 
 func (p *Parser) noPrefixParseFnError(t token.Token) {
+	if msg, ok := p.l.Error(t); ok {
+		p.addError(msg)
+		return
+	}
 	msg := fmt.Sprintf("No prefix parse function for %s found (literal='%s')", t.Type, t.Literal)
 	p.errors = append(p.errors, msg)
 }
+
+// implicitOperand reports whether the next token starts an operand multiplied
+// by left in notation mode. Parentheses after a function are still a call
+func (p *Parser) implicitOperand(left ast.Expression) bool {
+	if p.mode&Notation == 0 {
+		return false
+	}
+
+	switch p.peekToken.Type {
+	case token.IDENT, token.SQRT:
+		return true
+	case token.BAR:
+		return p.bars == 0
+	case token.FLOAT:
+		_, number := left.(*ast.FloatLiteral)
+		return !number
+	case token.LPAREN:
+		switch left.(type) {
+		case *ast.Identifier, *ast.CallExpression, *ast.MemberExpression:
+			return false
+		}
+		return true
+	}
+	return false
+}
+
+func (p *Parser) parseSqrt() ast.Expression {
+	p.nextToken()
+	return ast.NewCallExpression("sqrt", p.parseExpression(PREFIX))
+}
+
+func (p *Parser) parseAbsoluteValue() ast.Expression {
+	p.bars++
+	defer func() { p.bars-- }()
+
+	p.nextToken()
+	expression := p.parseExpression(LOWEST)
+	if !p.expectPeek(token.BAR) {
+		return nil
+	}
+	return ast.NewCallExpression("abs", expression)
+}
+
+// parseDoubleBar reads a || opening an absolute value as two bars, ||x| - 1|
+func (p *Parser) parseDoubleBar() ast.Expression {
+	first, second := splitBars(p.currToken)
+	p.currToken, p.peekToken, p.pending = first, second, append([]token.Token{p.peekToken}, p.pending...)
+	return p.parseAbsoluteValue()
+}
+
+// splitBars returns the two bars an || token is made of
+func splitBars(or token.Token) (token.Token, token.Token) {
+	first, second := token.New(token.BAR, '|'), token.New(token.BAR, '|')
+	first.Line, first.Column, first.Comments = or.Line, or.Column, or.Comments
+	second.Line, second.Column = or.Line, or.Column+1
+	return first, second
+}
+
+func (p *Parser) parseFactorial(left ast.Expression) ast.Expression {
+	return ast.NewCallExpression("factorial", left)
+}
+
+func (p *Parser) parsePercent(left ast.Expression) ast.Expression {
+	return ast.NewInfixExpression("/", left, ast.NewFloatLiteral(100))
+}
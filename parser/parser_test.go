@@ -81,6 +81,109 @@ x= 0.5;
 	}
 }
 
+func TestTypeStatements(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+		err      string
+	}{
+		{"type Point = {x, y}", "type Point = {x, y};", ""},
+		{"type Unit = {}; Unit()", "type Unit = {};Unit()", ""},
+		{"type P = {x, x}", "", "Duplicate field x in type P"},
+		{"type P = {x y}", "", "Expected next token to be ,, got IDENT instead"},
+		{"type P {x}", "", "Expected next token to be =, got { instead"},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		if tt.err != "" {
+			testingutils.Assert(t, len(p.Errors()) > 0, "%s: expected a parse error", tt.input)
+			testingutils.Equals(t, tt.err, p.Errors()[0], tt.input)
+			continue
+		}
+		assertNoParseErrors(t, p)
+		testingutils.Equals(t, tt.expected, program.String(), tt.input)
+	}
+}
+
+func TestAnnotatedAssignments(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+		err      string
+	}{
+		{"x: Float = 1", "x: Float = 1;", ""},
+		{"p: Point = Point(1, 2); p", "p: Point = Point(1, 2);p", ""},
+		{"x: = 1", "", "Expected next token to be IDENT, got = instead"},
+		{"x: Float 1", "", "Expected next token to be =, got FLOAT instead"},
+		{"const x = 1", "const x = 1;", ""},
+		{"const x: Float = 1", "const x: Float = 1;", ""},
+		{"const x 1", "", "Expected next token to be =, got FLOAT instead"},
+		{"const 1", "", "Expected next token to be IDENT, got FLOAT instead"},
+		{"x += 1", "x += 1;", ""},
+		{"x ^= 2 * y; x", "x ^= (2 * y);x", ""},
+		{"a, b = b, a", "a, b = b, a;", ""},
+		{"a, b = l", "a, b = l;", ""},
+		{"[x, y] = f(1, 2)", "[x, y] = f(1, 2);", ""},
+		{"[1, 2]", "[1, 2]", ""},
+		{"a = b = c = 0", "a = b = c = 0;", ""},
+		{"a = b == 0", "a = (b == 0);", ""},
+		{"a, b = 1, 2, 3", "", "Cannot assign 3 values to 2 variables"},
+		{"a, 1 = 2", "", "Expected next token to be IDENT, got FLOAT instead"},
+		{"[x, 1] = l", "", "Cannot assign to 1"},
+		{"a, b", "", "Expected next token to be =, got EOF instead"},
+		{"θ = 30; 2 * θ", "θ = 30;(2 * θ)", ""},
+		{"x = 1;\n  @", "", "Token @ not recognized at line 2, column 3"},
+		{"x = 1.2.3 + 1", "", "Malformed number 1.2.3 at line 1, column 5: unexpected decimal point"},
+		{"1__000", "", "Malformed number 1__000 at line 1, column 1: misplaced digit separator"},
+		{"2 * 1e400", "", "Malformed number 1e400 at line 1, column 5: out of range"},
+		{"1e + 2", "", "Malformed number 1e at line 1, column 1: missing exponent digits"},
+		{"x = 2E-", "", "Malformed number 2E- at line 1, column 5: missing exponent digits"},
+		{"x = 1 # one\n// two\ny = 2 /* three */", "x = 1;y = 2;", ""},
+		{"x = 1 /* open", "", "Unterminated comment at line 1, column 7"},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		if tt.err != "" {
+			testingutils.Assert(t, len(p.Errors()) > 0, "%s: expected a parse error", tt.input)
+			testingutils.Equals(t, tt.err, p.Errors()[0], tt.input)
+			continue
+		}
+		assertNoParseErrors(t, p)
+		testingutils.Equals(t, tt.expected, program.String(), tt.input)
+	}
+}
+
+func TestDocComments(t *testing.T) {
+	tests := []struct {
+		input string
+		docs  []string
+	}{
+		{"# The rate\n# per year\nr = 0.05", []string{"The rate\nper year"}},
+		{"/* Tax */ const tax = 0.2", []string{"Tax"}},
+		{"# Header\n\nx = 1", []string{""}},
+		{"x = 1 # one\ny = 2", []string{"", ""}},
+		{"// Both\na = b = 0; 1 + 1", []string{"Both"}},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		assertNoParseErrors(t, p)
+
+		var docs []string
+		for _, s := range program.Statements {
+			if as, ok := s.(*ast.AssignmentStatement); ok {
+				docs = append(docs, as.Doc)
+			}
+		}
+		testingutils.Equals(t, tt.docs, docs, tt.input)
+	}
+}
+
 func TestIdentifierExpression(t *testing.T) {
 	input := "myVar;"
 	l := lexer.New(input)
@@ -298,6 +401,22 @@ func TestOperatorPrecedenceParsing(t *testing.T) {
 			"3 >= 5",
 			"(3 >= 5)",
 		},
+		{
+			"d + 3d 4h * 2",
+			"(d + (3d 4h * 2))",
+		},
+		{
+			`"a" + "b"`,
+			`("a" + "b")`,
+		},
+		{
+			"a + lib.f(x) * lib.pi",
+			"(a + (lib.f(x) * lib.pi))",
+		},
+		{
+			`import "lib.gc"; -lib.x`,
+			`import "lib.gc";(-lib.x)`,
+		},
 		{
 			"3 > 5",
 			"(3 > 5)",
@@ -338,6 +457,10 @@ func TestOperatorPrecedenceParsing(t *testing.T) {
 			"add(a + b + c * d / f + g)",
 			"add((((a + b) + ((c * d) / f)) + g))",
 		},
+		{
+			"'(x ^ 2 - 1) * 'y",
+			"('((x ^ 2) - 1) * 'y)",
+		},
 	}
 
 	for _, tt := range tests {
@@ -385,3 +508,58 @@ func testInfixExpression(t *testing.T, exp ast.Expression, left interface{},
 	testLiteralExpression(t, res.Right, right)
 	testLiteralExpression(t, res.Left, left)
 }
+
+func TestNotation(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"2pi", "(2 * pi)"},
+		{"2x^2", "(2 * (x ^ 2))"},
+		{"1/2x", "(1 / (2 * x))"},
+		{"2x y", "((2 * x) * y)"},
+		{"3(4 + 5)", "(3 * (4 + 5))"},
+		{"(1 + 2)(3 + 4)", "((1 + 2) * (3 + 4))"},
+		{"2 sin(x)", "(2 * sin(x))"},
+		{"f(x)", "f(x)"},
+		{"-2x", "((-2) * x)"},
+		{"x2", "x2"},
+		{"5!", "factorial(5)"},
+		{"2^3!", "(2 ^ factorial(3))"},
+		{"-3!", "(-factorial(3))"},
+		{"5! != 120", "(factorial(5) != 120)"},
+		{"!true", "(!true)"},
+		{"50%", "(50 / 100)"},
+		{"200 * 15%", "(200 * (15 / 100))"},
+		{"|x - 3|", "abs((x - 3))"},
+		{"2|x|", "(2 * abs(x))"},
+		{"|2x| + 1", "(abs((2 * x)) + 1)"},
+		{"|1 - |-3||", "abs((1 - abs((-3))))"},
+		{"||x| - 1|", "abs((abs(x) - 1))"},
+		{"|||x|||", "abs(abs(abs(x)))"},
+		{"(|x| > 1) || (|y| > 1)", "((abs(x) > 1) || (abs(y) > 1))"},
+		{"2d", "(2 * d)"},
+		{"3h 2m", "(((3 * h) * 2) * m)"},
+		{"√2", "sqrt(2)"},
+		{"3√x", "(3 * sqrt(x))"},
+		{"2π", "(2 * pi)"},
+		{"6 × 7 ÷ 2", "((6 * 7) / 2)"},
+		{"1 ≤ 2", "(1 <= 2)"},
+		{"1 ≥ 2", "(1 >= 2)"},
+		{"1 ≠ 2", "(1 != 2)"},
+		{"a || b", "(a || b)"},
+	}
+
+	for _, tt := range tests {
+		p := NewWithMode(lexer.New(tt.input), Notation)
+		program := p.ParseProgram()
+		assertNoParseErrors(t, p)
+		testingutils.Equals(t, tt.expected, program.String(), tt.input)
+	}
+
+	for _, input := range []string{"5!", "|x|", "50%", "√2"} {
+		p := New(lexer.New(input))
+		p.ParseProgram()
+		testingutils.Assert(t, p.HasErrors(), "%s: parsed without the notation mode", input)
+	}
+}
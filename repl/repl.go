@@ -5,13 +5,21 @@ import (
 	"fmt"
 	"gocalc/evaluator"
 	"io"
+	"strconv"
+	"strings"
 )
 
 const PROMPT = ">>> "
 
+// COMMAND_PREFIX starts the lines that are commands for the REPL itself
+const COMMAND_PREFIX = ":"
+
 func Start(in io.Reader, out io.Writer) {
 	scanner := bufio.NewScanner(in)
 	ev := evaluator.New()
+	ev.OnWarning(func(msg string) {
+		io.WriteString(out, "Warning: "+msg+"\n")
+	})
 
 	for {
 		fmt.Printf(PROMPT)
@@ -23,6 +31,12 @@ func Start(in io.Reader, out io.Writer) {
 
 		line := scanner.Text()
 
+		if strings.HasPrefix(line, COMMAND_PREFIX) {
+			io.WriteString(out, command(ev, strings.Fields(line[len(COMMAND_PREFIX):])))
+			io.WriteString(out, "\n")
+			continue
+		}
+
 		res := ev.Eval(line)
 
 		if res != nil {
@@ -31,3 +45,70 @@ func Start(in io.Reader, out io.Writer) {
 		}
 	}
 }
+
+func command(ev *evaluator.Evaluator, args []string) string {
+	if len(args) == 0 {
+		return "Missing command"
+	}
+
+	switch args[0] {
+	case "symbolic":
+		if len(args) == 2 && (args[1] == "on" || args[1] == "off") {
+			ev.SetSymbolic(args[1] == "on")
+		}
+		if ev.Symbolic() {
+			return "symbolic mode is on"
+		}
+		return "symbolic mode is off"
+	case "check":
+		if len(args) == 2 && (args[1] == "on" || args[1] == "off") {
+			ev.SetTypeCheck(args[1] == "on")
+		}
+		if ev.TypeCheck() {
+			return "type checking is on"
+		}
+		return "type checking is off"
+	case "notation":
+		if len(args) == 2 && (args[1] == "on" || args[1] == "off") {
+			ev.SetNotation(args[1] == "on")
+		}
+		if ev.Notation() {
+			return "notation is on"
+		}
+		return "notation is off"
+	case "echo":
+		if len(args) == 2 && (args[1] == "on" || args[1] == "off") {
+			ev.SetEcho(args[1] == "on")
+		}
+		if ev.Echo() {
+			return "echo is on"
+		}
+		return "echo is off"
+	case "tol":
+		opts := ev.NumericOptions()
+		if len(args) == 2 {
+			if tol, err := strconv.ParseFloat(args[1], 64); err == nil && tol > 0 {
+				opts.Tol = tol
+				ev.SetNumericOptions(opts)
+			}
+		}
+		return fmt.Sprintf("tolerance is %g", ev.NumericOptions().Tol)
+	case "maxiter":
+		opts := ev.NumericOptions()
+		if len(args) == 2 {
+			if n, err := strconv.Atoi(args[1]); err == nil && n > 0 {
+				opts.MaxIter = n
+				ev.SetNumericOptions(opts)
+			}
+		}
+		return fmt.Sprintf("iteration limit is %d", ev.NumericOptions().MaxIter)
+	case "type":
+		t, err := ev.Check(strings.Join(args[1:], " "))
+		if err != nil {
+			return err.String()
+		}
+		return t.String()
+	}
+
+	return fmt.Sprintf("Unknown command %s", args[0])
+}
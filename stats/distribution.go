@@ -0,0 +1,224 @@
+// Package stats implements probability distributions.
+//
+// Discrete distributions return the probability mass of a value as its PDF
+package stats
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+)
+
+// Distribution is a probability distribution over the reals
+type Distribution interface {
+	PDF(x float64) float64
+	CDF(x float64) float64
+	// Quantile is the smallest x such that CDF(x) >= p, for p in [0, 1]
+	Quantile(p float64) float64
+	Sample(r *rand.Rand) float64
+	Mean() float64
+	Variance() float64
+	String() string
+}
+
+func invalid(format string, v ...interface{}) error {
+	return fmt.Errorf("invalid parameters: "+format, v...)
+}
+
+type Normal struct{ Mu, Sigma float64 }
+
+func NewNormal(mu, sigma float64) (*Normal, error) {
+	if !(sigma > 0) {
+		return nil, invalid("the standard deviation must be positive, got %g", sigma)
+	}
+	return &Normal{Mu: mu, Sigma: sigma}, nil
+}
+
+func (d *Normal) PDF(x float64) float64 {
+	z := (x - d.Mu) / d.Sigma
+	return math.Exp(-z*z/2) / (d.Sigma * math.Sqrt(2*math.Pi))
+}
+
+func (d *Normal) CDF(x float64) float64 {
+	return math.Erfc(-(x-d.Mu)/(d.Sigma*math.Sqrt2)) / 2
+}
+
+func (d *Normal) Quantile(p float64) float64 {
+	return d.Mu + d.Sigma*math.Sqrt2*math.Erfinv(2*p-1)
+}
+
+func (d *Normal) Sample(r *rand.Rand) float64 { return d.Mu + d.Sigma*r.NormFloat64() }
+func (d *Normal) Mean() float64               { return d.Mu }
+func (d *Normal) Variance() float64           { return d.Sigma * d.Sigma }
+func (d *Normal) String() string              { return fmt.Sprintf("normal(%v, %v)", d.Mu, d.Sigma) }
+
+type Uniform struct{ A, B float64 }
+
+func NewUniform(a, b float64) (*Uniform, error) {
+	if !(a < b) {
+		return nil, invalid("the lower bound must be less than the upper bound, got %g and %g", a, b)
+	}
+	return &Uniform{A: a, B: b}, nil
+}
+
+func (d *Uniform) PDF(x float64) float64 {
+	if x < d.A || x > d.B {
+		return 0
+	}
+	return 1 / (d.B - d.A)
+}
+
+func (d *Uniform) CDF(x float64) float64 {
+	return math.Max(0, math.Min(1, (x-d.A)/(d.B-d.A)))
+}
+
+func (d *Uniform) Quantile(p float64) float64  { return d.A + p*(d.B-d.A) }
+func (d *Uniform) Sample(r *rand.Rand) float64 { return d.A + r.Float64()*(d.B-d.A) }
+func (d *Uniform) Mean() float64               { return (d.A + d.B) / 2 }
+func (d *Uniform) Variance() float64           { return (d.B - d.A) * (d.B - d.A) / 12 }
+func (d *Uniform) String() string              { return fmt.Sprintf("uniform(%v, %v)", d.A, d.B) }
+
+type Exponential struct{ Rate float64 }
+
+func NewExponential(rate float64) (*Exponential, error) {
+	if !(rate > 0) {
+		return nil, invalid("the rate must be positive, got %g", rate)
+	}
+	return &Exponential{Rate: rate}, nil
+}
+
+func (d *Exponential) PDF(x float64) float64 {
+	if x < 0 {
+		return 0
+	}
+	return d.Rate * math.Exp(-d.Rate*x)
+}
+
+func (d *Exponential) CDF(x float64) float64 {
+	if x < 0 {
+		return 0
+	}
+	return -math.Expm1(-d.Rate * x)
+}
+
+func (d *Exponential) Quantile(p float64) float64  { return -math.Log1p(-p) / d.Rate }
+func (d *Exponential) Sample(r *rand.Rand) float64 { return r.ExpFloat64() / d.Rate }
+func (d *Exponential) Mean() float64               { return 1 / d.Rate }
+func (d *Exponential) Variance() float64           { return 1 / (d.Rate * d.Rate) }
+func (d *Exponential) String() string              { return fmt.Sprintf("exponential(%v)", d.Rate) }
+
+// discrete holds the mass function of a distribution over the natural numbers
+// up to max, from which its CDF and quantiles are computed
+type discrete struct {
+	pmf func(k float64) float64
+	max float64
+}
+
+func (d discrete) PDF(x float64) float64 {
+	if x < 0 || x > d.max || x != math.Trunc(x) {
+		return 0
+	}
+	return d.pmf(x)
+}
+
+func (d discrete) CDF(x float64) float64 {
+	if x < 0 {
+		return 0
+	}
+	sum := 0.0
+	for k := 0.0; k <= math.Min(math.Floor(x), d.max); k++ {
+		term := d.pmf(k)
+		// past the mode the terms only decrease, once they underflow the sum is complete
+		if term == 0 && sum > 0 {
+			break
+		}
+		sum += term
+	}
+	return math.Min(sum, 1)
+}
+
+func (d discrete) Quantile(p float64) float64 {
+	if p <= 0 {
+		return 0
+	}
+	if p >= 1 {
+		return d.max
+	}
+	sum := 0.0
+	for k := 0.0; k < d.max; k++ {
+		term := d.pmf(k)
+		if term == 0 && sum > 0 {
+			return k - 1
+		}
+		// the sums are rounded, so p is only compared up to a few ulps
+		if sum += term; sum >= p*(1-1e-12) {
+			return k
+		}
+	}
+	return d.max
+}
+
+func (d discrete) Sample(r *rand.Rand) float64 { return d.Quantile(r.Float64()) }
+
+// logChoose is the logarithm of the binomial coefficient (n k)
+func logChoose(n, k float64) float64 {
+	a, _ := math.Lgamma(n + 1)
+	b, _ := math.Lgamma(k + 1)
+	c, _ := math.Lgamma(n - k + 1)
+	return a - b - c
+}
+
+type Binomial struct {
+	discrete
+	N, P float64
+}
+
+func NewBinomial(n, p float64) (*Binomial, error) {
+	if n < 0 || n != math.Trunc(n) {
+		return nil, invalid("the number of trials must be a natural number, got %g", n)
+	}
+	if !(p >= 0 && p <= 1) {
+		return nil, invalid("the probability must be between 0 and 1, got %g", p)
+	}
+	pmf := func(k float64) float64 {
+		switch {
+		case p == 0:
+			return boolFloat(k == 0)
+		case p == 1:
+			return boolFloat(k == n)
+		}
+		return math.Exp(logChoose(n, k) + k*math.Log(p) + (n-k)*math.Log1p(-p))
+	}
+	return &Binomial{discrete: discrete{pmf: pmf, max: n}, N: n, P: p}, nil
+}
+
+func (d *Binomial) Mean() float64     { return d.N * d.P }
+func (d *Binomial) Variance() float64 { return d.N * d.P * (1 - d.P) }
+func (d *Binomial) String() string    { return fmt.Sprintf("binomial(%v, %v)", d.N, d.P) }
+
+type Poisson struct {
+	discrete
+	Lambda float64
+}
+
+func NewPoisson(lambda float64) (*Poisson, error) {
+	if !(lambda > 0) {
+		return nil, invalid("the rate must be positive, got %g", lambda)
+	}
+	pmf := func(k float64) float64 {
+		lg, _ := math.Lgamma(k + 1)
+		return math.Exp(k*math.Log(lambda) - lambda - lg)
+	}
+	return &Poisson{discrete: discrete{pmf: pmf, max: math.Inf(1)}, Lambda: lambda}, nil
+}
+
+func (d *Poisson) Mean() float64     { return d.Lambda }
+func (d *Poisson) Variance() float64 { return d.Lambda }
+func (d *Poisson) String() string    { return fmt.Sprintf("poisson(%v)", d.Lambda) }
+
+func boolFloat(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
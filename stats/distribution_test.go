@@ -0,0 +1,85 @@
+package stats
+
+import (
+	"gocalc/testing_utils"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func near(a, b float64) bool { return math.Abs(a-b) <= 1e-9*math.Max(1, math.Abs(b)) }
+
+func mustDist(d Distribution, err error) Distribution {
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+func TestDistributions(t *testing.T) {
+	normal := mustDist(NewNormal(0, 1))
+	tests := []struct {
+		name     string
+		value    float64
+		expected float64
+	}{
+		{"normal pdf", normal.PDF(0), 0.3989422804014327},
+		{"normal cdf", normal.CDF(1.96), 0.9750021048517795},
+		{"normal quantile", normal.Quantile(0.975), 1.959963984540054},
+		{"shifted normal cdf", mustDist(NewNormal(10, 2)).CDF(10), 0.5},
+		{"uniform pdf", mustDist(NewUniform(2, 6)).PDF(3), 0.25},
+		{"uniform cdf", mustDist(NewUniform(2, 6)).CDF(3), 0.25},
+		{"uniform quantile", mustDist(NewUniform(2, 6)).Quantile(0.5), 4},
+		{"exponential cdf", mustDist(NewExponential(2)).CDF(1), 1 - math.Exp(-2)},
+		{"exponential quantile", mustDist(NewExponential(2)).Quantile(0.5), math.Ln2 / 2},
+		{"binomial pmf", mustDist(NewBinomial(10, 0.5)).PDF(5), 0.24609375},
+		{"binomial cdf", mustDist(NewBinomial(10, 0.5)).CDF(5), 0.623046875},
+		{"binomial quantile", mustDist(NewBinomial(10, 0.5)).Quantile(0.623046875), 5},
+		{"binomial pmf outside", mustDist(NewBinomial(10, 0.5)).PDF(2.5), 0},
+		{"degenerate binomial", mustDist(NewBinomial(4, 1)).PDF(4), 1},
+		{"poisson pmf", mustDist(NewPoisson(3)).PDF(2), 4.5 * math.Exp(-3)},
+		{"poisson cdf", mustDist(NewPoisson(3)).CDF(2), 8.5 * math.Exp(-3)},
+		{"poisson quantile", mustDist(NewPoisson(3)).Quantile(0.5), 3},
+		{"poisson far tail", mustDist(NewPoisson(3)).CDF(1e12), 1},
+	}
+
+	for _, tt := range tests {
+		testingutils.Assert(t, near(tt.value, tt.expected), "%s: expected %v, got %v", tt.name, tt.expected, tt.value)
+	}
+
+	for _, invalid := range []func() (Distribution, error){
+		func() (Distribution, error) { return NewNormal(0, 0) },
+		func() (Distribution, error) { return NewUniform(1, 1) },
+		func() (Distribution, error) { return NewExponential(-1) },
+		func() (Distribution, error) { return NewBinomial(2.5, 0.5) },
+		func() (Distribution, error) { return NewBinomial(10, 1.5) },
+		func() (Distribution, error) { return NewPoisson(0) },
+	} {
+		_, err := invalid()
+		testingutils.Assert(t, err != nil, "invalid parameters should be rejected")
+	}
+}
+
+func TestSampleMoments(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for _, d := range []Distribution{
+		mustDist(NewNormal(5, 2)),
+		mustDist(NewUniform(-1, 3)),
+		mustDist(NewExponential(0.5)),
+		mustDist(NewBinomial(20, 0.3)),
+		mustDist(NewPoisson(4)),
+	} {
+		const n = 20000
+		sum, sumSq := 0.0, 0.0
+		for i := 0; i < n; i++ {
+			x := d.Sample(r)
+			sum += x
+			sumSq += x * x
+		}
+		mean := sum / n
+		variance := sumSq/n - mean*mean
+		tol := 5 * math.Sqrt(d.Variance()/n)
+		testingutils.Assert(t, math.Abs(mean-d.Mean()) < tol, "%s: sample mean %v, expected %v", d, mean, d.Mean())
+		testingutils.Assert(t, math.Abs(variance-d.Variance()) < 0.05*d.Variance(), "%s: sample variance %v, expected %v", d, variance, d.Variance())
+	}
+}
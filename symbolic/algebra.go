@@ -0,0 +1,394 @@
+package symbolic
+
+import (
+	"gocalc/ast"
+	"math"
+	"math/big"
+	"sort"
+)
+
+// maxFactorDivisor bounds the divisors tried when looking for rational roots
+const maxFactorDivisor = 1 << 20
+
+// atoms keeps the subexpressions that are treated as variables when an
+// expression is not a polynomial, like sin(x) in sin(x)^2 - 1
+type atoms map[string]ast.Expression
+
+// polynomial converts e into a polynomial over its variables and atoms
+func (a atoms) polynomial(e ast.Expression) *Polynomial {
+	switch e := e.(type) {
+	case *ast.FloatLiteral:
+		return Constant(e.Value)
+
+	case *ast.Identifier:
+		return Variable(e.Value)
+
+	case *ast.PrefixExpression:
+		if e.Operator == "-" {
+			return a.polynomial(e.Right).Scale(-1)
+		}
+
+	case *ast.InfixExpression:
+		l, r := a.polynomial(e.Left), a.polynomial(e.Right)
+		switch e.Operator {
+		case "+":
+			return l.Add(r)
+		case "-":
+			return l.Sub(r)
+		case "*":
+			return l.Mul(r)
+		case "/":
+			if c, ok := r.IsConstant(); ok && c != 0 {
+				return l.Scale(1 / c)
+			}
+			if q, ok := divide(l, r); ok {
+				return q
+			}
+			return a.atom(div(a.restore(l.Expression()), a.restore(r.Expression())))
+		case "^":
+			if c, ok := r.IsConstant(); ok && c >= 0 && c <= maxExpandPower && c == math.Trunc(c) {
+				return l.Pow(int(c))
+			}
+			return a.atom(pow(a.restore(l.Expression()), a.restore(r.Expression())))
+		}
+
+	case *ast.CallExpression:
+		args := make([]ast.Expression, len(e.Arguments))
+		for i, arg := range e.Arguments {
+			args[i] = a.restore(a.polynomial(arg).Expression())
+		}
+		return a.atom(&ast.CallExpression{Token: e.Token, Function: e.Function, Arguments: args})
+	}
+
+	return a.atom(e)
+}
+
+func (a atoms) atom(e ast.Expression) *Polynomial {
+	name := e.String()
+	a[name] = e
+	return Variable(name)
+}
+
+// restore replaces the atoms in e by the expressions they stand for
+func (a atoms) restore(e ast.Expression) ast.Expression {
+	switch e := e.(type) {
+	case *ast.Identifier:
+		if atom, ok := a[e.Value]; ok {
+			return atom
+		}
+	case *ast.PrefixExpression:
+		return ast.NewPrefixExpression(e.Operator, a.restore(e.Right))
+	case *ast.InfixExpression:
+		return ast.NewInfixExpression(e.Operator, a.restore(e.Left), a.restore(e.Right))
+	}
+	return e
+}
+
+// Expand distributes products and integer powers over sums and collects like terms
+func Expand(e ast.Expression) ast.Expression {
+	a := atoms{}
+	return a.restore(a.polynomial(e).Expression())
+}
+
+// SimplifyAlgebraic returns the shortest of the structurally simplified and the
+// expanded forms of e, so like terms are collected without expanding powers needlessly
+func SimplifyAlgebraic(e ast.Expression) ast.Expression {
+	simplified := Simplify(e)
+	expanded := Simplify(Expand(e))
+	if len(expanded.String()) <= len(simplified.String()) {
+		return expanded
+	}
+	return simplified
+}
+
+// divide divides two polynomials of the same single variable, if the division is exact
+func divide(p, q *Polynomial) (*Polynomial, bool) {
+	vars := p.Add(q).Variables()
+	if len(vars) != 1 {
+		return nil, false
+	}
+	x := vars[0]
+	num, den := p.Coefficients(x), q.Coefficients(x)
+	if len(den) == 0 || len(num) < len(den) {
+		return nil, false
+	}
+
+	quot := make([]float64, len(num)-len(den)+1)
+	rem := append([]float64{}, num...)
+	lead := den[len(den)-1]
+	for i := len(quot) - 1; i >= 0; i-- {
+		quot[i] = rem[i+len(den)-1] / lead
+		for j, d := range den {
+			rem[i+j] -= quot[i] * d
+		}
+	}
+	for _, r := range rem {
+		if math.Abs(r) > 1e-9 {
+			return nil, false
+		}
+	}
+
+	res := NewPolynomial()
+	for i, c := range quot {
+		res.addTerm(c, powersOf(x, i))
+	}
+	return res, true
+}
+
+func powersOf(x string, n int) map[string]int {
+	if n == 0 {
+		return map[string]int{}
+	}
+	return map[string]int{x: n}
+}
+
+// Factor writes e as a product. Common factors are extracted from every term,
+// and polynomials in a single variable with integer coefficients are split
+// into linear factors for each of their rational roots
+func Factor(e ast.Expression) ast.Expression {
+	a := atoms{}
+	p := a.polynomial(e)
+	if _, ok := p.IsConstant(); ok {
+		return p.Expression()
+	}
+
+	common, rest := commonFactor(p)
+	factors := []ast.Expression{}
+	c, constant := common.IsConstant()
+	if !constant || (c != 1 && c != -1) {
+		factors = append(factors, common.Expression())
+	}
+
+	if vars := rest.Variables(); len(vars) == 1 {
+		factors = append(factors, factorUnivariate(rest, vars[0])...)
+	} else {
+		factors = append(factors, rest.Expression())
+	}
+
+	res := factors[0]
+	for _, f := range factors[1:] {
+		res = mul(res, f)
+	}
+	if constant && c == -1 {
+		res = neg(res)
+	}
+	return a.restore(res)
+}
+
+// commonFactor splits p into the greatest monomial dividing every term and the rest
+func commonFactor(p *Polynomial) (*Polynomial, *Polynomial) {
+	var powers map[string]int
+	gcd := new(big.Int)
+	integral := true
+	var leading *term
+
+	for _, t := range p.terms {
+		if leading == nil || t.degree() > leading.degree() ||
+			(t.degree() == leading.degree() && monomialKey(t.powers) < monomialKey(leading.powers)) {
+			leading = t
+		}
+
+		if powers == nil {
+			powers = map[string]int{}
+			for v, n := range t.powers {
+				powers[v] = n
+			}
+		} else {
+			for v, n := range powers {
+				if t.powers[v] < n {
+					powers[v] = t.powers[v]
+				}
+			}
+		}
+
+		if c, ok := toInt(t.coef); ok && integral {
+			gcd.GCD(nil, nil, gcd, new(big.Int).Abs(c))
+		} else {
+			integral = false
+		}
+	}
+
+	for v, n := range powers {
+		if n == 0 {
+			delete(powers, v)
+		}
+	}
+
+	coef := 1.0
+	if integral && gcd.Sign() != 0 {
+		coef, _ = new(big.Float).SetInt(gcd).Float64()
+	}
+	if leading != nil && leading.coef < 0 {
+		coef = -coef
+	}
+
+	common := NewPolynomial()
+	common.addTerm(coef, powers)
+
+	rest := NewPolynomial()
+	for _, t := range p.terms {
+		reduced := map[string]int{}
+		for v, n := range t.powers {
+			if m := n - powers[v]; m > 0 {
+				reduced[v] = m
+			}
+		}
+		rest.addTerm(t.coef/coef, reduced)
+	}
+	return common, rest
+}
+
+type linearFactor struct {
+	p, q         *big.Int // the factor q*x - p, for the root p/q
+	multiplicity int
+}
+
+func factorUnivariate(poly *Polynomial, x string) []ast.Expression {
+	floats := poly.Coefficients(x)
+	coefs := make([]*big.Int, len(floats))
+	for i, c := range floats {
+		n, ok := toInt(c)
+		if !ok {
+			return []ast.Expression{poly.Expression()}
+		}
+		coefs[i] = n
+	}
+
+	roots := []*linearFactor{}
+	for len(coefs) > 2 || (len(coefs) == 2 && len(roots) > 0) {
+		root, ok := rationalRoot(coefs)
+		if !ok {
+			break
+		}
+		coefs = divideLinear(coefs, root.p, root.q)
+		if n := len(roots); n > 0 && roots[n-1].p.Cmp(root.p) == 0 && roots[n-1].q.Cmp(root.q) == 0 {
+			roots[n-1].multiplicity++
+		} else {
+			roots = append(roots, root)
+		}
+	}
+
+	sort.SliceStable(roots, func(i, j int) bool {
+		ri := new(big.Rat).SetFrac(roots[i].p, roots[i].q)
+		rj := new(big.Rat).SetFrac(roots[j].p, roots[j].q)
+		return ri.Cmp(rj) > 0
+	})
+
+	factors := []ast.Expression{}
+	for _, r := range roots {
+		f := r.expression(x)
+		if r.multiplicity > 1 {
+			f = pow(f, num(float64(r.multiplicity)))
+		}
+		factors = append(factors, f)
+	}
+
+	rest := NewPolynomial()
+	for i, c := range coefs {
+		f, _ := new(big.Float).SetInt(c).Float64()
+		rest.addTerm(f, powersOf(x, i))
+	}
+	if c, ok := rest.IsConstant(); !ok || c != 1 || len(factors) == 0 {
+		if ok && c == -1 && len(factors) > 0 {
+			factors[0] = neg(factors[0])
+		} else {
+			factors = append(factors, rest.Expression())
+		}
+	}
+	return factors
+}
+
+func (f *linearFactor) expression(x string) ast.Expression {
+	var term ast.Expression = ast.NewIdentifier(x)
+	if f.q.Cmp(big.NewInt(1)) != 0 {
+		q, _ := new(big.Float).SetInt(f.q).Float64()
+		term = mul(num(q), term)
+	}
+
+	p, _ := new(big.Float).SetInt(f.p).Float64()
+	switch {
+	case p > 0:
+		return sub(term, num(p))
+	case p < 0:
+		return add(term, num(-p))
+	}
+	return term
+}
+
+// rationalRoot looks for a root p/q of the polynomial with the given
+// coefficients, lowest degree first, using the rational root theorem
+func rationalRoot(coefs []*big.Int) (*linearFactor, bool) {
+	if coefs[0].Sign() == 0 {
+		return &linearFactor{p: big.NewInt(0), q: big.NewInt(1), multiplicity: 1}, true
+	}
+
+	ps, ok := divisors(coefs[0])
+	if !ok {
+		return nil, false
+	}
+	qs, ok := divisors(coefs[len(coefs)-1])
+	if !ok {
+		return nil, false
+	}
+
+	for _, q := range qs {
+		for _, p := range ps {
+			for _, sign := range []int64{1, -1} {
+				sp := new(big.Int).Mul(p, big.NewInt(sign))
+				if new(big.Int).GCD(nil, nil, p, q).Cmp(big.NewInt(1)) == 0 && isRoot(coefs, sp, q) {
+					return &linearFactor{p: sp, q: q, multiplicity: 1}, true
+				}
+			}
+		}
+	}
+	return nil, false
+}
+
+// isRoot evaluates q^n * P(p/q), which is an integer
+func isRoot(coefs []*big.Int, p, q *big.Int) bool {
+	n := len(coefs) - 1
+	sum := new(big.Int)
+	for i, c := range coefs {
+		t := new(big.Int).Mul(c, new(big.Int).Exp(p, big.NewInt(int64(i)), nil))
+		t.Mul(t, new(big.Int).Exp(q, big.NewInt(int64(n-i)), nil))
+		sum.Add(sum, t)
+	}
+	return sum.Sign() == 0
+}
+
+// divideLinear divides the polynomial by q*x - p, which must be one of its factors
+func divideLinear(coefs []*big.Int, p, q *big.Int) []*big.Int {
+	n := len(coefs) - 1
+	res := make([]*big.Int, n)
+	res[n-1] = new(big.Int).Quo(coefs[n], q)
+	for i := n - 1; i >= 1; i-- {
+		b := new(big.Int).Mul(p, res[i])
+		b.Add(b, coefs[i])
+		res[i-1] = b.Quo(b, q)
+	}
+	return res
+}
+
+func divisors(n *big.Int) ([]*big.Int, bool) {
+	abs := new(big.Int).Abs(n)
+	if !abs.IsInt64() || abs.Int64() > maxFactorDivisor {
+		return nil, false
+	}
+
+	v := abs.Int64()
+	res := []*big.Int{}
+	for d := int64(1); d <= v; d++ {
+		if v%d == 0 {
+			res = append(res, big.NewInt(d))
+		}
+	}
+	return res, true
+}
+
+func toInt(f float64) (*big.Int, bool) {
+	if f != math.Trunc(f) || math.IsInf(f, 0) {
+		return nil, false
+	}
+	n, _ := big.NewFloat(f).Int(nil)
+	return n, true
+}
@@ -0,0 +1,109 @@
+package symbolic
+
+import (
+	"fmt"
+	"gocalc/ast"
+)
+
+// Diff returns the derivative of e with respect to x, simplified
+func Diff(e ast.Expression, x string) (ast.Expression, error) {
+	d, err := diff(e, x)
+	if err != nil {
+		return nil, err
+	}
+	return Simplify(d), nil
+}
+
+func diff(e ast.Expression, x string) (ast.Expression, error) {
+	if !Contains(e, x) {
+		switch e.(type) {
+		case *ast.FloatLiteral, *ast.Identifier, *ast.PrefixExpression, *ast.InfixExpression, *ast.CallExpression:
+			return num(0), nil
+		}
+	}
+
+	switch e := e.(type) {
+	case *ast.Identifier:
+		return num(1), nil
+
+	case *ast.PrefixExpression:
+		if e.Operator != "-" {
+			break
+		}
+		du, err := diff(e.Right, x)
+		if err != nil {
+			return nil, err
+		}
+		return neg(du), nil
+
+	case *ast.InfixExpression:
+		return diffInfix(e, x)
+
+	case *ast.CallExpression:
+		return diffCall(e, x)
+	}
+
+	return nil, fmt.Errorf("cannot differentiate %s", e)
+}
+
+func diffInfix(e *ast.InfixExpression, x string) (ast.Expression, error) {
+	u, v := e.Left, e.Right
+	du, err := diff(u, x)
+	if err != nil {
+		return nil, err
+	}
+	dv, err := diff(v, x)
+	if err != nil {
+		return nil, err
+	}
+
+	switch e.Operator {
+	case "+", "-":
+		return infix(e.Operator, du, dv), nil
+	case "*":
+		return add(mul(du, v), mul(u, dv)), nil
+	case "/":
+		return div(sub(mul(du, v), mul(u, dv)), pow(v, num(2))), nil
+	case "^":
+		switch {
+		case !Contains(v, x):
+			// power rule
+			return mul(mul(v, pow(u, sub(v, num(1)))), du), nil
+		case !Contains(u, x):
+			// exponential rule
+			return mul(mul(e, ln(u)), dv), nil
+		default:
+			// u^v = e^(v ln u)
+			return mul(e, add(mul(dv, ln(u)), div(mul(v, du), u))), nil
+		}
+	}
+
+	return nil, fmt.Errorf("cannot differentiate operator %s in %s", e.Operator, e)
+}
+
+// derivatives of the functions of one argument, in terms of that argument
+var derivatives = map[string]func(u ast.Expression) ast.Expression{
+	"sin":   func(u ast.Expression) ast.Expression { return call("cos", u) },
+	"cos":   func(u ast.Expression) ast.Expression { return neg(call("sin", u)) },
+	"ln":    func(u ast.Expression) ast.Expression { return div(num(1), u) },
+	"log2":  func(u ast.Expression) ast.Expression { return div(num(1), mul(u, ln(num(2)))) },
+	"log10": func(u ast.Expression) ast.Expression { return div(num(1), mul(u, ln(num(10)))) },
+	"sqrt":  func(u ast.Expression) ast.Expression { return div(num(1), mul(num(2), call("sqrt", u))) },
+}
+
+func diffCall(e *ast.CallExpression, x string) (ast.Expression, error) {
+	name := e.Function.TokenLiteral()
+	rule, ok := derivatives[name]
+	if !ok || len(e.Arguments) != 1 {
+		return nil, fmt.Errorf("cannot differentiate function %s", e.Function)
+	}
+
+	u := e.Arguments[0]
+	du, err := diff(u, x)
+	if err != nil {
+		return nil, err
+	}
+
+	// chain rule
+	return mul(rule(u), du), nil
+}
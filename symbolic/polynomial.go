@@ -0,0 +1,273 @@
+package symbolic
+
+import (
+	"gocalc/ast"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// maxExpandPower caps the exponents expanded when building a polynomial
+const maxExpandPower = 32
+
+// Polynomial is a sum of terms, each a coefficient times a product of variables
+type Polynomial struct {
+	terms map[string]*term
+}
+
+type term struct {
+	coef   float64
+	powers map[string]int
+}
+
+func NewPolynomial() *Polynomial { return &Polynomial{terms: map[string]*term{}} }
+
+// Constant returns the polynomial c
+func Constant(c float64) *Polynomial {
+	p := NewPolynomial()
+	p.addTerm(c, map[string]int{})
+	return p
+}
+
+// Variable returns the polynomial x
+func Variable(x string) *Polynomial {
+	p := NewPolynomial()
+	p.addTerm(1, map[string]int{x: 1})
+	return p
+}
+
+func monomialKey(powers map[string]int) string {
+	vars := make([]string, 0, len(powers))
+	for v := range powers {
+		vars = append(vars, v)
+	}
+	sort.Strings(vars)
+
+	parts := make([]string, len(vars))
+	for i, v := range vars {
+		parts[i] = v + "^" + strconv.Itoa(powers[v])
+	}
+	return strings.Join(parts, "*")
+}
+
+func (p *Polynomial) addTerm(coef float64, powers map[string]int) {
+	key := monomialKey(powers)
+	if t, ok := p.terms[key]; ok {
+		t.coef += coef
+		if t.coef == 0 {
+			delete(p.terms, key)
+		}
+		return
+	}
+	if coef != 0 {
+		p.terms[key] = &term{coef: coef, powers: powers}
+	}
+}
+
+func (p *Polynomial) Add(q *Polynomial) *Polynomial {
+	res := NewPolynomial()
+	for _, t := range p.terms {
+		res.addTerm(t.coef, t.powers)
+	}
+	for _, t := range q.terms {
+		res.addTerm(t.coef, t.powers)
+	}
+	return res
+}
+
+func (p *Polynomial) Scale(c float64) *Polynomial {
+	res := NewPolynomial()
+	for _, t := range p.terms {
+		res.addTerm(t.coef*c, t.powers)
+	}
+	return res
+}
+
+func (p *Polynomial) Sub(q *Polynomial) *Polynomial { return p.Add(q.Scale(-1)) }
+
+func (p *Polynomial) Mul(q *Polynomial) *Polynomial {
+	res := NewPolynomial()
+	for _, t1 := range p.terms {
+		for _, t2 := range q.terms {
+			powers := map[string]int{}
+			for v, n := range t1.powers {
+				powers[v] += n
+			}
+			for v, n := range t2.powers {
+				powers[v] += n
+			}
+			res.addTerm(t1.coef*t2.coef, powers)
+		}
+	}
+	return res
+}
+
+func (p *Polynomial) Pow(n int) *Polynomial {
+	res := Constant(1)
+	for i := 0; i < n; i++ {
+		res = res.Mul(p)
+	}
+	return res
+}
+
+// IsConstant reports whether p has no variables, returning its value
+func (p *Polynomial) IsConstant() (float64, bool) {
+	switch len(p.terms) {
+	case 0:
+		return 0, true
+	case 1:
+		if t, ok := p.terms[""]; ok {
+			return t.coef, true
+		}
+	}
+	return 0, false
+}
+
+// Variables returns the sorted names of the variables of p
+func (p *Polynomial) Variables() []string {
+	seen := map[string]bool{}
+	vars := []string{}
+	for _, t := range p.terms {
+		for v := range t.powers {
+			if !seen[v] {
+				seen[v] = true
+				vars = append(vars, v)
+			}
+		}
+	}
+	sort.Strings(vars)
+	return vars
+}
+
+func (t *term) degree() int {
+	d := 0
+	for _, n := range t.powers {
+		d += n
+	}
+	return d
+}
+
+// Coefficients returns the coefficients of a polynomial in the single variable x,
+// lowest degree first
+func (p *Polynomial) Coefficients(x string) []float64 {
+	coefs := []float64{}
+	for _, t := range p.terms {
+		n := t.powers[x]
+		for len(coefs) <= n {
+			coefs = append(coefs, 0)
+		}
+		coefs[n] += t.coef
+	}
+	return coefs
+}
+
+// PolynomialOf converts e into a polynomial. It fails if e uses anything but
+// sums, products, divisions by constants and non negative integer powers
+func PolynomialOf(e ast.Expression) (*Polynomial, bool) {
+	switch e := e.(type) {
+	case *ast.FloatLiteral:
+		return Constant(e.Value), true
+
+	case *ast.Identifier:
+		return Variable(e.Value), true
+
+	case *ast.PrefixExpression:
+		if e.Operator != "-" {
+			return nil, false
+		}
+		r, ok := PolynomialOf(e.Right)
+		if !ok {
+			return nil, false
+		}
+		return r.Scale(-1), true
+
+	case *ast.InfixExpression:
+		l, ok := PolynomialOf(e.Left)
+		if !ok {
+			return nil, false
+		}
+		r, ok := PolynomialOf(e.Right)
+		if !ok {
+			return nil, false
+		}
+
+		switch e.Operator {
+		case "+":
+			return l.Add(r), true
+		case "-":
+			return l.Sub(r), true
+		case "*":
+			return l.Mul(r), true
+		case "/":
+			if c, ok := r.IsConstant(); ok && c != 0 {
+				return l.Scale(1 / c), true
+			}
+		case "^":
+			if c, ok := r.IsConstant(); ok && c >= 0 && c <= maxExpandPower && c == math.Trunc(c) {
+				return l.Pow(int(c)), true
+			}
+		}
+	}
+
+	return nil, false
+}
+
+// Expression converts p back into an expression, highest degree terms first
+func (p *Polynomial) Expression() ast.Expression {
+	terms := make([]*term, 0, len(p.terms))
+	for _, t := range p.terms {
+		terms = append(terms, t)
+	}
+	sort.Slice(terms, func(i, j int) bool {
+		if di, dj := terms[i].degree(), terms[j].degree(); di != dj {
+			return di > dj
+		}
+		return monomialKey(terms[i].powers) < monomialKey(terms[j].powers)
+	})
+
+	if len(terms) == 0 {
+		return num(0)
+	}
+
+	res := terms[0].expression(terms[0].coef)
+	for _, t := range terms[1:] {
+		if t.coef < 0 {
+			res = sub(res, t.expression(-t.coef))
+		} else {
+			res = add(res, t.expression(t.coef))
+		}
+	}
+	return res
+}
+
+func (t *term) expression(coef float64) ast.Expression {
+	vars := make([]string, 0, len(t.powers))
+	for v := range t.powers {
+		vars = append(vars, v)
+	}
+	sort.Strings(vars)
+
+	var monomial ast.Expression
+	for _, v := range vars {
+		var factor ast.Expression = ast.NewIdentifier(v)
+		if n := t.powers[v]; n != 1 {
+			factor = pow(factor, num(float64(n)))
+		}
+		if monomial == nil {
+			monomial = factor
+		} else {
+			monomial = mul(monomial, factor)
+		}
+	}
+
+	switch {
+	case monomial == nil:
+		return num(coef)
+	case coef == 1:
+		return monomial
+	case coef == -1:
+		return neg(monomial)
+	}
+	return mul(num(coef), monomial)
+}
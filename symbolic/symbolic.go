@@ -0,0 +1,164 @@
+// Package symbolic implements transformations of expressions kept as ASTs
+package symbolic
+
+import (
+	"gocalc/ast"
+	"gocalc/optimizer"
+	"math"
+)
+
+// Contains reports whether the identifier x appears in e
+func Contains(e ast.Expression, x string) bool {
+	found := false
+	ast.Inspect(e, func(n ast.Node) bool {
+		if id, ok := n.(*ast.Identifier); ok && id.Value == x {
+			found = true
+		}
+		return !found
+	})
+	return found
+}
+
+// Simplify folds constants and removes neutral and absorbing elements until e does not change
+func Simplify(e ast.Expression) ast.Expression {
+	o := optimizer.New(nil)
+	o.AssumeNumeric()
+	for {
+		next := o.Expression(simplify(e))
+		if next.String() == e.String() {
+			return next
+		}
+		e = next
+	}
+}
+
+func simplify(e ast.Expression) ast.Expression {
+	switch e := e.(type) {
+	case *ast.PrefixExpression:
+		r := simplify(e.Right)
+		if isNum(r, 0) && e.Operator == "-" {
+			return num(0)
+		}
+		return ast.NewPrefixExpression(e.Operator, r)
+
+	case *ast.InfixExpression:
+		l, r := simplify(e.Left), simplify(e.Right)
+		switch e.Operator {
+		case "*":
+			if isNum(l, 0) || isNum(r, 0) {
+				return num(0)
+			}
+			if isNum(l, -1) {
+				return neg(r)
+			}
+			if isNum(r, -1) {
+				return neg(l)
+			}
+			// pull negations out so they can cancel: a * (-b) -> -(a * b)
+			if p, ok := r.(*ast.PrefixExpression); ok && p.Operator == "-" {
+				return neg(mul(l, p.Right))
+			}
+			if p, ok := l.(*ast.PrefixExpression); ok && p.Operator == "-" {
+				return neg(mul(p.Right, r))
+			}
+			// move constants to the left so they can be folded: x * 2 -> 2 * x
+			if isConstant(r) && !isConstant(l) {
+				l, r = r, l
+			}
+			// c1 * (c2 * x) -> (c1 * c2) * x
+			if inner, ok := r.(*ast.InfixExpression); ok && inner.Operator == "*" && isConstant(l) && isConstant(inner.Left) {
+				return mul(mul(l, inner.Left), inner.Right)
+			}
+		case "/":
+			if isNum(l, 0) {
+				return num(0)
+			}
+			if l.String() == r.String() {
+				return num(1)
+			}
+		case "^":
+			if isNum(r, 0) {
+				return num(1)
+			}
+		case "+":
+			if p, ok := r.(*ast.PrefixExpression); ok && p.Operator == "-" {
+				return sub(l, p.Right)
+			}
+		case "-":
+			if p, ok := r.(*ast.PrefixExpression); ok && p.Operator == "-" {
+				return add(l, p.Right)
+			}
+			if l.String() == r.String() {
+				return num(0)
+			}
+		}
+		return infix(e.Operator, l, r)
+
+	case *ast.CallExpression:
+		args := make([]ast.Expression, len(e.Arguments))
+		for i, a := range e.Arguments {
+			args[i] = simplify(a)
+		}
+		if v, ok := fold(e.Function.TokenLiteral(), args); ok {
+			return num(v)
+		}
+		return &ast.CallExpression{Token: e.Token, Function: e.Function, Arguments: args}
+	}
+
+	return e
+}
+
+// functions of numbers folded by simplify
+var functions = map[string]func(float64) float64{
+	"sin":   math.Sin,
+	"cos":   math.Cos,
+	"ln":    math.Log,
+	"log2":  math.Log2,
+	"log10": math.Log10,
+	"sqrt":  math.Sqrt,
+	"abs":   math.Abs,
+}
+
+// fold evaluates a known function of a number when the result is exact, so
+// ln(2.718281828459045) is 1 while ln(2) stays as it is
+func fold(name string, args []ast.Expression) (float64, bool) {
+	fn, ok := functions[name]
+	if !ok || len(args) != 1 || !isConstant(args[0]) {
+		return 0, false
+	}
+	v := fn(args[0].(*ast.FloatLiteral).Value)
+	return v, v == math.Trunc(v) && !math.IsInf(v, 0)
+}
+
+func isNum(e ast.Expression, value float64) bool {
+	f, ok := e.(*ast.FloatLiteral)
+	return ok && f.Value == value
+}
+
+func isConstant(e ast.Expression) bool {
+	_, ok := e.(*ast.FloatLiteral)
+	return ok
+}
+
+func num(v float64) ast.Expression { return ast.NewFloatLiteral(v) }
+
+func neg(e ast.Expression) ast.Expression { return ast.NewPrefixExpression("-", e) }
+
+func infix(op string, l, r ast.Expression) ast.Expression { return ast.NewInfixExpression(op, l, r) }
+
+func add(l, r ast.Expression) ast.Expression { return infix("+", l, r) }
+
+func sub(l, r ast.Expression) ast.Expression { return infix("-", l, r) }
+
+func mul(l, r ast.Expression) ast.Expression { return infix("*", l, r) }
+
+func div(l, r ast.Expression) ast.Expression { return infix("/", l, r) }
+
+func pow(l, r ast.Expression) ast.Expression { return infix("^", l, r) }
+
+func call(fn string, args ...ast.Expression) ast.Expression {
+	return ast.NewCallExpression(fn, args...)
+}
+
+// ln keeps ln(e) as is, e is only Euler's number when the caller has not rebound it
+func ln(e ast.Expression) ast.Expression { return call("ln", e) }
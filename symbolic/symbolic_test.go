@@ -0,0 +1,120 @@
+package symbolic
+
+import (
+	"gocalc/ast"
+	"gocalc/lexer"
+	"gocalc/parser"
+	"gocalc/testing_utils"
+	"testing"
+)
+
+func parseExpression(t *testing.T, input string) ast.Expression {
+	p := parser.New(lexer.New(input))
+	program := p.ParseProgram()
+	testingutils.Assert(t, !p.HasErrors(), "parser errors: %v", p.Errors())
+	return program.Statements[0].(*ast.ExpressionStatement).Expression
+}
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"5", "0"},
+		{"x", "1"},
+		{"y", "0"},
+		{"x + x", "2"},
+		{"3 * x", "3"},
+		{"x * 3 + pi", "3"},
+		{"x ^ 2", "(2 * x)"},
+		{"x ^ 3 - 2 * x", "((3 * (x ^ 2)) - 2)"},
+		{"-x ^ 2", "(2 * x)"},
+		{"1 / x", "(-1 / (x ^ 2))"},
+		{"sin(x)", "cos(x)"},
+		{"cos(2 * x)", "(-(2 * sin((2 * x))))"},
+		{"ln(x)", "(1 / x)"},
+		{"sqrt(x)", "(1 / (2 * sqrt(x)))"},
+		{"e ^ x", "((e ^ x) * ln(e))"},
+		{"2 ^ x", "((2 ^ x) * ln(2))"},
+		{"2.718281828459045 ^ x", "(2.718281828459045 ^ x)"},
+		{"x * sin(x)", "(sin(x) + (x * cos(x)))"},
+		{"sin(y) * x", "sin(y)"},
+		{"x ^ x", "((x ^ x) * (ln(x) + 1))"},
+	}
+
+	for _, tt := range tests {
+		d, err := Diff(parseExpression(t, tt.input), "x")
+		testingutils.Assert(t, err == nil, "%s: unexpected error %v", tt.input, err)
+		testingutils.Equals(t, tt.expected, d.String(), tt.input)
+	}
+}
+
+func TestDiffErrors(t *testing.T) {
+	inputs := []string{"tan(x)", "true", "x > 2", "len([x])"}
+
+	for _, input := range inputs {
+		_, err := Diff(parseExpression(t, input), "x")
+		testingutils.Assert(t, err != nil, "%s: expected an error", input)
+	}
+}
+
+func TestExpand(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"(x + 1) ^ 2", "(((x ^ 2) + (2 * x)) + 1)"},
+		{"(x + y) * (x - y)", "((x ^ 2) - (y ^ 2))"},
+		{"2 * (x - 3) + 6", "(2 * x)"},
+		{"x * (sin(x) + 1)", "((sin(x) * x) + x)"},
+		{"(x ^ 2 - 1) / (x - 1)", "(x + 1)"},
+		{"x / 2", "(0.5 * x)"},
+		{"x - x", "0"},
+	}
+
+	for _, tt := range tests {
+		testingutils.Equals(t, tt.expected, Expand(parseExpression(t, tt.input)).String(), tt.input)
+	}
+}
+
+func TestFactor(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"x ^ 2 - 1", "((x - 1) * (x + 1))"},
+		{"x ^ 2 + 2 * x + 1", "((x + 1) ^ 2)"},
+		{"2 * x ^ 2 - 2", "((2 * (x - 1)) * (x + 1))"},
+		{"x ^ 3 - x", "((x * (x - 1)) * (x + 1))"},
+		{"6 * x ^ 2 - 5 * x + 1", "(((2 * x) - 1) * ((3 * x) - 1))"},
+		{"x ^ 2 + 1", "((x ^ 2) + 1)"},
+		{"x * y + x", "(x * (y + 1))"},
+		{"sin(x) ^ 2 - 1", "((sin(x) - 1) * (sin(x) + 1))"},
+		{"4 - x ^ 2", "(-((x - 2) * (x + 2)))"},
+		{"3", "3"},
+	}
+
+	for _, tt := range tests {
+		testingutils.Equals(t, tt.expected, Factor(parseExpression(t, tt.input)).String(), tt.input)
+	}
+}
+
+func TestSimplifyAlgebraic(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"x + x + x", "(3 * x)"},
+		{"(x + 1) ^ 2", "((x + 1) ^ 2)"},
+		{"(x ^ 2 - 1) / (x + 1)", "(x - 1)"},
+		{"x * 0 + y * 1", "y"},
+		{"2 * x - x + sin(x) - sin(x)", "x"},
+		{"sqrt(4) * x", "(2 * x)"},
+		{"ln(2.718281828459045) * x", "x"},
+		{"cos(0) + ln(2)", "(1 + ln(2))"},
+	}
+
+	for _, tt := range tests {
+		testingutils.Equals(t, tt.expected, SimplifyAlgebraic(parseExpression(t, tt.input)).String(), tt.input)
+	}
+}
@@ -1,10 +1,49 @@
 package token
 
+import "strings"
+
 type TokenType byte
 
 type Token struct {
 	Type    TokenType
 	Literal string
+
+	// Line and Column locate the first character of the token, starting at 1.
+	// Columns count characters rather than bytes
+	Line, Column int
+
+	// Comments are the comments between the previous token and this one, kept
+	// as trivia so tools can preserve them. Comments at the end are on EOF
+	Comments []Comment
+}
+
+// Comment is a # or // line comment or a /* */ block comment, as written
+type Comment struct {
+	Literal      string
+	Line, Column int
+
+	// Trailing comments follow another token on their line
+	Trailing bool
+}
+
+// EndLine returns the line of the last character of the comment
+func (c Comment) EndLine() int { return c.Line + strings.Count(c.Literal, "\n") }
+
+// Text returns the content of the comment without its markers
+func (c Comment) Text() string {
+	switch {
+	case strings.HasPrefix(c.Literal, "#"):
+		return strings.TrimSpace(c.Literal[1:])
+	case strings.HasPrefix(c.Literal, "//"):
+		return strings.TrimSpace(c.Literal[2:])
+	}
+	// a /** comment may start each line with a *, which is not part of the text
+	body := strings.TrimPrefix(strings.TrimSuffix(strings.TrimPrefix(c.Literal, "/*"), "*/"), "*")
+	lines := strings.Split(body, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(line), "*"))
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
 }
 
 const (
@@ -12,21 +51,25 @@ const (
 	EOF
 
 	literal_beg
-	IDENT  // x, x2, y
-	FLOAT  // 10.14
-	IMAG   // 10.14i
-	CHAR   // 'a'
-	STRING // "abc"
+	IDENT    // x, x2, y
+	FLOAT    // 10.14
+	IMAG     // 10.14i
+	CHAR     // 'a'
+	STRING   // "abc"
+	DURATION // 3d 4h
 	literal_end
 
 	operator_beg
 	SEMICOLON
+	COLON
 	COMMA
 	PERIOD
 	LPAREN
 	RPAREN
 	LBRACK
 	RBRACK
+	LBRACE
+	RBRACE
 	ASSIGN
 	PLUS
 	MINUS
@@ -40,8 +83,19 @@ const (
 	GT
 	LT_EQ
 	GT_EQ
-	AND // &&
-	OR  // ||
+	AND   // &&
+	OR    // ||
+	QUOTE // '
+
+	PLUS_ASSIGN     // +=
+	MINUS_ASSIGN    // -=
+	ASTERISK_ASSIGN // *=
+	SLASH_ASSIGN    // /=
+	CARET_ASSIGN    // ^=
+
+	BAR     // |
+	PERCENT // %
+	SQRT    // √
 
 	operator_end
 
@@ -50,6 +104,7 @@ const (
 	FALSE
 	IMPORT
 	TYPE
+	CONST
 	keyword_end
 )
 
@@ -58,20 +113,24 @@ var tokenNames = []string{
 	EOF:     "EOF",
 
 	// Literals
-	IDENT:  "IDENT",
-	FLOAT:  "FLOAT",
-	IMAG:   "IMAG",
-	CHAR:   "CHAR",
-	STRING: "STRING",
+	IDENT:    "IDENT",
+	FLOAT:    "FLOAT",
+	IMAG:     "IMAG",
+	CHAR:     "CHAR",
+	STRING:   "STRING",
+	DURATION: "DURATION",
 
 	// Delimiters
 	SEMICOLON: ";",
+	COLON:     ":",
 	COMMA:     ",",
 	PERIOD:    ".",
 	LPAREN:    "(",
 	RPAREN:    ")",
 	LBRACK:    "[",
 	RBRACK:    "]",
+	LBRACE:    "{",
+	RBRACE:    "}",
 
 	// Operators
 	ASSIGN:   "=",
@@ -89,17 +148,32 @@ var tokenNames = []string{
 	GT_EQ:    ">=",
 	AND:      "&&",
 	OR:       "||",
+	QUOTE:    "'",
+
+	PLUS_ASSIGN:     "+=",
+	MINUS_ASSIGN:    "-=",
+	ASTERISK_ASSIGN: "*=",
+	SLASH_ASSIGN:    "/=",
+	CARET_ASSIGN:    "^=",
+
+	BAR:     "|",
+	PERCENT: "%",
+	SQRT:    "√",
 
 	// Keywords
 	IMPORT: "import",
 	TYPE:   "type",
+	CONST:  "const",
 	TRUE:   "true",
 	FALSE:  "false",
 }
 
 var keywords = map[string]TokenType{
-	"true":  TRUE,
-	"false": FALSE,
+	"true":   TRUE,
+	"false":  FALSE,
+	"import": IMPORT,
+	"type":   TYPE,
+	"const":  CONST,
 }
 
 func TryGetKeyword(kw string) (res TokenType, b bool) {
@@ -109,8 +183,18 @@ func TryGetKeyword(kw string) (res TokenType, b bool) {
 
 func (tt TokenType) String() string { return tokenNames[tt] }
 
-func New(tokenType TokenType, ch byte) Token { return Token{Type: tokenType, Literal: string(ch)} }
+func New(tokenType TokenType, ch rune) Token { return Token{Type: tokenType, Literal: string(ch)} }
 
 func NewExt(tokenType TokenType, lit string) Token { return Token{Type: tokenType, Literal: lit} }
 
 func (t *Token) IsIllegal() bool { return t.Type == ILLEGAL }
+
+// LookupOperator returns the token type of an operator or delimiter
+func LookupOperator(op string) (TokenType, bool) {
+	for tt := operator_beg + 1; tt < operator_end; tt++ {
+		if tokenNames[tt] == op {
+			return tt, true
+		}
+	}
+	return ILLEGAL, false
+}
@@ -0,0 +1,439 @@
+package types
+
+import (
+	"fmt"
+	"gocalc/ast"
+	"gocalc/object"
+	"strings"
+)
+
+// Checker infers the types of programs and reports the operations that would
+// fail at runtime
+type Checker struct {
+	scope    map[string]Type
+	declared map[string]Type // variables with a type annotation
+	consts   map[string]bool
+	errors   []string
+
+	// Import returns the type of the module imported from path
+	Import func(path string) (Type, error)
+	// Symbolic types unknown identifiers as expressions instead of reporting them
+	Symbolic bool
+}
+
+// NewChecker returns a checker where the names of scope are bound to their types
+func NewChecker(scope map[string]Type) *Checker {
+	c := &Checker{scope: make(map[string]Type, len(scope)), declared: map[string]Type{}, consts: map[string]bool{}}
+	for name, t := range scope {
+		c.scope[name] = t
+	}
+	return c
+}
+
+// Constant marks names as constants, which cannot be assigned
+func (c *Checker) Constant(names ...string) {
+	for _, name := range names {
+		c.consts[name] = true
+	}
+}
+
+// Declare gives name a declared type, which the values assigned to it must have
+func (c *Checker) Declare(name string, t Type) {
+	c.declared[name] = t
+}
+
+// Check infers the types of program, adding its declarations to the scope of c.
+// It returns the type of the last statement, for declarations the type of the
+// declared name, and the errors found
+func (c *Checker) Check(program *ast.Program) (Type, []string) {
+	c.errors = nil
+	var last Type = Null
+	for _, s := range program.Statements {
+		last = c.Statement(s)
+	}
+	return last, c.errors
+}
+
+func (c *Checker) errorf(format string, v ...interface{}) Type {
+	c.errors = append(c.errors, fmt.Sprintf(format, v...))
+	return Any
+}
+
+func (c *Checker) Statement(s ast.Statement) Type {
+	switch s := s.(type) {
+	case *ast.ExpressionStatement:
+		return c.Expression(s.Expression)
+	case *ast.AssignmentStatement:
+		v := c.Expression(s.Value)
+		names := s.Names()
+		for i := len(names) - 1; i >= 0; i-- {
+			v = c.assign(names[i].Value, v, s.Annotation, s.Const)
+		}
+		return v
+	case *ast.CompoundAssignmentStatement:
+		return c.assign(s.Name.Value, c.Expression(s.Infix()), nil, false)
+	case *ast.DestructuringStatement:
+		return c.destructuring(s)
+	case *ast.ImportStatement:
+		var t Type = &Module{Name: s.Name()}
+		if c.Import != nil {
+			m, err := c.Import(s.Path)
+			if err != nil {
+				return c.errorf("%s", err)
+			}
+			t = m
+		}
+		c.scope[s.Name()] = t
+		return t
+	case *ast.TypeStatement:
+		t := &TypeOf{Of: &Record{Name: s.Name.Value, Fields: s.FieldNames()}}
+		c.scope[s.Name.Value] = t
+		return t
+	}
+	return Any
+}
+
+// assign binds the variable to the type of its value v, or to its declared
+// type, which later assignments must also respect
+func (c *Checker) assign(name string, v Type, annotation *ast.Identifier, constant bool) Type {
+	if c.consts[name] {
+		return c.errorf(object.CONST_ASSIGNMENT_ERROR, name)
+	}
+	if constant {
+		c.consts[name] = true
+	}
+
+	t, declared := c.declared[name]
+	if annotation != nil {
+		t, declared = c.annotation(annotation.Value), true
+	}
+	if !declared {
+		c.scope[name] = v
+		return v
+	}
+
+	if !Assignable(v, t) {
+		c.errorf(object.ANNOTATION_ERROR, v, name, t)
+	}
+	c.declared[name] = t
+	c.scope[name] = t
+	return t
+}
+
+// destructuring assigns the values in order, or the elements of a single value
+func (c *Checker) destructuring(ds *ast.DestructuringStatement) Type {
+	values := make([]Type, len(ds.Values))
+	for i, v := range ds.Values {
+		values[i] = c.Expression(v)
+	}
+	if ds.Unpacks() {
+		var elem Type
+		switch k := Kind(values[0]); k {
+		case object.LIST:
+			elem = values[0].(*List).Elem
+		case object.VECTOR:
+			elem = Float
+		case object.ANY, object.MATRIX:
+			elem = Any
+		default:
+			return c.errorf(object.UNPACK_TYPE_ERROR, k)
+		}
+		values = make([]Type, len(ds.Names))
+		for i := range values {
+			values[i] = elem
+		}
+	}
+
+	res := make([]Type, len(ds.Names))
+	for i := len(ds.Names) - 1; i >= 0; i-- {
+		res[i] = c.assign(ds.Names[i].Value, values[i], nil, false)
+	}
+	return &List{Elem: joinAll(res)}
+}
+
+// joinAll is the type of values that are of any of the types ts
+func joinAll(ts []Type) Type {
+	var res Type = Any
+	for i, t := range ts {
+		if i == 0 {
+			res = t
+		} else {
+			res = Join(res, t)
+		}
+	}
+	return res
+}
+
+// annotation returns the type named in an annotation, a builtin type or a
+// record type in scope
+func (c *Checker) annotation(name string) Type {
+	if t, ok := c.scope[name].(*TypeOf); ok {
+		return t.Of
+	}
+	if kind, ok := object.ParseType(name); ok {
+		return FromKind(kind)
+	}
+	return c.errorf(object.UNKNOWN_TYPE_ERROR, name)
+}
+
+// Expression returns the type of e
+func (c *Checker) Expression(e ast.Expression) Type {
+	switch e := e.(type) {
+	case *ast.FloatLiteral:
+		return Float
+	case *ast.BooleanLiteral:
+		return Bool
+	case *ast.StringLiteral:
+		return Str
+	case *ast.DurationLiteral:
+		return Duration
+	case *ast.QuoteExpression:
+		return Expr
+	case *ast.Identifier:
+		return c.identifier(e.Value)
+	case *ast.ListLiteral:
+		return c.list(e)
+	case *ast.PrefixExpression:
+		return c.prefix(e.Operator, c.Expression(e.Right))
+	case *ast.InfixExpression:
+		// the evaluator evaluates the right operand first
+		r := c.Expression(e.Right)
+		return c.infix(e.Operator, c.Expression(e.Left), r)
+	case *ast.CallExpression:
+		return c.call(e)
+	case *ast.MemberExpression:
+		return c.member(c.Expression(e.Object), e.Member.Value)
+	}
+	return Any
+}
+
+func (c *Checker) identifier(name string) Type {
+	if t, ok := c.scope[name]; ok {
+		return t
+	}
+	if c.Symbolic {
+		return Expr
+	}
+	return c.errorf(object.IDENTIFIER_NOT_FOUND_ERROR, name)
+}
+
+func (c *Checker) list(ll *ast.ListLiteral) Type {
+	values := make([]Type, len(ll.Values))
+	for i, v := range ll.Values {
+		values[i] = c.Expression(v)
+	}
+	elem := joinAll(values)
+	// rows of numbers make a matrix
+	if l, ok := elem.(*List); ok && Assignable(l.Elem, Float) {
+		return Any
+	}
+	return &List{Elem: elem}
+}
+
+func isComparison(operator string) bool {
+	switch operator {
+	case "==", "!=", "<", "<=", ">", ">=":
+		return true
+	}
+	return false
+}
+
+func isLinalg(kind object.ObjectType) bool {
+	return kind == object.VECTOR || kind == object.MATRIX
+}
+
+func isTime(kind object.ObjectType) bool {
+	return kind == object.TIME || kind == object.DURATION
+}
+
+// elem is the type of the elements of a list, or of a scalar repeated to the length of one
+func elem(t Type) Type {
+	if l, ok := t.(*List); ok {
+		return l.Elem
+	}
+	return t
+}
+
+func (c *Checker) infix(operator string, left, right Type) Type {
+	l, r := Kind(left), Kind(right)
+
+	switch {
+	case l == object.ANY || r == object.ANY:
+		return Any
+	case l == object.EXPRESSION || r == object.EXPRESSION:
+		return Expr
+	case isLinalg(l) || isLinalg(r):
+		return Any
+	case l == object.LIST || r == object.LIST:
+		return &List{Elem: c.infix(operator, elem(left), elem(right))}
+	case l == object.RECORD || r == object.RECORD:
+		if l == r && (operator == "==" || operator == "!=") {
+			return Bool
+		}
+	case isTime(l) || isTime(r):
+		if t := timeResult(operator, l, r); t != nil {
+			return t
+		}
+	case l == object.TYPE && r == object.TYPE:
+		if operator == "==" || operator == "!=" {
+			return Bool
+		}
+	case l == object.STRING && r == object.STRING:
+		if operator == "+" {
+			return Str
+		}
+		if isComparison(operator) {
+			return Bool
+		}
+	case l == object.FLOAT && r == object.FLOAT:
+		if isComparison(operator) {
+			return Bool
+		}
+		switch operator {
+		case "+", "-", "*", "/", "^":
+			return Float
+		}
+	case l == object.BOOLEAN && r == object.BOOLEAN:
+		switch operator {
+		case "==", "!=", "&&", "||":
+			return Bool
+		}
+	}
+
+	return c.errorf(object.UNKNOWN_INFIX_OPERATOR_ERROR, l, operator, r)
+}
+
+// timeResult is the type of the arithmetic between times, durations and numbers
+func timeResult(operator string, l, r object.ObjectType) Type {
+	switch {
+	case l == object.TIME && r == object.DURATION && (operator == "+" || operator == "-"):
+		return Time
+	case l == object.DURATION && r == object.TIME && operator == "+":
+		return Time
+	case l == object.TIME && r == object.TIME && operator == "-":
+		return Duration
+	case l == object.DURATION && r == object.DURATION && (operator == "+" || operator == "-"):
+		return Duration
+	case l == object.DURATION && r == object.DURATION && operator == "/":
+		return Float
+	case l == r && isComparison(operator):
+		return Bool
+	case l == object.DURATION && r == object.FLOAT && (operator == "*" || operator == "/"):
+		return Duration
+	case l == object.FLOAT && r == object.DURATION && operator == "*":
+		return Duration
+	}
+	return nil
+}
+
+func (c *Checker) prefix(operator string, right Type) Type {
+	k := Kind(right)
+
+	switch {
+	case k == object.ANY:
+		return Any
+	case k == object.EXPRESSION:
+		return Expr
+	case isLinalg(k):
+		return Any
+	case k == object.LIST:
+		return &List{Elem: c.prefix(operator, elem(right))}
+	case k == object.DURATION && operator == "-":
+		return Duration
+	case k == object.FLOAT && operator == "-":
+		return Float
+	case k == object.BOOLEAN && operator == "!":
+		return Bool
+	}
+
+	return c.errorf(object.UNKNOWN_PREFIX_OPERATOR_ERROR, operator, k)
+}
+
+func (c *Checker) call(ce *ast.CallExpression) Type {
+	fn := c.Expression(ce.Function)
+	if f, ok := fn.(*Function); ok && f.Quoted {
+		return f.result()
+	}
+
+	args := make([]Type, len(ce.Arguments))
+	for i, a := range ce.Arguments {
+		args[i] = c.Expression(a)
+	}
+
+	switch f := fn.(type) {
+	case *Function:
+		return c.apply(f, args)
+	case *TypeOf:
+		if rec, ok := f.Of.(*Record); ok {
+			if len(args) != len(rec.Fields) {
+				return c.errorf(object.WRONG_ARGUMENTS_ERROR, rec.Name, "("+strings.Join(rec.Fields, ", ")+")", len(args))
+			}
+			return rec
+		}
+	}
+
+	switch Kind(fn) {
+	case object.ANY, object.TYPE, object.NATIVE_FUNCTION:
+		return Any
+	case object.EXPRESSION:
+		return Expr
+	}
+	return c.errorf(object.NOT_CALLABLE_ERROR, fn)
+}
+
+// apply checks the arguments of a call to f the same way natives check their signature
+func (c *Checker) apply(f *Function, args []Type) Type {
+	if f.Elementwise && len(args) == 1 {
+		switch Kind(args[0]) {
+		case object.LIST:
+			return &List{Elem: c.apply(f, []Type{elem(args[0])})}
+		case object.EXPRESSION:
+			return Expr
+		}
+	}
+	if f.Params == nil {
+		return f.result()
+	}
+
+	n, min := len(f.Params), len(f.Params)-f.Optional
+	if f.Variadic && f.Optional == 0 {
+		min = n - 1
+	}
+	if len(args) < min || (!f.Variadic && len(args) > n) {
+		return c.errorf(object.WRONG_ARGUMENTS_ERROR, f.Name, f.params(), len(args))
+	}
+
+	for i, arg := range args {
+		if p := f.param(i); !Assignable(arg, p) {
+			c.errorf(object.ARGUMENT_TYPE_ERROR, i+1, f.Name, p, Kind(arg))
+		}
+	}
+	return f.result()
+}
+
+func (c *Checker) member(t Type, name string) Type {
+	switch t := t.(type) {
+	case *Module:
+		if t.Members == nil {
+			return Any
+		}
+		if m, ok := t.Members[name]; ok {
+			return m
+		}
+		return c.errorf(object.MEMBER_NOT_FOUND_ERROR, "module "+t.Name, name)
+	case *Record:
+		for _, f := range t.Fields {
+			if f == name {
+				return Any
+			}
+		}
+		return c.errorf(object.MEMBER_NOT_FOUND_ERROR, t.Name, name)
+	}
+
+	switch k := Kind(t); k {
+	case object.ANY, object.MODULE, object.RECORD:
+		return Any
+	default:
+		return c.errorf(object.NO_MEMBERS_ERROR, k)
+	}
+}
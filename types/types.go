@@ -0,0 +1,206 @@
+// Package types infers the static types of programs.
+//
+// The checker follows the rules of the evaluator, so the operations it reports,
+// like true + 1 or calling a number, are the ones that would fail at runtime.
+// Types that cannot be known before evaluation are Any, which is compatible
+// with every other type
+package types
+
+import (
+	"gocalc/object"
+	"strings"
+)
+
+// Type is the static type of an expression
+type Type interface {
+	String() string
+}
+
+// Basic is the type of the values of a builtin object type
+type Basic struct {
+	Kind object.ObjectType
+}
+
+func (b *Basic) String() string { return b.Kind.String() }
+
+var (
+	Any      = &Basic{Kind: object.ANY}
+	Float    = &Basic{Kind: object.FLOAT}
+	Bool     = &Basic{Kind: object.BOOLEAN}
+	Str      = &Basic{Kind: object.STRING}
+	Null     = &Basic{Kind: object.NULL}
+	Time     = &Basic{Kind: object.TIME}
+	Duration = &Basic{Kind: object.DURATION}
+	Expr     = &Basic{Kind: object.EXPRESSION}
+	Dist     = &Basic{Kind: object.DISTRIBUTION}
+)
+
+// FromKind returns the type of the values of kind
+func FromKind(kind object.ObjectType) Type {
+	if kind == object.LIST {
+		return &List{Elem: Any}
+	}
+	for _, b := range []*Basic{Any, Float, Bool, Str, Null, Time, Duration, Expr, Dist} {
+		if b.Kind == kind {
+			return b
+		}
+	}
+	return &Basic{Kind: kind}
+}
+
+// List is the type of lists whose elements are of type Elem
+type List struct {
+	Elem Type
+}
+
+func (l *List) String() string {
+	if l.Elem == Any {
+		return object.LIST.String()
+	}
+	return object.LIST.String() + "[" + l.Elem.String() + "]"
+}
+
+// Function is the type of natives. Nil Params means the parameters are unknown
+// and any arguments are accepted, nil Result that the result is unknown
+type Function struct {
+	Name     string
+	Params   []Type
+	Variadic bool
+	Optional int
+	Result   Type
+
+	// Elementwise functions map over a list argument, Quoted ones receive their
+	// arguments unevaluated so they are not checked
+	Elementwise bool
+	Quoted      bool
+}
+
+func (f *Function) String() string { return f.params() + " -> " + f.result().String() }
+
+// params formats the parameters the same way as the signatures of natives
+func (f *Function) params() string {
+	if f.Params == nil {
+		return "(...)"
+	}
+	names := make([]string, len(f.Params))
+	for i, p := range f.Params {
+		names[i] = p.String()
+	}
+	if f.Variadic && len(names) > 0 {
+		names[len(names)-1] += "..."
+	}
+	for i := len(names) - f.Optional; i < len(names); i++ {
+		names[i] = "[" + names[i] + "]"
+	}
+	return "(" + strings.Join(names, ", ") + ")"
+}
+
+func (f *Function) result() Type {
+	if f.Result == nil {
+		return Any
+	}
+	return f.Result
+}
+
+func (f *Function) param(i int) Type {
+	if i >= len(f.Params) {
+		return f.Params[len(f.Params)-1]
+	}
+	return f.Params[i]
+}
+
+// Record is the type of the values of a record type
+type Record struct {
+	Name   string
+	Fields []string
+}
+
+func (r *Record) String() string { return r.Name }
+
+// TypeOf is the type of a value that is itself a type, such as a record type
+// used as a constructor
+type TypeOf struct {
+	Of Type
+}
+
+func (t *TypeOf) String() string { return object.TYPE.String() }
+
+// Module is the type of an imported module. Nil Members means its members are unknown
+type Module struct {
+	Name    string
+	Members map[string]Type
+}
+
+func (m *Module) String() string { return object.MODULE.String() }
+
+// Assignable reports whether a value of type v can be used where t is expected
+func Assignable(v, t Type) bool {
+	if v == Any || t == Any {
+		return true
+	}
+
+	switch t := t.(type) {
+	case *Basic:
+		// the dynamic types the static ones are made of
+		switch v.(type) {
+		case *List:
+			return t.Kind == object.LIST
+		case *Function:
+			return t.Kind == object.NATIVE_FUNCTION
+		case *Record:
+			return t.Kind == object.RECORD
+		case *TypeOf:
+			return t.Kind == object.TYPE
+		case *Module:
+			return t.Kind == object.MODULE
+		}
+		v, ok := v.(*Basic)
+		return ok && v.Kind == t.Kind
+	case *List:
+		v, ok := v.(*List)
+		return ok && Assignable(v.Elem, t.Elem)
+	case *Function:
+		_, ok := v.(*Function)
+		return ok
+	case *Record:
+		v, ok := v.(*Record)
+		return ok && v.Name == t.Name && strings.Join(v.Fields, ",") == strings.Join(t.Fields, ",")
+	case *TypeOf:
+		_, ok := v.(*TypeOf)
+		return ok
+	case *Module:
+		_, ok := v.(*Module)
+		return ok
+	}
+	return false
+}
+
+// Kind returns the object type of the values of t, ANY when they may be of several
+func Kind(t Type) object.ObjectType {
+	switch t := t.(type) {
+	case *Basic:
+		return t.Kind
+	case *List:
+		return object.LIST
+	case *Function:
+		return object.NATIVE_FUNCTION
+	case *Record:
+		return object.RECORD
+	case *TypeOf:
+		return object.TYPE
+	case *Module:
+		return object.MODULE
+	}
+	return object.ANY
+}
+
+// Join is the type of values that are of type a or b
+func Join(a, b Type) Type {
+	if a == Any || b == Any {
+		return Any
+	}
+	if Assignable(a, b) && Assignable(b, a) {
+		return a
+	}
+	return Any
+}
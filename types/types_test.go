@@ -0,0 +1,50 @@
+package types
+
+import (
+	"gocalc/object"
+	"gocalc/testing_utils"
+	"testing"
+)
+
+func TestAssignable(t *testing.T) {
+	point := &Record{Name: "Point", Fields: []string{"x", "y"}}
+	tests := []struct {
+		v, t     Type
+		expected bool
+	}{
+		{Float, Float, true},
+		{Float, Str, false},
+		{Any, Str, true},
+		{Str, Any, true},
+		{&List{Elem: Float}, &List{Elem: Any}, true},
+		{&List{Elem: Float}, &List{Elem: Str}, false},
+		{&List{Elem: Float}, FromKind(object.LIST), true},
+		{point, &Record{Name: "Point", Fields: []string{"x", "y"}}, true},
+		{point, &Record{Name: "Point", Fields: []string{"x"}}, false},
+		{point, FromKind(object.RECORD), true},
+		{&Function{}, FromKind(object.NATIVE_FUNCTION), true},
+	}
+
+	for _, tt := range tests {
+		testingutils.Equals(t, tt.expected, Assignable(tt.v, tt.t), tt.v.String()+" to "+tt.t.String())
+	}
+}
+
+func TestString(t *testing.T) {
+	tests := []struct {
+		t        Type
+		expected string
+	}{
+		{&List{Elem: Any}, "List"},
+		{&List{Elem: &List{Elem: Str}}, "List[List[Str]]"},
+		{&Function{Params: []Type{Float, Float}, Optional: 1, Result: Float}, "(Float, [Float]) -> Float"},
+		{&Function{Params: []Type{Str}, Variadic: true}, "(Str...) -> Any"},
+		{&TypeOf{Of: Float}, "Type"},
+		{Join(Float, Str), "Any"},
+		{Join(&List{Elem: Float}, &List{Elem: Float}), "List[Float]"},
+	}
+
+	for _, tt := range tests {
+		testingutils.Equals(t, tt.expected, tt.t.String(), tt.expected)
+	}
+}